@@ -5,26 +5,80 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // RenovateJobSpec defines the desired state of RenovateJob
-// +kubebuilder:object:root=true
-// +kubebuilder:subresource:status
 type RenovateJobSpec struct {
 	// Cron schedule in standard cron format
 	Schedule string `json:"schedule"`
+	// IANA time zone the schedule and execution windows are evaluated in, e.g. "Europe/Berlin".
+	// Defaults to the time zone of the operator.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+	// Spread the start of the projects over this duration after the schedule fired, e.g. "2h".
+	// Every project gets a stable offset derived from its name. Projects triggered by webhooks or the UI start right away.
+	// +optional
+	Spread *metav1.Duration `json:"spread,omitempty"`
+	// Maximum time since the last successful run of a project, e.g. "72h". Stale projects are reported and
+	// started before other projects of the RenovateJob, except for projects triggered from the UI.
+	// Projects that never completed a run are stale.
+	// +optional
+	MaxStaleness *metav1.Duration `json:"maxStaleness,omitempty"`
+	// Time windows in which scheduled projects are started. Projects scheduled outside of a window
+	// wait for the next window. Projects are started at any time if not set.
+	// +optional
+	ExecutionWindows []RenovateExecutionWindow `json:"executionWindows,omitempty"`
+	// If true, the schedule is not registered and no scheduled projects are started.
+	// Running projects are finished, their status is kept.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
 	// Renovate Docker image to use
 	Image string `json:"image"`
 	// Renovate Provider Information to fill "RENOVATE_ENDPOINT" and "RENOVATE_PLATFORM" environment variables in the renovate container
 	Provider *RenovateProvider `json:"provider"`
+	// Projects managed by this RenovateJob, e.g. "org/repo". If set, projects are not autodiscovered unless
+	// autodiscover is true, filters and skip options only apply to autodiscovered projects.
+	// +optional
+	Projects []string `json:"projects,omitempty"`
+	// Whether projects are autodiscovered and merged with the static projects.
+	// Defaults to true without static projects and to false with static projects.
+	// +optional
+	Autodiscover *bool `json:"autodiscover,omitempty"`
 	// Filter to select which projects to process
 	DiscoveryFilter string `json:"discoveryFilter,omitempty"`
 	// Topics to discover projects from
 	DiscoverTopics string `json:"discoverTopics,omitempty"`
+	// How projects are discovered. "pod" runs Renovate in a discovery job, "api" lists the repositories
+	// through the platform API without starting a pod and falls back to the discovery job on errors.
+	// Defaults to "pod".
+	// +kubebuilder:validation:Enum=pod;api
+	// +optional
+	DiscoveryMode RenovateDiscoveryMode `json:"discoveryMode,omitempty"`
 	// If true, forked repositories discovered during autodiscovery will be excluded by querying the platform API
 	SkipForks bool `json:"skipForks,omitempty"`
+	// If true, archived repositories are excluded by querying the platform API
+	// +optional
+	SkipArchived bool `json:"skipArchived,omitempty"`
+	// If true, repositories without commits are excluded by querying the platform API
+	// +optional
+	SkipEmpty bool `json:"skipEmpty,omitempty"`
+	// Exclude repositories without a push for longer than the given duration, e.g. "4320h"
+	// +optional
+	SkipInactiveFor *metav1.Duration `json:"skipInactiveFor,omitempty"`
+	// Ordered include and exclude rules applied to the discovered projects. The last matching rule decides.
+	// Projects matching no rule are kept, unless the list contains an include rule.
+	// +optional
+	ProjectFilters []RenovateProjectFilter `json:"projectFilters,omitempty"`
+	// Maximum number of projects a single discovery may remove, either as a count (e.g. 20) or as a
+	// percentage of the existing projects (e.g. "10%"). Discoveries removing more projects are not applied
+	// until the removal is confirmed. Removals are not limited if not set.
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	MaxProjectRemovals *intstr.IntOrString `json:"maxProjectRemovals,omitempty"`
 	// Reference to the secret containing the renovate config
 	SecretRef string `json:"secretRef,omitempty"`
 	// Additional environment variables to set in the renovate container
@@ -33,6 +87,29 @@ type RenovateJobSpec struct {
 	ExtraEnvFrom []corev1.EnvFromSource `json:"extraEnvFrom,omitempty"`
 	// Maximum number of projects to process in parallel
 	Parallelism int32 `json:"parallelism"`
+	// Number of scheduled projects run together in one executor job, which reduces the overhead of starting
+	// a pod for every project. Parallelism and the operator wide limits count executor jobs.
+	// Projects with project overrides other than the priority always run in their own job. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+	// Share of the operator wide executor capacity compared to other RenovateJobs, defaults to 1.
+	// Only relevant if the operator limits the number of concurrent executor jobs.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weight int32 `json:"weight,omitempty"`
+	// Automatic retries of failed projects. Failed projects are not retried if not set.
+	// +optional
+	RetryPolicy *RenovateRetryPolicy `json:"retryPolicy,omitempty"`
+	// Number of consecutive failed runs after which a project is quarantined and no longer scheduled
+	// until it is released. Projects are never quarantined if not set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	QuarantineThreshold int32 `json:"quarantineThreshold,omitempty"`
+	// If true, failed projects are scheduled again once the contents of a referenced secret change,
+	// e.g. after an expired token was rotated. Quarantined projects stay quarantined.
+	// +optional
+	RescheduleFailedOnSecretChange bool `json:"rescheduleFailedOnSecretChange,omitempty"`
 	// Resource requirements for the renovate container
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 	// Node selector for scheduling the resulting pod
@@ -59,12 +136,212 @@ type RenovateJobSpec struct {
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 	// DNS Policy for the renovate pods
 	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+	// Timeout, backoff limit and TTL of the discovery jobs, falling back to the operator configuration
+	// +optional
+	DiscoveryJob *RenovateJobLimits `json:"discoveryJob,omitempty"`
+	// Timeout, backoff limit and TTL of the executor jobs, falling back to the operator configuration
+	// +optional
+	ExecutorJob *RenovateJobLimits `json:"executorJob,omitempty"`
+	// Settings for projects matching a name or glob, e.g. more memory for monorepos.
+	// All matching overrides are applied in order, later overrides take precedence.
+	// +optional
+	ProjectOverrides []RenovateProjectOverride `json:"projectOverrides,omitempty"`
+	// Global renovate configuration, e.g. hostRules or onboardingConfig. It is rendered into a ConfigMap
+	// managed by the operator and passed to renovate as RENOVATE_CONFIG_FILE. Changes apply to the next runs.
+	// Secrets belong into the secret referenced by secretRef instead.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	RenovateConfig *runtime.RawExtension `json:"renovateConfig,omitempty"`
+	// Validation of the renovate config with renovate-config-validator whenever the inline config, the image
+	// or the referenced secrets and config maps change. The config is not validated if not set.
+	// +optional
+	ConfigValidation *RenovateConfigValidation `json:"configValidation,omitempty"`
+	// Pod templates strategically merged over the generated discovery and executor pods,
+	// for pod settings without a field of their own, e.g. a priority class or a sidecar container.
+	// +optional
+	PodTemplate *RenovatePodTemplates `json:"podTemplate,omitempty"`
+	// Persistent cache of the executor jobs, kept in volumes created by the operator.
+	// The executor jobs use a fresh cache on every run if not set.
+	// +optional
+	Cache *RenovateCache `json:"cache,omitempty"`
 	// Groups allowed to view this RenovateJob when authentication is enabled.
 	// If empty or not set, the job is hidden from all users.
 	// +optional
 	AllowedGroups []string `json:"allowedGroups,omitempty"`
 }
 
+// time window in which scheduled projects are started
+type RenovateExecutionWindow struct {
+	// Days the window starts on, every day if empty
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// Start of the window in the format HH:MM
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+	// End of the window in the format HH:MM. Windows ending before their start end on the next day
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+	// IANA time zone of the window, defaults to the time zone of the RenovateJob
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=Mon;Tue;Wed;Thu;Fri;Sat;Sun
+type Weekday string
+
+type RenovateDiscoveryMode string
+
+// limits of the kubernetes jobs created for a RenovateJob
+type RenovateJobLimits struct {
+	// Maximum duration of the job in seconds, replaces JOB_TIMEOUT_SECONDS of the operator
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+	// Number of retries of the pod before the job fails, replaces JOB_BACKOFF_LIMIT of the operator
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// Seconds a finished job is kept before it is deleted, replaces JOB_TTL_SECONDS_AFTER_FINISHED of the operator
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// pod templates merged over the pods generated by the operator. containers, init containers and volumes
+// are merged by their name, the generated containers are named "discovery" and "renovate".
+type RenovatePodTemplates struct {
+	// Pod template merged over the discovery pods, e.g. {"spec": {"priorityClassName": "low"}}
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	Discovery *runtime.RawExtension `json:"discovery,omitempty"`
+	// Pod template merged over the executor pods, e.g. {"spec": {"containers": [{"name": "proxy", "image": "envoy"}]}}
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +optional
+	Executor *runtime.RawExtension `json:"executor,omitempty"`
+}
+
+// validation of the renovate config in a short job before it is used by scheduled runs
+type RenovateConfigValidation struct {
+	// What happens while the config is invalid. "block" does not start scheduled projects until the config
+	// validates, "warn" only reports the result in the ConfigValid condition. Defaults to "warn".
+	// +kubebuilder:validation:Enum=block;warn
+	// +kubebuilder:default=warn
+	// +optional
+	Mode RenovateConfigValidationMode `json:"mode,omitempty"`
+	// Maximum duration of the validator job in seconds. Defaults to 300.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+}
+
+type RenovateConfigValidationMode string
+
+const (
+	ConfigValidationModeBlock RenovateConfigValidationMode = "block"
+	ConfigValidationModeWarn  RenovateConfigValidationMode = "warn"
+)
+
+// persistent volumes holding the renovate cache and repository cache of the executor jobs
+type RenovateCache struct {
+	// Requested size of each cache volume, e.g. "10Gi"
+	Size resource.Quantity `json:"size"`
+	// Storage class of the cache volumes, the default storage class is used if not set
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// Access mode of the cache volumes. Volumes that cannot be shared between nodes are used
+	// by a single running project at a time, other projects of the same shard wait until it finished.
+	// +kubebuilder:validation:Enum=ReadWriteOnce;ReadWriteOncePod;ReadWriteMany
+	// +kubebuilder:default=ReadWriteOnce
+	// +optional
+	AccessMode corev1.PersistentVolumeAccessMode `json:"accessMode,omitempty"`
+	// Number of cache volumes. Every project is assigned to one of them by its name. Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Shards int32 `json:"shards,omitempty"`
+	// Usage of a cache volume in percent from which outdated cache files are removed before a project starts.
+	// Defaults to 80.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	CleanupThreshold int32 `json:"cleanupThreshold,omitempty"`
+}
+
+// settings of the executor job for projects matching a name or glob
+type RenovateProjectOverride struct {
+	// Project name or glob, e.g. "org/monorepo" or "org/mono-*"
+	Project string `json:"project"`
+	// Renovate Docker image to use instead of the image of the RenovateJob
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Resource requirements for the renovate container, replacing the resources of the RenovateJob
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Additional environment variables, taking precedence over the extraEnv of the RenovateJob
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+	// Node selector replacing the node selector of the RenovateJob
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Timeout of the executor job in seconds, replacing the executor timeout of the RenovateJob
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+	// Minimum priority of the scheduled project in the queue of the RenovateJob.
+	// Projects triggered by webhooks have a priority of 1, projects triggered from the UI of 2.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// include or exclude rule for discovered projects, exactly one of glob, regex and prefix must be set
+// +kubebuilder:validation:XValidation:rule="(has(self.glob) ? 1 : 0) + (has(self.regex) ? 1 : 0) + (has(self.prefix) ? 1 : 0) == 1",message="exactly one of glob, regex and prefix must be set"
+type RenovateProjectFilter struct {
+	// Whether matching projects are included or excluded
+	Action RenovateProjectFilterAction `json:"action"`
+	// Glob matching the project name, e.g. "platform/legacy-*". "*" does not match "/", "**" does
+	// +optional
+	Glob string `json:"glob,omitempty"`
+	// Regular expression matching the project name, e.g. "^platform/.*-service$"
+	// +optional
+	Regex string `json:"regex,omitempty"`
+	// Namespace or group prefix, e.g. "platform" matches "platform/api" and "platform/team/api"
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// +kubebuilder:validation:Enum=include;exclude
+type RenovateProjectFilterAction string
+
+const (
+	ProjectFilterInclude RenovateProjectFilterAction = "include"
+	ProjectFilterExclude RenovateProjectFilterAction = "exclude"
+)
+
+const (
+	DiscoveryModePod RenovateDiscoveryMode = "pod"
+	DiscoveryModeAPI RenovateDiscoveryMode = "api"
+)
+
+// retry configuration for failed projects
+type RenovateRetryPolicy struct {
+	// Maximum number of runs per project including the initial one
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int32 `json:"maxAttempts"`
+	// Delay before the first retry, defaults to 5m
+	// +optional
+	InitialDelay *metav1.Duration `json:"initialDelay,omitempty"`
+	// Factor the delay is multiplied with for every further retry, defaults to 2
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BackoffFactor int32 `json:"backoffFactor,omitempty"`
+}
+
 // configuration regarding serviceaccounts for the resulting pod
 type RenovateJobServiceAccount struct {
 	AutomountServiceAccountToken *bool  `json:"automountServiceAccountToken,omitempty"`
@@ -136,6 +413,18 @@ type ProjectStatus struct {
 	Status               RenovateProjectStatus `json:"status"`
 	Priority             int32                 `json:"priority,omitempty"`
 	RenovateResultStatus *string               `json:"renovateResultStatus,omitempty"`
+	// Number of failed runs since the project was last scheduled by its schedule, a webhook or the UI.
+	// Limits the retries of the retry policy and is reset by any scheduling other than a retry or once a run completes
+	Attempts int32 `json:"attempts,omitempty"`
+	// Time the failed project will be scheduled again by the retry policy
+	NextRetry *metav1.Time `json:"nextRetry,omitempty"`
+	// Number of consecutive failed runs across schedules, reset once a run completes or the project is released from quarantine.
+	// Unlike attempts it is kept when the project is scheduled again, so it is never lower than attempts
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// Earliest time the scheduled project is started, set if the start is spread by the schedule
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+	// Time of the last successful run
+	LastSuccess *metav1.Time `json:"lastSuccess,omitempty"`
 }
 
 type RenovateProjectStatus string
@@ -145,13 +434,85 @@ const (
 	JobStatusRunning   RenovateProjectStatus = "running"
 	JobStatusCompleted RenovateProjectStatus = "completed"
 	JobStatusFailed    RenovateProjectStatus = "failed"
+	// the project failed too often in a row and is not scheduled until it is released
+	JobStatusQuarantined RenovateProjectStatus = "quarantined"
 )
 
 // RenovateJobStatus defines the observed state of RenovateJob
-// +kubebuilder:object:root=true
 type RenovateJobStatus struct {
+	// Deprecated: the state of each project is stored in a RenovateProject resource.
+	// Existing entries are migrated by the operator and removed afterwards.
 	Projects         []ProjectStatus           `json:"projects,omitempty"`
 	ExecutionOptions *RenovateExecutionOptions `json:"executionOptions,omitempty"`
+	// Projects the last discovery would have removed, set while the removal exceeds maxProjectRemovals
+	// +optional
+	PendingProjectRemoval *RenovatePendingProjectRemoval `json:"pendingProjectRemoval,omitempty"`
+	// Results of the last discoveries, the latest first
+	// +optional
+	DiscoveryHistory []RenovateDiscoveryResult `json:"discoveryHistory,omitempty"`
+	// Number of projects removed by repository events since the last discovery, counted towards maxProjectRemovals
+	// +optional
+	RepositoryEventRemovals int32 `json:"repositoryEventRemovals,omitempty"`
+	// Current state of the RenovateJob
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Fingerprint of the renovate config the ConfigValid condition was determined for
+	// +optional
+	ValidatedConfigHash string `json:"validatedConfigHash,omitempty"`
+	// Fingerprint of the contents of the referenced secrets, set if rescheduleFailedOnSecretChange is enabled
+	// +optional
+	SecretsHash string `json:"secretsHash,omitempty"`
+}
+
+// condition types of a RenovateJob
+const (
+	// the last discovery removed more projects than allowed and was not applied
+	ConditionProjectRemovalBlocked = "ProjectRemovalBlocked"
+	// result of the last validation of the renovate config, unknown while the validator job runs
+	ConditionConfigValid = "ConfigValid"
+)
+
+// changes of the projects by a single discovery
+type RenovateDiscoveryResult struct {
+	// Time of the discovery
+	Time metav1.Time `json:"time"`
+	// Number of projects returned by the discovery
+	Discovered int32 `json:"discovered"`
+	// Number of projects discovered for the first time
+	AddedCount int32 `json:"addedCount"`
+	// Number of projects no longer discovered
+	RemovedCount int32 `json:"removedCount"`
+	// Projects discovered for the first time, truncated for large discoveries
+	// +optional
+	Added []string `json:"added,omitempty"`
+	// Projects no longer discovered, truncated for large discoveries
+	// +optional
+	Removed []string `json:"removed,omitempty"`
+	// If true, the discovery removed more projects than allowed and was not applied
+	// +optional
+	Blocked bool `json:"blocked,omitempty"`
+	// Origin of the result, empty for a discovery
+	// +optional
+	Source string `json:"source,omitempty"`
+}
+
+// sources of a discovery result
+const (
+	// projects removed by repository events of a webhook
+	DiscoverySourceRepositoryEvent = "RepositoryEvent"
+)
+
+// removal of projects by a discovery that waits for confirmation
+type RenovatePendingProjectRemoval struct {
+	// Projects that are no longer discovered
+	Projects []string `json:"projects"`
+	// Number of projects returned by the discovery
+	DiscoveredProjects int32 `json:"discoveredProjects"`
+	// Projects returned by the discovery, applied once the removal is confirmed
+	// +optional
+	DiscoveredProjectNames []string `json:"discoveredProjectNames,omitempty"`
+	// Time of the discovery
+	DiscoveredAt metav1.Time `json:"discoveredAt"`
 }
 
 type RenovateExecutionOptions struct {
@@ -169,35 +530,18 @@ type RenovateJob struct {
 	Status RenovateJobStatus `json:"status,omitempty"`
 }
 
-func (in *RenovateJob) DeepCopyObject() runtime.Object {
-	if in == nil {
-		return nil
-	}
-	out := new(RenovateJob)
-	*out = *in
-	return out
-}
-
 // unique name for a renovatejob ${name}-${namespace}
 func (in *RenovateJob) Fullname() string {
 	return in.Name + "-" + in.Namespace
 }
 
+// +kubebuilder:object:root=true
 type RenovateJobList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []RenovateJob `json:"items"`
 }
 
-func (in *RenovateJobList) DeepCopyObject() runtime.Object {
-	if in == nil {
-		return nil
-	}
-	out := new(RenovateJobList)
-	*out = *in
-	return out
-}
-
 func init() {
 	SchemeBuilder.Register(&RenovateJob{}, &RenovateJobList{})
 }
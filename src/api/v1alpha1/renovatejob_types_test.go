@@ -2,7 +2,9 @@ package v1alpha1
 
 import (
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -84,6 +86,62 @@ func TestRenovateJob_DeepCopyObject(t *testing.T) {
 		}
 	})
 
+	t.Run("copy does not share metadata and status", func(t *testing.T) {
+		original := &RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-job",
+				Annotations: map[string]string{"key": "value"},
+			},
+			Status: RenovateJobStatus{
+				Projects:         []ProjectStatus{{Name: "p1", Status: JobStatusScheduled}},
+				ExecutionOptions: &RenovateExecutionOptions{Debug: false},
+			},
+		}
+
+		copiedJob := original.DeepCopyObject().(*RenovateJob)
+		copiedJob.Annotations["key"] = "changed"
+		copiedJob.Status.Projects[0].Status = JobStatusRunning
+		copiedJob.Status.ExecutionOptions.Debug = true
+
+		if original.Annotations["key"] != "value" {
+			t.Errorf("annotations of the original were modified")
+		}
+		if original.Status.Projects[0].Status != JobStatusScheduled {
+			t.Errorf("projects of the original were modified")
+		}
+		if original.Status.ExecutionOptions.Debug {
+			t.Errorf("execution options of the original were modified")
+		}
+	})
+
+	t.Run("copy does not share spec slices and status pointers", func(t *testing.T) {
+		nextRetry := metav1.NewTime(time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC))
+		original := &RenovateJob{
+			Spec: RenovateJobSpec{
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+				Projects:         []string{"org/repo"},
+			},
+			Status: RenovateJobStatus{
+				Projects: []ProjectStatus{{Name: "p1", NextRetry: &nextRetry}},
+			},
+		}
+
+		copiedJob := original.DeepCopyObject().(*RenovateJob)
+		copiedJob.Spec.ImagePullSecrets[0].Name = "changed"
+		copiedJob.Spec.Projects[0] = "org/changed"
+		copiedJob.Status.Projects[0].NextRetry.Time = time.Time{}
+
+		if original.Spec.ImagePullSecrets[0].Name != "registry" {
+			t.Errorf("image pull secrets of the original were modified")
+		}
+		if original.Spec.Projects[0] != "org/repo" {
+			t.Errorf("projects in the spec of the original were modified")
+		}
+		if !original.Status.Projects[0].NextRetry.Equal(&nextRetry) {
+			t.Errorf("next retry of the original was modified")
+		}
+	})
+
 	t.Run("nil object", func(t *testing.T) {
 		var original *RenovateJob = nil
 		copied := original.DeepCopyObject()
@@ -113,3 +171,27 @@ func TestRenovateProjectStatus_Constants(t *testing.T) {
 		})
 	}
 }
+
+func TestRenovateProject_DeepCopyObject(t *testing.T) {
+	lastSuccess := metav1.NewTime(time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC))
+	duration := "1m"
+	original := &RenovateProject{
+		ObjectMeta: metav1.ObjectMeta{Name: "project", Labels: map[string]string{"key": "value"}},
+		Status:     ProjectStatus{Name: "org/repo", LastSuccess: &lastSuccess, Duration: &duration},
+	}
+
+	copied := original.DeepCopyObject().(*RenovateProject)
+	copied.Labels["key"] = "changed"
+	copied.Status.LastSuccess.Time = time.Time{}
+	*copied.Status.Duration = "2m"
+
+	if original.Labels["key"] != "value" {
+		t.Errorf("labels of the original were modified")
+	}
+	if !original.Status.LastSuccess.Equal(&lastSuccess) {
+		t.Errorf("last success of the original was modified")
+	}
+	if *original.Status.Duration != "1m" {
+		t.Errorf("duration of the original was modified")
+	}
+}
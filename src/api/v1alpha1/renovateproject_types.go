@@ -0,0 +1,40 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RenovateProjectSpec defines which project of which RenovateJob this resource tracks
+type RenovateProjectSpec struct {
+	// Name of the RenovateJob (in the same namespace) this project belongs to
+	RenovateJob string `json:"renovateJob"`
+	// Full name of the project on the git platform, e.g. "org/repo"
+	Project string `json:"project"`
+}
+
+// RenovateProject holds the state of a single project of a RenovateJob.
+// It is created and owned by the operator, one resource per discovered project.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Job",type=string,JSONPath=`.spec.renovateJob`
+// +kubebuilder:printcolumn:name="Project",type=string,JSONPath=`.spec.project`
+// +kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.status`
+// +kubebuilder:printcolumn:name="Last Run",type=date,JSONPath=`.status.lastRun`
+type RenovateProject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RenovateProjectSpec `json:"spec,omitempty"`
+	Status ProjectStatus       `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type RenovateProjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RenovateProject `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RenovateProject{}, &RenovateProjectList{})
+}
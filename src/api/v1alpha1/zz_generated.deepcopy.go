@@ -0,0 +1,874 @@
+//go:build !ignore_autogenerated
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectStatus) DeepCopyInto(out *ProjectStatus) {
+	*out = *in
+	in.LastRun.DeepCopyInto(&out.LastRun)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(string)
+		**out = **in
+	}
+	if in.RenovateResultStatus != nil {
+		in, out := &in.RenovateResultStatus, &out.RenovateResultStatus
+		*out = new(string)
+		**out = **in
+	}
+	if in.NextRetry != nil {
+		in, out := &in.NextRetry, &out.NextRetry
+		*out = (*in).DeepCopy()
+	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccess != nil {
+		in, out := &in.LastSuccess, &out.LastSuccess
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectStatus.
+func (in *ProjectStatus) DeepCopy() *ProjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateCache) DeepCopyInto(out *RenovateCache) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateCache.
+func (in *RenovateCache) DeepCopy() *RenovateCache {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateConfigValidation) DeepCopyInto(out *RenovateConfigValidation) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateConfigValidation.
+func (in *RenovateConfigValidation) DeepCopy() *RenovateConfigValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateConfigValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateDiscoveryResult) DeepCopyInto(out *RenovateDiscoveryResult) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Added != nil {
+		in, out := &in.Added, &out.Added
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Removed != nil {
+		in, out := &in.Removed, &out.Removed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateDiscoveryResult.
+func (in *RenovateDiscoveryResult) DeepCopy() *RenovateDiscoveryResult {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateDiscoveryResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateExecutionOptions) DeepCopyInto(out *RenovateExecutionOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateExecutionOptions.
+func (in *RenovateExecutionOptions) DeepCopy() *RenovateExecutionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateExecutionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateExecutionWindow) DeepCopyInto(out *RenovateExecutionWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateExecutionWindow.
+func (in *RenovateExecutionWindow) DeepCopy() *RenovateExecutionWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateExecutionWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJob) DeepCopyInto(out *RenovateJob) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJob.
+func (in *RenovateJob) DeepCopy() *RenovateJob {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RenovateJob) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobLimits) DeepCopyInto(out *RenovateJobLimits) {
+	*out = *in
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobLimits.
+func (in *RenovateJobLimits) DeepCopy() *RenovateJobLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobList) DeepCopyInto(out *RenovateJobList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RenovateJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobList.
+func (in *RenovateJobList) DeepCopy() *RenovateJobList {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RenovateJobList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobMetadata) DeepCopyInto(out *RenovateJobMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobMetadata.
+func (in *RenovateJobMetadata) DeepCopy() *RenovateJobMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobSecurityContext) DeepCopyInto(out *RenovateJobSecurityContext) {
+	*out = *in
+	if in.Pod != nil {
+		in, out := &in.Pod, &out.Pod
+		*out = new(corev1.PodSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(corev1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobSecurityContext.
+func (in *RenovateJobSecurityContext) DeepCopy() *RenovateJobSecurityContext {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobSecurityContext)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobServiceAccount) DeepCopyInto(out *RenovateJobServiceAccount) {
+	*out = *in
+	if in.AutomountServiceAccountToken != nil {
+		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobServiceAccount.
+func (in *RenovateJobServiceAccount) DeepCopy() *RenovateJobServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobSpec) DeepCopyInto(out *RenovateJobSpec) {
+	*out = *in
+	if in.Spread != nil {
+		in, out := &in.Spread, &out.Spread
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxStaleness != nil {
+		in, out := &in.MaxStaleness, &out.MaxStaleness
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ExecutionWindows != nil {
+		in, out := &in.ExecutionWindows, &out.ExecutionWindows
+		*out = make([]RenovateExecutionWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(RenovateProvider)
+		**out = **in
+	}
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Autodiscover != nil {
+		in, out := &in.Autodiscover, &out.Autodiscover
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SkipInactiveFor != nil {
+		in, out := &in.SkipInactiveFor, &out.SkipInactiveFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ProjectFilters != nil {
+		in, out := &in.ProjectFilters, &out.ProjectFilters
+		*out = make([]RenovateProjectFilter, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxProjectRemovals != nil {
+		in, out := &in.MaxProjectRemovals, &out.MaxProjectRemovals
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraEnvFrom != nil {
+		in, out := &in.ExtraEnvFrom, &out.ExtraEnvFrom
+		*out = make([]corev1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RenovateRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(RenovateJobServiceAccount)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(RenovateJobMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(RenovateJobSecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(RenovateWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscoveryJob != nil {
+		in, out := &in.DiscoveryJob, &out.DiscoveryJob
+		*out = new(RenovateJobLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExecutorJob != nil {
+		in, out := &in.ExecutorJob, &out.ExecutorJob
+		*out = new(RenovateJobLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProjectOverrides != nil {
+		in, out := &in.ProjectOverrides, &out.ProjectOverrides
+		*out = make([]RenovateProjectOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RenovateConfig != nil {
+		in, out := &in.RenovateConfig, &out.RenovateConfig
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigValidation != nil {
+		in, out := &in.ConfigValidation, &out.ConfigValidation
+		*out = new(RenovateConfigValidation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(RenovatePodTemplates)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cache != nil {
+		in, out := &in.Cache, &out.Cache
+		*out = new(RenovateCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedGroups != nil {
+		in, out := &in.AllowedGroups, &out.AllowedGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobSpec.
+func (in *RenovateJobSpec) DeepCopy() *RenovateJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateJobStatus) DeepCopyInto(out *RenovateJobStatus) {
+	*out = *in
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]ProjectStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExecutionOptions != nil {
+		in, out := &in.ExecutionOptions, &out.ExecutionOptions
+		*out = new(RenovateExecutionOptions)
+		**out = **in
+	}
+	if in.PendingProjectRemoval != nil {
+		in, out := &in.PendingProjectRemoval, &out.PendingProjectRemoval
+		*out = new(RenovatePendingProjectRemoval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiscoveryHistory != nil {
+		in, out := &in.DiscoveryHistory, &out.DiscoveryHistory
+		*out = make([]RenovateDiscoveryResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateJobStatus.
+func (in *RenovateJobStatus) DeepCopy() *RenovateJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovatePendingProjectRemoval) DeepCopyInto(out *RenovatePendingProjectRemoval) {
+	*out = *in
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscoveredProjectNames != nil {
+		in, out := &in.DiscoveredProjectNames, &out.DiscoveredProjectNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.DiscoveredAt.DeepCopyInto(&out.DiscoveredAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovatePendingProjectRemoval.
+func (in *RenovatePendingProjectRemoval) DeepCopy() *RenovatePendingProjectRemoval {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovatePendingProjectRemoval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovatePodTemplates) DeepCopyInto(out *RenovatePodTemplates) {
+	*out = *in
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Executor != nil {
+		in, out := &in.Executor, &out.Executor
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovatePodTemplates.
+func (in *RenovatePodTemplates) DeepCopy() *RenovatePodTemplates {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovatePodTemplates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateProject) DeepCopyInto(out *RenovateProject) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateProject.
+func (in *RenovateProject) DeepCopy() *RenovateProject {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RenovateProject) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateProjectFilter) DeepCopyInto(out *RenovateProjectFilter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateProjectFilter.
+func (in *RenovateProjectFilter) DeepCopy() *RenovateProjectFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateProjectFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateProjectList) DeepCopyInto(out *RenovateProjectList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RenovateProject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateProjectList.
+func (in *RenovateProjectList) DeepCopy() *RenovateProjectList {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateProjectList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RenovateProjectList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateProjectOverride) DeepCopyInto(out *RenovateProjectOverride) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateProjectOverride.
+func (in *RenovateProjectOverride) DeepCopy() *RenovateProjectOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateProjectOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateProjectSpec) DeepCopyInto(out *RenovateProjectSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateProjectSpec.
+func (in *RenovateProjectSpec) DeepCopy() *RenovateProjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateProjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateProvider) DeepCopyInto(out *RenovateProvider) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateProvider.
+func (in *RenovateProvider) DeepCopy() *RenovateProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateRetryPolicy) DeepCopyInto(out *RenovateRetryPolicy) {
+	*out = *in
+	if in.InitialDelay != nil {
+		in, out := &in.InitialDelay, &out.InitialDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateRetryPolicy.
+func (in *RenovateRetryPolicy) DeepCopy() *RenovateRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateSecretKeyReference) DeepCopyInto(out *RenovateSecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateSecretKeyReference.
+func (in *RenovateSecretKeyReference) DeepCopy() *RenovateSecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateSecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateWebhook) DeepCopyInto(out *RenovateWebhook) {
+	*out = *in
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(RenovateWebhookAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Forgejo != nil {
+		in, out := &in.Forgejo, &out.Forgejo
+		*out = new(RenovateWebhookForgejo)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateWebhook.
+func (in *RenovateWebhook) DeepCopy() *RenovateWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateWebhookAuth) DeepCopyInto(out *RenovateWebhookAuth) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(RenovateSecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateWebhookAuth.
+func (in *RenovateWebhookAuth) DeepCopy() *RenovateWebhookAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateWebhookAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateWebhookForgejo) DeepCopyInto(out *RenovateWebhookForgejo) {
+	*out = *in
+	if in.Sync != nil {
+		in, out := &in.Sync, &out.Sync
+		*out = new(RenovateWebhookForgejoSync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateWebhookForgejo.
+func (in *RenovateWebhookForgejo) DeepCopy() *RenovateWebhookForgejo {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateWebhookForgejo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenovateWebhookForgejoSync) DeepCopyInto(out *RenovateWebhookForgejoSync) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TokenSecretRef != nil {
+		in, out := &in.TokenSecretRef, &out.TokenSecretRef
+		*out = new(RenovateSecretKeyReference)
+		**out = **in
+	}
+	if in.AuthTokenSecretRef != nil {
+		in, out := &in.AuthTokenSecretRef, &out.AuthTokenSecretRef
+		*out = new(RenovateSecretKeyReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenovateWebhookForgejoSync.
+func (in *RenovateWebhookForgejoSync) DeepCopy() *RenovateWebhookForgejoSync {
+	if in == nil {
+		return nil
+	}
+	out := new(RenovateWebhookForgejoSync)
+	in.DeepCopyInto(out)
+	return out
+}
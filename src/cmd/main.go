@@ -7,9 +7,12 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	// the operator image contains no time zone database, required for time zones of RenovateJobs
+	_ "time/tzdata"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	api "renovate-operator/api/v1alpha1"
@@ -26,6 +29,7 @@ import (
 	"renovate-operator/ui"
 	"renovate-operator/webhook"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
 
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -125,6 +129,36 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Key:      "MAX_CONCURRENT_EXECUTOR_JOBS",
+			Optional: true,
+			Default:  "0",
+			Validate: func(value string) error {
+				parsed, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("'MAX_CONCURRENT_EXECUTOR_JOBS' needs to be an integer: %s", err.Error())
+				}
+				if parsed < 0 {
+					return fmt.Errorf("'MAX_CONCURRENT_EXECUTOR_JOBS' needs to be 0 or greater")
+				}
+				return nil
+			},
+		},
+		{
+			Key:      "MAX_CONCURRENT_EXECUTOR_JOBS_PER_NAMESPACE",
+			Optional: true,
+			Default:  "0",
+			Validate: func(value string) error {
+				parsed, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					return fmt.Errorf("'MAX_CONCURRENT_EXECUTOR_JOBS_PER_NAMESPACE' needs to be an integer: %s", err.Error())
+				}
+				if parsed < 0 {
+					return fmt.Errorf("'MAX_CONCURRENT_EXECUTOR_JOBS_PER_NAMESPACE' needs to be 0 or greater")
+				}
+				return nil
+			},
+		},
 		{
 			Key:      "WATCH_NAMESPACE",
 			Optional: true,
@@ -230,6 +264,8 @@ func main() {
 		LeaderElectionNamespace:       config.GetValue("POD_NAMESPACE"),
 		LeaderElectionReleaseOnCancel: true,
 		Cache:                         cache.Options{DefaultNamespaces: map[string]cache.Config{watchNamespace: {}}},
+		// secrets are read directly, only their metadata is watched and cached
+		Client: client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}}},
 	}
 
 	mgr, err := ctrl.NewManager(cfg, mgrOptions)
@@ -245,7 +281,7 @@ func main() {
 	health := health.NewHealthCheck()
 	ctx := ctrl.SetupSignalHandler()
 
-	jobMgr := crdManager.NewRenovateJobManager(mgr.GetClient())
+	jobMgr := crdManager.NewRenovateJobManager(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetEventRecorder("renovate-operator"))
 
 	discovery := renovate.NewDiscoveryAgent(
 		mgr.GetScheme(),
@@ -354,10 +390,19 @@ func main() {
 		}
 	}()
 
+	configValidator := renovate.NewConfigValidator(
+		mgr.GetScheme(),
+		jobMgr,
+		mgr.GetClient(),
+		ctrl.Log.WithName("renovate-config-validator"),
+	)
+
 	err = (&controllers.RenovateJobReconciler{
 		Scheduler:                cronManager,
 		Manager:                  jobMgr,
 		Discovery:                discovery,
+		Executor:                 executor,
+		ConfigValidator:          configValidator,
 		K8sClient:                mgr.GetClient(),
 		GitProviderClientFactory: gitProviderClientFactory,
 	}).SetupWithManager(mgr)
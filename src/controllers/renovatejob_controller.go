@@ -2,6 +2,7 @@ package controllers
 
 import (
 	context "context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	api "renovate-operator/api/v1alpha1"
@@ -13,16 +14,22 @@ import (
 	"renovate-operator/scheduler"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/go-logr/logr"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	crdManager "renovate-operator/internal/crdManager"
 )
@@ -31,10 +38,13 @@ const webhookSyncStateAnnotation = "renovate-operator.mogenius.com/webhook-sync-
 
 /*
 Reconciler for RenovateJob resources
-Watching for create/update/delete events and managing the schedules accordingly
+Watching for create/update/delete events and managing the schedules accordingly.
+Changes to owned executor jobs and projects only trigger the executor and do not reconcile the RenovateJob.
 */
 type RenovateJobReconciler struct {
 	Discovery                renovate.DiscoveryAgent
+	Executor                 renovate.RenovateExecutor
+	ConfigValidator          renovate.ConfigValidator
 	Manager                  crdManager.RenovateJobManager
 	Scheduler                scheduler.Scheduler
 	K8sClient                client.Client
@@ -52,25 +62,84 @@ func (r *RenovateJobReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	renovateJob, err := r.Manager.GetRenovateJob(ctx, req.Name, req.Namespace)
 
 	if err == nil {
+		// move projects still stored in the renovatejob status to their own resources
+		if len(renovateJob.Status.Projects) > 0 {
+			if err := r.Manager.MigrateLegacyProjectStatus(ctx, crdManager.RenovateJobIdentifier{
+				Name:      renovateJob.Name,
+				Namespace: renovateJob.Namespace,
+			}); err != nil {
+				logger.Error(err, "Failed to migrate project status to RenovateProjects")
+				return ctrl.Result{}, err
+			}
+		}
+
 		// renovatejob object read without problem -> create the schedule
+		// failed steps do not stop the others, their errors are returned together so the renovatejob is requeued with backoff
+		var reconcileErrors []error
 		r.ensureWebhookSyncer(ctx, logger, renovateJob)
-		createScheduler(logger, renovateJob, r)
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		if r.K8sClient != nil {
+			r.rescheduleOnSecretChange(ctx, logger, renovateJob)
+			// cache volumes and config are owned by the renovatejob and removed by the garbage collector together with it
+			if err := crdManager.EnsureCacheVolumes(ctx, r.K8sClient, renovateJob); err != nil {
+				logger.Error(err, "Failed to reconcile cache volumes")
+				reconcileErrors = append(reconcileErrors, fmt.Errorf("failed to reconcile cache volumes: %w", err))
+			}
+			if err := crdManager.EnsureRenovateConfig(ctx, r.K8sClient, renovateJob); err != nil {
+				logger.Error(err, "Failed to reconcile renovate config")
+				reconcileErrors = append(reconcileErrors, fmt.Errorf("failed to reconcile renovate config: %w", err))
+			}
+		}
+		// the validator job is started once the config changed, its result is recorded when the owned job finished
+		result := ctrl.Result{}
+		if r.ConfigValidator != nil {
+			retryAfter, err := r.ConfigValidator.Validate(ctx, renovateJob)
+			if err != nil {
+				logger.Error(err, "Failed to validate renovate config")
+				reconcileErrors = append(reconcileErrors, fmt.Errorf("failed to validate renovate config: %w", err))
+			}
+			result.RequeueAfter = retryAfter
+		}
+		if renovateJob.Spec.Suspend {
+			// suspended renovatejobs keep their projects, but are not scheduled anymore
+			r.Scheduler.RemoveSchedule(renovateJob.Fullname())
+		} else {
+			createScheduler(logger, renovateJob, r)
+		}
+
+		// the spec changed -> let the executor pick up the changes
+		r.triggerExecutor(crdManager.RenovateJobIdentifier{
+			Name:      renovateJob.Name,
+			Namespace: renovateJob.Namespace,
+		})
+		if len(reconcileErrors) > 0 {
+			return ctrl.Result{}, utilerrors.NewAggregate(reconcileErrors)
+		}
+		return result, nil
 	} else if errors.IsNotFound(err) {
 		// renovatejob cannot be found -> delete the schedule
 		name := req.Name + "-" + req.Namespace
 		r.Scheduler.RemoveSchedule(name)
 		delete(r.webhookSyncers, name)
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		return ctrl.Result{}, nil
 	} else {
 		logger.Error(err, "Failed to get RenovateJob")
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, err
+		return ctrl.Result{}, err
+	}
+}
+
+// discover the projects of the renovatejob, apply its filters and skip options and merge them with its static projects
+func discoverProjects(ctx context.Context, logger logr.Logger, renovateJob *api.RenovateJob, reconciler *RenovateJobReconciler) ([]string, error) {
+	projects, err := reconciler.Discovery.Discover(ctx, renovateJob)
+	if err != nil {
+		return nil, err
 	}
+	logger.V(2).Info("Successfully discovered projects", "count", len(projects))
+	return renovate.ApplyDiscoveryFilters(ctx, logger, renovateJob, projects, reconciler.GitProviderClientFactory)
 }
 
 func createScheduler(logger logr.Logger, renovateJob *api.RenovateJob, reconciler *RenovateJobReconciler) {
 	name := renovateJob.Fullname()
-	expr := renovateJob.Spec.Schedule
+	expr := utils.GetScheduleExpression(&renovateJob.Spec)
 	jobName := renovateJob.Name
 	jobNamespace := renovateJob.Namespace
 	f := func() {
@@ -85,25 +154,14 @@ func createScheduler(logger logr.Logger, renovateJob *api.RenovateJob, reconcile
 			return
 		}
 
-		projects, err := reconciler.Discovery.Discover(ctx, currentJob)
-		if err != nil {
-			logger.Error(err, "Failed to discover projects for RenovateJob")
-			return
-		}
-		logger.V(2).Info("Successfully discovered projects", "count", len(projects))
-
-		if currentJob.Spec.SkipForks && reconciler.GitProviderClientFactory != nil {
-			providerClient, err := reconciler.GitProviderClientFactory(ctx, currentJob)
-			if err != nil {
-				logger.Error(err, "Failed to create git provider client for fork filtering")
-				return
-			}
-			projects, err = gitprovider.FilterForks(ctx, providerClient, logger, projects)
+		// static projects are reconciled without starting a discovery
+		projects := currentJob.Spec.Projects
+		if utils.IsAutodiscoveryEnabled(&currentJob.Spec) {
+			projects, err = discoverProjects(ctx, logger, currentJob, reconciler)
 			if err != nil {
-				logger.Error(err, "Failed to filter forked repositories")
+				logger.Error(err, "Failed to discover projects for RenovateJob")
 				return
 			}
-			logger.V(2).Info("Filtered forked repositories", "remaining", len(projects))
 		}
 
 		jobIdentifier := crdManager.RenovateJobIdentifier{
@@ -111,18 +169,26 @@ func createScheduler(logger logr.Logger, renovateJob *api.RenovateJob, reconcile
 			Namespace: jobNamespace,
 		}
 		err = reconciler.Manager.ReconcileProjects(ctx, jobIdentifier, projects)
-		if err != nil {
+		if crdManager.IsProjectRemovalBlocked(err) {
+			// the existing projects are kept and still scheduled until the removal is confirmed
+			logger.Info("Discovered projects are not applied until the removal is confirmed", "reason", err.Error())
+		} else if err != nil {
 			logger.Error(err, "failed to reconcile projects")
 			return
+		} else {
+			logger.V(2).Info("Successfully reconciled Projects")
 		}
-		logger.V(2).Info("Successfully reconciled Projects")
 
 		isNotRunning := func(p api.ProjectStatus) bool {
 			return p.Status != api.JobStatusRunning
 		}
-		err = reconciler.Manager.UpdateProjectStatusBatched(ctx, isNotRunning, jobIdentifier, &types.RenovateStatusUpdate{
+		scheduledStatus := &types.RenovateStatusUpdate{
 			Status: api.JobStatusScheduled,
-		})
+		}
+		if currentJob.Spec.Spread != nil {
+			scheduledStatus.Spread = currentJob.Spec.Spread.Duration
+		}
+		err = reconciler.Manager.UpdateProjectStatusBatched(ctx, isNotRunning, jobIdentifier, scheduledStatus)
 
 		if err != nil {
 			logger.Error(err, "failed to schedule projects")
@@ -163,13 +229,6 @@ func (r *RenovateJobReconciler) ensureWebhookSyncer(ctx context.Context, logger
 
 	syncCfg := renovateJob.Spec.Webhook.Forgejo.Sync
 	_, providerEndpoint := utils.GetPlatformAndEndpoint(renovateJob.Spec.Provider)
-	fp := syncFingerprint(syncCfg, providerEndpoint, renovateJob.Spec.DiscoverTopics, renovateJob.Namespace, renovateJob.Name)
-
-	// Config unchanged — nothing to do
-	if entry, exists := r.webhookSyncers[name]; exists && entry.fingerprint == fp {
-		return
-	}
-
 	jobNamespace := renovateJob.Namespace
 
 	if syncCfg.TokenSecretRef == nil {
@@ -192,6 +251,14 @@ func (r *RenovateJobReconciler) ensureWebhookSyncer(ctx context.Context, logger
 		}
 	}
 
+	// the fingerprint covers the token contents, so rotated tokens replace the syncer
+	fp := syncFingerprint(syncCfg, providerEndpoint, renovateJob.Spec.DiscoverTopics, renovateJob.Namespace, renovateJob.Name, forgejoToken, authToken)
+
+	// Config unchanged — nothing to do
+	if entry, exists := r.webhookSyncers[name]; exists && entry.fingerprint == fp {
+		return
+	}
+
 	topic := syncCfg.Topic
 	if topic == "" {
 		topic = renovateJob.Spec.DiscoverTopics
@@ -239,8 +306,9 @@ func (r *RenovateJobReconciler) ensureWebhookSyncer(ctx context.Context, logger
 	r.webhookSyncers[name] = &webhookSyncerEntry{syncer: syncer, fingerprint: fp}
 }
 
-// syncFingerprint produces a string that changes when any sync-relevant config changes.
-func syncFingerprint(cfg *api.RenovateWebhookForgejoSync, endpoint, defaultTopic, namespace, jobName string) string {
+// syncFingerprint produces a string that changes when any sync-relevant config or token changes.
+// The tokens are only included as hash.
+func syncFingerprint(cfg *api.RenovateWebhookForgejoSync, endpoint, defaultTopic, namespace, jobName, token, authToken string) string {
 	topic := cfg.Topic
 	if topic == "" {
 		topic = defaultTopic
@@ -254,7 +322,8 @@ func syncFingerprint(cfg *api.RenovateWebhookForgejoSync, endpoint, defaultTopic
 		authRef = cfg.AuthTokenSecretRef.Name + "/" + cfg.AuthTokenSecretRef.Key
 	}
 	events := strings.Join(cfg.Events, ",")
-	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s", endpoint, cfg.WebhookURL, topic, events, tokenRef, authRef, namespace+"/"+jobName)
+	tokens := sha256.Sum256([]byte(token + "|" + authToken))
+	return fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%x", endpoint, cfg.WebhookURL, topic, events, tokenRef, authRef, namespace+"/"+jobName, tokens[:8])
 }
 
 func (r *RenovateJobReconciler) loadWebhookSyncState(renovateJob *api.RenovateJob) map[string]int64 {
@@ -311,6 +380,126 @@ func (r *RenovateJobReconciler) saveWebhookSyncState(ctx context.Context, logger
 	}
 }
 
+/*
+rescheduleOnSecretChange schedules the failed projects of the renovatejob again once the contents of a referenced
+secret changed, if enabled. The fingerprint of the secrets is kept in the status, so changes made while the operator
+was not running are noticed as well. The first fingerprint is only stored.
+*/
+func (r *RenovateJobReconciler) rescheduleOnSecretChange(ctx context.Context, logger logr.Logger, renovateJob *api.RenovateJob) {
+	jobId := crdManager.RenovateJobIdentifier{
+		Name:      renovateJob.Name,
+		Namespace: renovateJob.Namespace,
+	}
+	if !renovateJob.Spec.RescheduleFailedOnSecretChange {
+		if renovateJob.Status.SecretsHash != "" {
+			if err := r.Manager.UpdateSecretsHash(ctx, jobId, ""); err != nil {
+				logger.Error(err, "failed to remove the fingerprint of the referenced secrets")
+			}
+		}
+		return
+	}
+
+	fp, err := crdManager.SecretsFingerprint(ctx, r.K8sClient, renovateJob)
+	if err != nil {
+		logger.Error(err, "failed to read the referenced secrets")
+		return
+	}
+	previous := renovateJob.Status.SecretsHash
+	if previous == fp {
+		return
+	}
+
+	if previous != "" {
+		isFailed := func(p api.ProjectStatus) bool {
+			return p.Status == api.JobStatusFailed
+		}
+		err = r.Manager.UpdateProjectStatusBatched(ctx, isFailed, jobId, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled})
+		if err != nil {
+			// the previous fingerprint is kept, the next reconcile tries again
+			logger.Error(err, "failed to reschedule failed projects after a secret change")
+			return
+		}
+		logger.Info("Referenced secrets changed, rescheduled failed projects")
+	}
+	if err := r.Manager.UpdateSecretsHash(ctx, jobId, fp); err != nil {
+		logger.Error(err, "failed to store the fingerprint of the referenced secrets")
+	}
+}
+
+// renovateJobsForSecret maps a secret to the renovatejobs referencing it
+func (r *RenovateJobReconciler) renovateJobsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	renovateJobs := &api.RenovateJobList{}
+	err := r.K8sClient.List(ctx, renovateJobs,
+		client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{crdManager.SECRET_REF_INDEX: secret.GetName()})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list RenovateJobs referencing secret", "secret", secret.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(renovateJobs.Items))
+	for _, renovateJob := range renovateJobs.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: k8stypes.NamespacedName{
+			Name:      renovateJob.Name,
+			Namespace: renovateJob.Namespace,
+		}})
+	}
+	return requests
+}
+
+func (r *RenovateJobReconciler) triggerExecutor(job crdManager.RenovateJobIdentifier) {
+	if r.Executor != nil {
+		r.Executor.Trigger(job)
+	}
+}
+
+// triggerExecutorForOwner triggers the executor for the renovatejob owning the object, without reconciling the renovatejob
+func (r *RenovateJobReconciler) triggerExecutorForOwner(ctx context.Context, obj client.Object) []reconcile.Request {
+	if owner := renovateJobOwner(obj); owner != nil {
+		r.triggerExecutor(*owner)
+	}
+	return nil
+}
+
+// triggerExecutorForRenovateJob triggers the executor for status changes of a renovatejob, e.g. a validated config
+func (r *RenovateJobReconciler) triggerExecutorForRenovateJob(ctx context.Context, obj client.Object) []reconcile.Request {
+	r.triggerExecutor(crdManager.RenovateJobIdentifier{
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	})
+	return nil
+}
+
+/*
+mapJob maps owned jobs of a renovatejob. Executor and discovery jobs only trigger the executor,
+validator jobs reconcile the renovatejob to record the validation result.
+*/
+func (r *RenovateJobReconciler) mapJob(ctx context.Context, obj client.Object) []reconcile.Request {
+	owner := renovateJobOwner(obj)
+	if owner == nil {
+		return nil
+	}
+	if obj.GetLabels()[crdManager.JOB_LABEL_TYPE] == string(crdManager.ValidatorJobType) {
+		return []reconcile.Request{{NamespacedName: k8stypes.NamespacedName{
+			Name:      owner.Name,
+			Namespace: owner.Namespace,
+		}}}
+	}
+	r.triggerExecutor(*owner)
+	return nil
+}
+
+// the renovatejob controlling the object, nil for objects not controlled by a renovatejob
+func renovateJobOwner(obj client.Object) *crdManager.RenovateJobIdentifier {
+	owner := metav1.GetControllerOf(obj)
+	if owner == nil || owner.Kind != "RenovateJob" || owner.APIVersion != api.GroupVersion.String() {
+		return nil
+	}
+	return &crdManager.RenovateJobIdentifier{
+		Name:      owner.Name,
+		Namespace: obj.GetNamespace(),
+	}
+}
+
 func (r *RenovateJobReconciler) readSecretKey(ctx context.Context, ref *api.RenovateSecretKeyReference, namespace string) (string, error) {
 	if ref == nil {
 		return "", fmt.Errorf("secret reference is nil")
@@ -332,8 +521,25 @@ func (r *RenovateJobReconciler) readSecretKey(ctx context.Context, ref *api.Reno
 
 func (r *RenovateJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.webhookSyncers = make(map[string]*webhookSyncerEntry)
+
+	// changed secrets are mapped to the renovatejobs referencing them through this index
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &api.RenovateJob{}, crdManager.SECRET_REF_INDEX, func(obj client.Object) []string {
+		return crdManager.ReferencedSecrets(obj.(*api.RenovateJob))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to index secret references: %w", err)
+	}
+
+	// status writes to renovatejobs, their projects and jobs happen thousands of times per cycle,
+	// they only trigger the executor instead of a full reconcile
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&api.RenovateJob{}).
-		Owns(&batchv1.Job{}).
+		For(&api.RenovateJob{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&api.RenovateJob{}, handler.EnqueueRequestsFromMapFunc(r.triggerExecutorForRenovateJob)).
+		Watches(&batchv1.Job{}, handler.EnqueueRequestsFromMapFunc(r.mapJob)).
+		Watches(&api.RenovateProject{}, handler.EnqueueRequestsFromMapFunc(r.triggerExecutorForOwner)).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.ConfigMap{}).
+		// only the metadata of secrets is cached, their contents are read from the api server
+		WatchesMetadata(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.renovateJobsForSecret)).
 		Complete(r)
 }
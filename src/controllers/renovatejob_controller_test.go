@@ -13,11 +13,17 @@ import (
 	"renovate-operator/internal/types"
 
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 // fakeManager implements the full RenovateJobManager interface but only the
@@ -26,6 +32,8 @@ type fakeManager struct {
 	getFn                        func(ctx context.Context, name, namespace string) (*api.RenovateJob, error)
 	reconcileProjectsFn          func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error
 	updateProjectStatusBatchedFn func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error
+	// hashes stored by UpdateSecretsHash
+	secretsHashes []string
 }
 
 func (f *fakeManager) ListRenovateJobs(ctx context.Context) ([]crdManager.RenovateJobIdentifier, error) {
@@ -55,6 +63,25 @@ func (f *fakeManager) UpdateProjectStatusBatched(ctx context.Context, fn func(p
 func (m *fakeManager) UpdateExecutionOptions(ctx context.Context, jobId crdManager.RenovateJobIdentifier, options *api.RenovateExecutionOptions) error {
 	return nil
 }
+func (m *fakeManager) UpdateConfigValidation(ctx context.Context, jobId crdManager.RenovateJobIdentifier, configHash string, condition *metav1.Condition) error {
+	return nil
+}
+func (m *fakeManager) UpdateSecretsHash(ctx context.Context, jobId crdManager.RenovateJobIdentifier, hash string) error {
+	m.secretsHashes = append(m.secretsHashes, hash)
+	return nil
+}
+func (m *fakeManager) SetProjectSuspended(ctx context.Context, project string, jobId crdManager.RenovateJobIdentifier, suspended bool) error {
+	return nil
+}
+func (m *fakeManager) ConfirmProjectRemoval(ctx context.Context, jobId crdManager.RenovateJobIdentifier) error {
+	return nil
+}
+func (m *fakeManager) AddProject(ctx context.Context, jobId crdManager.RenovateJobIdentifier, project string) (bool, error) {
+	return false, fmt.Errorf("not implemented")
+}
+func (m *fakeManager) RemoveProjects(ctx context.Context, jobId crdManager.RenovateJobIdentifier, projects []string) ([]string, error) {
+	return nil, fmt.Errorf("not implemented")
+}
 func (f *fakeManager) GetProjectsByStatus(ctx context.Context, job crdManager.RenovateJobIdentifier, status api.RenovateProjectStatus) ([]crdManager.RenovateProjectStatus, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -64,6 +91,9 @@ func (f *fakeManager) ReconcileProjects(ctx context.Context, job crdManager.Reno
 	}
 	return nil
 }
+func (f *fakeManager) MigrateLegacyProjectStatus(ctx context.Context, job crdManager.RenovateJobIdentifier) error {
+	return nil
+}
 func (f *fakeManager) GetLogsForProject(ctx context.Context, job crdManager.RenovateJobIdentifier, project string) (string, error) {
 	return "", fmt.Errorf("not implemented")
 }
@@ -100,13 +130,22 @@ func (f *fakeDiscovery) WaitForDiscoveryJob(ctx context.Context, job *api.Renova
 
 // fakeGitProviderClient implements gitprovider.GitProviderClient for tests.
 type fakeGitProviderClient struct {
-	isForkFn func(ctx context.Context, project string) (bool, error)
+	isForkFn   func(ctx context.Context, project string) (bool, error)
+	metadataFn func(ctx context.Context, project string) (*gitprovider.RepositoryMetadata, error)
 }
 
 func (f *fakeGitProviderClient) IsFork(ctx context.Context, project string) (bool, error) {
 	return f.isForkFn(ctx, project)
 }
 
+func (f *fakeGitProviderClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (f *fakeGitProviderClient) GetRepositoryMetadata(ctx context.Context, project string) (*gitprovider.RepositoryMetadata, error) {
+	return f.metadataFn(ctx, project)
+}
+
 type fakeScheduler struct {
 	addedExpr    string
 	addedName    string
@@ -205,6 +244,42 @@ func TestCreateScheduler_DiscoveryAndManagerInteraction(t *testing.T) {
 	}
 }
 
+// Test: the spread of the RenovateJob is passed on when scheduling the projects
+func TestCreateScheduler_PassesSpread(t *testing.T) {
+	var gotStatus *types.RenovateStatusUpdate
+	mgr := &fakeManager{}
+	mgr.reconcileProjectsFn = func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error {
+		return nil
+	}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		gotStatus = status
+		return nil
+	}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       api.RenovateJobSpec{Spread: &metav1.Duration{Duration: 2 * time.Hour}},
+		}, nil
+	}
+
+	sched := &fakeScheduler{}
+	reconciler := &RenovateJobReconciler{
+		Manager:   mgr,
+		Scheduler: sched,
+		Discovery: &fakeDiscovery{discoverFn: func(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+			return []string{"p1"}, nil
+		}},
+	}
+
+	renovateJob := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}, Spec: api.RenovateJobSpec{Schedule: "*/1 * * * *"}}
+	createScheduler(logr.Discard(), renovateJob, reconciler)
+	sched.storedFn()
+
+	if gotStatus == nil || gotStatus.Spread != 2*time.Hour {
+		t.Fatalf("expected projects to be scheduled with a spread of 2h, got %+v", gotStatus)
+	}
+}
+
 // Test: when Discovery returns an error, the scheduled function should abort and not call manager methods
 func TestCreateScheduler_DiscoveryErrorAborts(t *testing.T) {
 	calledReconcile := false
@@ -291,6 +366,39 @@ func TestCreateScheduler_ReconcileErrorAborts(t *testing.T) {
 	}
 }
 
+// Test: when ReconcileProjects blocks a mass removal, the existing projects should still be scheduled
+func TestCreateScheduler_ProjectRemovalBlockedSchedulesExistingProjects(t *testing.T) {
+	calledUpdate := false
+
+	mgr := &fakeManager{}
+	mgr.reconcileProjectsFn = func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error {
+		return &crdManager.ProjectRemovalBlockedError{Removed: 10, Existing: 11}
+	}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		calledUpdate = true
+		return nil
+	}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+	}
+
+	disc := &fakeDiscovery{}
+	disc.discoverFn = func(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+		return []string{"p1"}, nil
+	}
+
+	sched := &fakeScheduler{}
+	reconciler := &RenovateJobReconciler{Manager: mgr, Scheduler: sched, Discovery: disc}
+	renovateJob := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}, Spec: api.RenovateJobSpec{Schedule: "*/1 * * * *"}}
+
+	createScheduler(logr.Discard(), renovateJob, reconciler)
+	sched.storedFn()
+
+	if !calledUpdate {
+		t.Fatalf("expected UpdateProjectStatusBatched to be called when the removal is blocked")
+	}
+}
+
 // Test: when UpdateProjectStatusBatched returns an error, it should be invoked and handled
 func TestCreateScheduler_UpdateProjectStatusBatchedError(t *testing.T) {
 	calledReconcile := false
@@ -451,8 +559,163 @@ func TestReconcile_CreateSchedule(t *testing.T) {
 	if sched.addedName != expectedName {
 		t.Fatalf("expected schedule name %s, got %s", expectedName, sched.addedName)
 	}
-	if res.RequeueAfter != 1*time.Minute {
-		t.Fatalf("expected RequeueAfter 1m, got %v", res.RequeueAfter)
+	if res.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue, got %v", res.RequeueAfter)
+	}
+}
+
+// Test: a suspended RenovateJob must not be scheduled
+func TestReconcile_SuspendRemovesSchedule(t *testing.T) {
+	mgr := &fakeManager{}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       api.RenovateJobSpec{Schedule: "*/5 * * * *", Suspend: true},
+		}, nil
+	}
+
+	sched := &fakeScheduler{}
+
+	reconciler := &RenovateJobReconciler{
+		Manager:        mgr,
+		Scheduler:      sched,
+		Discovery:      &fakeDiscovery{},
+		webhookSyncers: make(map[string]*webhookSyncerEntry),
+	}
+
+	req := ctrl.Request{NamespacedName: k8stypes.NamespacedName{Name: "test", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sched.addCalled {
+		t.Fatalf("expected no schedule to be added for a suspended RenovateJob")
+	}
+	if len(sched.removedNames) != 1 || sched.removedNames[0] != "test-default" {
+		t.Fatalf("expected schedule test-default to be removed, got %v", sched.removedNames)
+	}
+}
+
+type fakeExecutor struct {
+	triggered []crdManager.RenovateJobIdentifier
+}
+
+func (f *fakeExecutor) Start(ctx context.Context) error { return nil }
+func (f *fakeExecutor) Trigger(job crdManager.RenovateJobIdentifier) {
+	f.triggered = append(f.triggered, job)
+}
+
+// Test: every reconcile of an existing RenovateJob should trigger the executor
+func TestReconcile_TriggersExecutor(t *testing.T) {
+	mgr := &fakeManager{}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       api.RenovateJobSpec{Schedule: "*/5 * * * *"},
+		}, nil
+	}
+	executor := &fakeExecutor{}
+
+	reconciler := &RenovateJobReconciler{
+		Manager:        mgr,
+		Scheduler:      &fakeScheduler{},
+		Discovery:      &fakeDiscovery{},
+		Executor:       executor,
+		webhookSyncers: make(map[string]*webhookSyncerEntry),
+	}
+
+	req := ctrl.Request{NamespacedName: k8stypes.NamespacedName{Name: "test", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(executor.triggered) != 1 || executor.triggered[0].Name != "test" || executor.triggered[0].Namespace != "default" {
+		t.Fatalf("expected executor to be triggered for test/default, got %v", executor.triggered)
+	}
+}
+
+type fakeConfigValidator struct {
+	err error
+}
+
+func (f *fakeConfigValidator) Validate(ctx context.Context, job *api.RenovateJob) (time.Duration, error) {
+	return 0, f.err
+}
+
+// Test: failed steps are returned so the RenovateJob is requeued, the remaining steps still run
+func TestReconcile_ReturnsStepErrors(t *testing.T) {
+	mgr := &fakeManager{}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       api.RenovateJobSpec{Schedule: "*/5 * * * *"},
+		}, nil
+	}
+	sched := &fakeScheduler{}
+	executor := &fakeExecutor{}
+
+	reconciler := &RenovateJobReconciler{
+		Manager:         mgr,
+		Scheduler:       sched,
+		Discovery:       &fakeDiscovery{},
+		Executor:        executor,
+		ConfigValidator: &fakeConfigValidator{err: fmt.Errorf("api server unavailable")},
+		webhookSyncers:  make(map[string]*webhookSyncerEntry),
+	}
+
+	req := ctrl.Request{NamespacedName: k8stypes.NamespacedName{Name: "test", Namespace: "default"}}
+	if _, err := reconciler.Reconcile(context.Background(), req); err == nil {
+		t.Fatal("expected the validation error to be returned")
+	}
+	if !sched.addCalled || len(executor.triggered) != 1 {
+		t.Fatalf("expected the schedule and executor trigger despite the error, got %v and %v", sched.addCalled, executor.triggered)
+	}
+}
+
+// Test: executor jobs and projects trigger the executor of their renovatejob, validator jobs reconcile it
+func TestMapOwnedObjects(t *testing.T) {
+	executor := &fakeExecutor{}
+	reconciler := &RenovateJobReconciler{Executor: executor}
+	owner := []metav1.OwnerReference{{
+		APIVersion: api.GroupVersion.String(),
+		Kind:       "RenovateJob",
+		Name:       "test",
+		Controller: ptr.To(true),
+	}}
+	ctx := context.Background()
+
+	executorJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:            "test-executor",
+		Namespace:       "default",
+		OwnerReferences: owner,
+		Labels:          map[string]string{crdManager.JOB_LABEL_TYPE: string(crdManager.ExecutorJobType)},
+	}}
+	if requests := reconciler.mapJob(ctx, executorJob); len(requests) != 0 {
+		t.Fatalf("expected executor jobs not to reconcile the renovatejob, got %v", requests)
+	}
+	project := &api.RenovateProject{ObjectMeta: metav1.ObjectMeta{Name: "test-project", Namespace: "default", OwnerReferences: owner}}
+	if requests := reconciler.triggerExecutorForOwner(ctx, project); len(requests) != 0 {
+		t.Fatalf("expected projects not to reconcile the renovatejob, got %v", requests)
+	}
+	if len(executor.triggered) != 2 || executor.triggered[0].Fullname() != "test-default" || executor.triggered[1].Fullname() != "test-default" {
+		t.Fatalf("expected executor to be triggered twice for test/default, got %v", executor.triggered)
+	}
+
+	validatorJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:            "test-validator",
+		Namespace:       "default",
+		OwnerReferences: owner,
+		Labels:          map[string]string{crdManager.JOB_LABEL_TYPE: string(crdManager.ValidatorJobType)},
+	}}
+	requests := reconciler.mapJob(ctx, validatorJob)
+	if len(requests) != 1 || requests[0].Name != "test" || requests[0].Namespace != "default" {
+		t.Fatalf("expected validator jobs to reconcile test/default, got %v", requests)
+	}
+
+	// jobs not controlled by a renovatejob are ignored
+	if requests := reconciler.mapJob(ctx, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}); len(requests) != 0 {
+		t.Fatalf("expected unowned jobs to be ignored, got %v", requests)
+	}
+	if len(executor.triggered) != 2 {
+		t.Fatalf("expected no further executor triggers, got %v", executor.triggered)
 	}
 }
 
@@ -484,8 +747,8 @@ func TestReconcile_RemoveScheduleOnNotFound(t *testing.T) {
 	if len(sched.removedNames) != 1 || sched.removedNames[0] != expectedName {
 		t.Fatalf("expected removed names [%s], got %v", expectedName, sched.removedNames)
 	}
-	if res.RequeueAfter != 1*time.Minute {
-		t.Fatalf("expected RequeueAfter 1m, got %v", res.RequeueAfter)
+	if res.RequeueAfter != 0 {
+		t.Fatalf("expected no requeue, got %v", res.RequeueAfter)
 	}
 }
 
@@ -681,3 +944,326 @@ func TestCreateScheduler_SkipForksFilterError(t *testing.T) {
 		t.Fatal("expected ReconcileProjects NOT to be called when fork filter errors")
 	}
 }
+
+// Test: projectFilters are applied to the discovered projects before reconciling
+func TestCreateScheduler_AppliesProjectFilters(t *testing.T) {
+	var gotProjects []string
+
+	mgr := &fakeManager{}
+	mgr.reconcileProjectsFn = func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error {
+		gotProjects = projects
+		return nil
+	}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		return nil
+	}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: api.RenovateJobSpec{
+				Schedule: "*/1 * * * *",
+				ProjectFilters: []api.RenovateProjectFilter{
+					{Action: api.ProjectFilterInclude, Glob: "platform/*"},
+					{Action: api.ProjectFilterExclude, Glob: "platform/legacy-*"},
+				},
+			},
+		}, nil
+	}
+
+	disc := &fakeDiscovery{}
+	disc.discoverFn = func(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+		return []string{"platform/api", "platform/legacy-billing", "apps/shop"}, nil
+	}
+
+	sched := &fakeScheduler{}
+	reconciler := &RenovateJobReconciler{
+		Manager:   mgr,
+		Scheduler: sched,
+		Discovery: disc,
+	}
+
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{Schedule: "*/1 * * * *"},
+	}
+
+	createScheduler(logr.Discard(), renovateJob, reconciler)
+	sched.storedFn()
+
+	if len(gotProjects) != 1 || gotProjects[0] != "platform/api" {
+		t.Fatalf("expected only platform/api, got %v", gotProjects)
+	}
+}
+
+// Test: skipArchived filters archived repositories without skipForks
+func TestCreateScheduler_SkipArchivedFilters(t *testing.T) {
+	var gotProjects []string
+
+	mgr := &fakeManager{}
+	mgr.reconcileProjectsFn = func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error {
+		gotProjects = projects
+		return nil
+	}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		return nil
+	}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       api.RenovateJobSpec{Schedule: "*/1 * * * *", SkipArchived: true},
+		}, nil
+	}
+
+	disc := &fakeDiscovery{}
+	disc.discoverFn = func(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+		return []string{"org/repo1", "org/repo2-archived"}, nil
+	}
+
+	factory := gitprovider.ClientFactory(func(ctx context.Context, job *api.RenovateJob) (gitprovider.GitProviderClient, error) {
+		return &fakeGitProviderClient{
+			isForkFn: func(ctx context.Context, project string) (bool, error) {
+				t.Fatal("IsFork should not be called when skipForks is false")
+				return false, nil
+			},
+			metadataFn: func(ctx context.Context, project string) (*gitprovider.RepositoryMetadata, error) {
+				return &gitprovider.RepositoryMetadata{Archived: project == "org/repo2-archived"}, nil
+			},
+		}, nil
+	})
+
+	sched := &fakeScheduler{}
+	reconciler := &RenovateJobReconciler{
+		Manager:                  mgr,
+		Scheduler:                sched,
+		Discovery:                disc,
+		GitProviderClientFactory: factory,
+	}
+
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{Schedule: "*/1 * * * *", SkipArchived: true},
+	}
+
+	createScheduler(logr.Discard(), renovateJob, reconciler)
+	sched.storedFn()
+
+	if len(gotProjects) != 1 || gotProjects[0] != "org/repo1" {
+		t.Fatalf("expected only org/repo1, got %v", gotProjects)
+	}
+}
+
+// Test: static projects are reconciled without discovery
+func TestCreateScheduler_StaticProjectsSkipDiscovery(t *testing.T) {
+	var gotProjects []string
+
+	mgr := &fakeManager{}
+	mgr.reconcileProjectsFn = func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error {
+		gotProjects = projects
+		return nil
+	}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		return nil
+	}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       api.RenovateJobSpec{Schedule: "*/1 * * * *", Projects: []string{"org/static"}},
+		}, nil
+	}
+
+	disc := &fakeDiscovery{}
+	disc.discoverFn = func(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+		t.Fatal("Discover should not be called for static projects")
+		return nil, nil
+	}
+
+	sched := &fakeScheduler{}
+	reconciler := &RenovateJobReconciler{Manager: mgr, Scheduler: sched, Discovery: disc}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{Schedule: "*/1 * * * *"},
+	}
+
+	createScheduler(logr.Discard(), renovateJob, reconciler)
+	sched.storedFn()
+
+	if len(gotProjects) != 1 || gotProjects[0] != "org/static" {
+		t.Fatalf("expected only org/static, got %v", gotProjects)
+	}
+}
+
+// Test: static projects are merged with the discovered projects if autodiscover is enabled
+func TestCreateScheduler_StaticProjectsMergedWithDiscovery(t *testing.T) {
+	var gotProjects []string
+	autodiscover := true
+
+	mgr := &fakeManager{}
+	mgr.reconcileProjectsFn = func(ctx context.Context, job crdManager.RenovateJobIdentifier, projects []string) error {
+		gotProjects = projects
+		return nil
+	}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		return nil
+	}
+	mgr.getFn = func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+		return &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: api.RenovateJobSpec{
+				Schedule:     "*/1 * * * *",
+				Projects:     []string{"other/static"},
+				Autodiscover: &autodiscover,
+				ProjectFilters: []api.RenovateProjectFilter{
+					{Action: api.ProjectFilterInclude, Prefix: "org"},
+				},
+			},
+		}, nil
+	}
+
+	disc := &fakeDiscovery{}
+	disc.discoverFn = func(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+		return []string{"org/repo1", "other/repo2"}, nil
+	}
+
+	sched := &fakeScheduler{}
+	reconciler := &RenovateJobReconciler{Manager: mgr, Scheduler: sched, Discovery: disc}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{Schedule: "*/1 * * * *"},
+	}
+
+	createScheduler(logr.Discard(), renovateJob, reconciler)
+	sched.storedFn()
+
+	// the project filters only apply to discovered projects
+	if len(gotProjects) != 2 || gotProjects[0] != "org/repo1" || gotProjects[1] != "other/static" {
+		t.Fatalf("expected org/repo1 and other/static, got %v", gotProjects)
+	}
+}
+
+// helper to create a fake client which knows the core types and indexes renovatejobs like the manager
+func makeClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	if err := api.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add api scheme: %v", err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core scheme: %v", err)
+	}
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithIndex(&api.RenovateJob{}, crdManager.SECRET_REF_INDEX, func(obj client.Object) []string {
+			return crdManager.ReferencedSecrets(obj.(*api.RenovateJob))
+		}).
+		Build()
+}
+
+// Test: a changed secret is mapped to the renovatejobs referencing it in the same namespace
+func TestRenovateJobsForSecret(t *testing.T) {
+	referencing := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{SecretRef: "renovate-secret"},
+	}
+	syncing := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "syncing", Namespace: "default"},
+		Spec: api.RenovateJobSpec{Webhook: &api.RenovateWebhook{Forgejo: &api.RenovateWebhookForgejo{Sync: &api.RenovateWebhookForgejoSync{
+			TokenSecretRef: &api.RenovateSecretKeyReference{Name: "renovate-secret", Key: "token"},
+		}}}},
+	}
+	other := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{SecretRef: "other-secret"},
+	}
+	otherNamespace := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "referencing", Namespace: "other"},
+		Spec:       api.RenovateJobSpec{SecretRef: "renovate-secret"},
+	}
+	reconciler := &RenovateJobReconciler{K8sClient: makeClient(t, referencing, syncing, other, otherNamespace)}
+
+	// secrets are only watched by their metadata
+	secret := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: "renovate-secret", Namespace: "default"}}
+	requests := reconciler.renovateJobsForSecret(context.Background(), secret)
+	names := map[string]bool{}
+	for _, request := range requests {
+		names[request.Namespace+"/"+request.Name] = true
+	}
+	if len(requests) != 2 || !names["default/referencing"] || !names["default/syncing"] {
+		t.Fatalf("expected the two referencing renovatejobs in default, got %v", requests)
+	}
+}
+
+// Test: failed projects are rescheduled once the fingerprint of the referenced secrets differs from the status
+func TestRescheduleOnSecretChange(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate-secret", Namespace: "default"},
+		Data:       map[string][]byte{"RENOVATE_TOKEN": []byte("expired")},
+	}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       api.RenovateJobSpec{SecretRef: "renovate-secret", RescheduleFailedOnSecretChange: true},
+	}
+	k8sClient := makeClient(t, secret, renovateJob)
+
+	var rescheduled []string
+	mgr := &fakeManager{}
+	mgr.updateProjectStatusBatchedFn = func(ctx context.Context, fn func(p api.ProjectStatus) bool, job crdManager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+		for _, project := range []api.ProjectStatus{{Name: "failed", Status: api.JobStatusFailed}, {Name: "completed", Status: api.JobStatusCompleted}} {
+			if fn(project) && status.Status == api.JobStatusScheduled {
+				rescheduled = append(rescheduled, project.Name)
+			}
+		}
+		return nil
+	}
+	reconciler := &RenovateJobReconciler{Manager: mgr, K8sClient: k8sClient}
+	ctx := context.Background()
+
+	// the first fingerprint is only stored
+	reconciler.rescheduleOnSecretChange(ctx, logr.Discard(), renovateJob)
+	if len(rescheduled) != 0 || len(mgr.secretsHashes) != 1 || mgr.secretsHashes[0] == "" {
+		t.Fatalf("expected only the fingerprint to be stored, got %v and %v", rescheduled, mgr.secretsHashes)
+	}
+	renovateJob.Status.SecretsHash = mgr.secretsHashes[0]
+	reconciler.rescheduleOnSecretChange(ctx, logr.Discard(), renovateJob)
+	if len(rescheduled) != 0 || len(mgr.secretsHashes) != 1 {
+		t.Fatalf("expected nothing to happen without a change, got %v and %v", rescheduled, mgr.secretsHashes)
+	}
+
+	// changes are detected against the status, e.g. made while the operator was not running
+	secret.Data["RENOVATE_TOKEN"] = []byte("rotated")
+	if err := k8sClient.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	reconciler.rescheduleOnSecretChange(ctx, logr.Discard(), renovateJob)
+	if len(rescheduled) != 1 || rescheduled[0] != "failed" {
+		t.Fatalf("expected the failed project to be rescheduled, got %v", rescheduled)
+	}
+	if len(mgr.secretsHashes) != 2 || mgr.secretsHashes[1] == renovateJob.Status.SecretsHash {
+		t.Fatalf("expected the new fingerprint to be stored, got %v", mgr.secretsHashes)
+	}
+
+	// without the option, the fingerprint is removed from the status
+	renovateJob.Spec.RescheduleFailedOnSecretChange = false
+	reconciler.rescheduleOnSecretChange(ctx, logr.Discard(), renovateJob)
+	if len(mgr.secretsHashes) != 3 || mgr.secretsHashes[2] != "" {
+		t.Fatalf("expected the fingerprint to be removed once the option is disabled, got %v", mgr.secretsHashes)
+	}
+}
+
+// Test: the webhook sync fingerprint changes with the token contents
+func TestSyncFingerprint_Tokens(t *testing.T) {
+	cfg := &api.RenovateWebhookForgejoSync{
+		Enabled:        true,
+		WebhookURL:     "https://renovate.example.com/webhook",
+		TokenSecretRef: &api.RenovateSecretKeyReference{Name: "forgejo", Key: "token"},
+	}
+	fp := syncFingerprint(cfg, "https://forgejo.example.com", "renovate", "default", "test", "token", "")
+	if again := syncFingerprint(cfg, "https://forgejo.example.com", "renovate", "default", "test", "token", ""); again != fp {
+		t.Fatalf("expected a stable fingerprint, got %s and %s", fp, again)
+	}
+	if rotated := syncFingerprint(cfg, "https://forgejo.example.com", "renovate", "default", "test", "rotated", ""); rotated == fp {
+		t.Fatal("expected the fingerprint to change with the token")
+	}
+	if withAuth := syncFingerprint(cfg, "https://forgejo.example.com", "renovate", "default", "test", "token", "auth"); withAuth == fp {
+		t.Fatal("expected the fingerprint to change with the auth token")
+	}
+}
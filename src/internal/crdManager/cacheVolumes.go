@@ -0,0 +1,130 @@
+package crdmanager
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	CACHE_LABEL_JOB   = "renovate-operator.mogenius.com/cache-of"
+	CACHE_LABEL_SHARD = "renovate-operator.mogenius.com/cache-shard"
+)
+
+/*
+EnsureCacheVolumes creates the persistent volume claims holding the cache of the renovatejob and removes
+the ones no longer configured. The claims are owned by the renovatejob and deleted together with it.
+Claims whose access mode or storage class changed are deleted and created again once they are no longer in use.
+*/
+func EnsureCacheVolumes(ctx context.Context, client crclient.Client, renovateJob *api.RenovateJob) error {
+	claims := &corev1.PersistentVolumeClaimList{}
+	err := client.List(ctx, claims, crclient.InNamespace(renovateJob.Namespace), crclient.MatchingLabels{
+		CACHE_LABEL_JOB: renovateJob.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list cache volumes: %w", err)
+	}
+
+	existing := make(map[string]*corev1.PersistentVolumeClaim, len(claims.Items))
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		if !metav1.IsControlledBy(claim, renovateJob) {
+			continue
+		}
+		existing[claim.Name] = claim
+	}
+
+	for shard := range utils.GetCacheShards(&renovateJob.Spec) {
+		desired, err := newCacheVolumeClaim(client, renovateJob, shard)
+		if err != nil {
+			return err
+		}
+		claim, ok := existing[desired.Name]
+		delete(existing, desired.Name)
+
+		if !ok {
+			if err := client.Create(ctx, desired); err != nil && !errors.IsAlreadyExists(err) {
+				return fmt.Errorf("failed to create cache volume %s: %w", desired.Name, err)
+			}
+			continue
+		}
+		if claim.DeletionTimestamp != nil {
+			// recreated once the previous claim is released
+			continue
+		}
+		if !isCacheVolumeCompatible(claim, desired) {
+			if err := client.Delete(ctx, claim); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete cache volume %s: %w", claim.Name, err)
+			}
+			continue
+		}
+
+		// volumes can only be expanded, a smaller size is ignored
+		size := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+		if current := claim.Spec.Resources.Requests[corev1.ResourceStorage]; current.Cmp(size) < 0 {
+			claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			if err := client.Update(ctx, claim); err != nil {
+				return fmt.Errorf("failed to resize cache volume %s: %w", claim.Name, err)
+			}
+		}
+	}
+
+	// shards that are no longer configured
+	for _, claim := range existing {
+		if claim.DeletionTimestamp != nil {
+			continue
+		}
+		if err := client.Delete(ctx, claim); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete cache volume %s: %w", claim.Name, err)
+		}
+	}
+	return nil
+}
+
+func newCacheVolumeClaim(client crclient.Client, renovateJob *api.RenovateJob, shard int) (*corev1.PersistentVolumeClaim, error) {
+	cache := renovateJob.Spec.Cache
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      utils.CacheVolumeName(renovateJob, shard),
+			Namespace: renovateJob.Namespace,
+			Labels: map[string]string{
+				CACHE_LABEL_JOB:   renovateJob.Name,
+				CACHE_LABEL_SHARD: strconv.Itoa(shard),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{utils.GetCacheAccessMode(cache)},
+			StorageClassName: cache.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: cache.Size,
+				},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(renovateJob, claim, client.Scheme()); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+	return claim, nil
+}
+
+// whether the immutable settings of an existing claim match the cache configuration
+func isCacheVolumeCompatible(claim *corev1.PersistentVolumeClaim, desired *corev1.PersistentVolumeClaim) bool {
+	if len(claim.Spec.AccessModes) != 1 || claim.Spec.AccessModes[0] != desired.Spec.AccessModes[0] {
+		return false
+	}
+	// without a storage class, the default storage class is set by kubernetes
+	if desired.Spec.StorageClassName != nil && (claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != *desired.Spec.StorageClassName) {
+		return false
+	}
+	return true
+}
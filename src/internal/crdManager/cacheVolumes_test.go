@@ -0,0 +1,116 @@
+package crdmanager
+
+import (
+	"context"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func listCacheVolumes(t *testing.T, client crclient.Client) map[string]corev1.PersistentVolumeClaim {
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := client.List(context.Background(), claims, crclient.InNamespace("default")); err != nil {
+		t.Fatalf("failed to list claims: %v", err)
+	}
+	result := map[string]corev1.PersistentVolumeClaim{}
+	for _, claim := range claims.Items {
+		result[claim.Name] = claim
+	}
+	return result
+}
+
+func TestEnsureCacheVolumes(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "default", UID: "uid-1"},
+		Spec: api.RenovateJobSpec{
+			Cache: &api.RenovateCache{
+				Size:             resource.MustParse("1Gi"),
+				StorageClassName: ptr.To("fast"),
+				Shards:           2,
+			},
+		},
+	}
+	client := makeClient(t, job)
+	ctx := context.Background()
+
+	if err := EnsureCacheVolumes(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims := listCacheVolumes(t, client)
+	if len(claims) != 2 {
+		t.Fatalf("expected 2 cache volumes, got %d", len(claims))
+	}
+	claim, ok := claims[utils.CacheVolumeName(job, 1)]
+	if !ok {
+		t.Fatalf("expected cache volume of shard 1, got %v", claims)
+	}
+	if !metav1.IsControlledBy(&claim, job) {
+		t.Error("expected the cache volume to be owned by the renovatejob")
+	}
+	if claim.Spec.AccessModes[0] != corev1.ReadWriteOnce || *claim.Spec.StorageClassName != "fast" {
+		t.Errorf("unexpected claim spec: %+v", claim.Spec)
+	}
+
+	// larger volumes are expanded, removed shards deleted
+	job.Spec.Cache.Size = resource.MustParse("2Gi")
+	job.Spec.Cache.Shards = 1
+	if err := EnsureCacheVolumes(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claims = listCacheVolumes(t, client)
+	if len(claims) != 1 {
+		t.Fatalf("expected 1 cache volume, got %d", len(claims))
+	}
+	size := claims[utils.CacheVolumeName(job, 0)].Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.String() != "2Gi" {
+		t.Errorf("expected the cache volume to be expanded to 2Gi, got %s", size.String())
+	}
+
+	// a changed access mode requires a new volume
+	job.Spec.Cache.AccessMode = corev1.ReadWriteMany
+	if err := EnsureCacheVolumes(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := EnsureCacheVolumes(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claim = listCacheVolumes(t, client)[utils.CacheVolumeName(job, 0)]
+	if claim.Spec.AccessModes[0] != corev1.ReadWriteMany {
+		t.Errorf("expected the cache volume to be recreated with ReadWriteMany, got %v", claim.Spec.AccessModes)
+	}
+
+	// disabling the cache removes all volumes
+	job.Spec.Cache = nil
+	if err := EnsureCacheVolumes(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims := listCacheVolumes(t, client); len(claims) != 0 {
+		t.Errorf("expected no cache volumes, got %d", len(claims))
+	}
+}
+
+func TestEnsureCacheVolumes_IgnoresForeignClaims(t *testing.T) {
+	job := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "default", UID: "uid-1"}}
+	foreign := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "manual",
+			Namespace: "default",
+			Labels:    map[string]string{CACHE_LABEL_JOB: "renovate"},
+		},
+	}
+	client := makeClient(t, job, foreign)
+
+	if err := EnsureCacheVolumes(context.Background(), client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := listCacheVolumes(t, client)["manual"]; !ok {
+		t.Error("expected claims not owned by the renovatejob to be kept")
+	}
+}
@@ -0,0 +1,187 @@
+package crdmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"maps"
+	"slices"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// annotation of the validator job holding the fingerprint of the config it validates
+const JOB_ANNOTATION_CONFIG_FINGERPRINT = "renovate-operator.mogenius.com/config-fingerprint"
+
+// reasons of the ConfigValid condition
+const (
+	ConfigValidationReasonValidating  = "Validating"
+	ConfigValidationReasonValid       = "Valid"
+	ConfigValidationReasonInvalid     = "Invalid"
+	ConfigValidationReasonUnavailable = "ConfigUnavailable"
+	ConfigValidationReasonFailed      = "ValidationFailed"
+)
+
+// IsConfigValidationBlocking returns true if scheduled projects of the renovatejob must not start,
+// because its config is validated in block mode and did not validate yet.
+func IsConfigValidationBlocking(renovateJob *api.RenovateJob) bool {
+	if renovateJob.Spec.ConfigValidation == nil || renovateJob.Spec.ConfigValidation.Mode != api.ConfigValidationModeBlock {
+		return false
+	}
+	return !meta.IsStatusConditionTrue(renovateJob.Status.Conditions, api.ConditionConfigValid)
+}
+
+/*
+ConfigFingerprint hashes everything renovate reads its config from: the image, the inline config, extraEnv
+and the contents of the referenced secrets and config maps, including the ones mounted by extraVolumes.
+Missing optional references are skipped, other missing references return an error.
+*/
+func ConfigFingerprint(ctx context.Context, client crclient.Client, renovateJob *api.RenovateJob) (string, error) {
+	h := sha256.New()
+	spec := &renovateJob.Spec
+	writeField(h, "image", []byte(spec.Image))
+	writeField(h, "config", []byte(utils.RenovateConfigHash(spec)))
+	env, err := json.Marshal(spec.ExtraEnv)
+	if err != nil {
+		return "", err
+	}
+	writeField(h, "env", env)
+
+	if spec.SecretRef != "" {
+		if err := writeSecret(ctx, client, h, renovateJob.Namespace, spec.SecretRef, false); err != nil {
+			return "", err
+		}
+	}
+	for _, source := range spec.ExtraEnvFrom {
+		if source.SecretRef != nil {
+			err = writeSecret(ctx, client, h, renovateJob.Namespace, source.SecretRef.Name, isOptional(source.SecretRef.Optional))
+		} else if source.ConfigMapRef != nil {
+			err = writeConfigMap(ctx, client, h, renovateJob.Namespace, source.ConfigMapRef.Name, isOptional(source.ConfigMapRef.Optional))
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, env := range spec.ExtraEnv {
+		if env.ValueFrom == nil {
+			continue
+		}
+		if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+			err = writeSecret(ctx, client, h, renovateJob.Namespace, ref.Name, isOptional(ref.Optional))
+		} else if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+			err = writeConfigMap(ctx, client, h, renovateJob.Namespace, ref.Name, isOptional(ref.Optional))
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, volume := range spec.ExtraVolumes {
+		if volume.Secret != nil {
+			err = writeSecret(ctx, client, h, renovateJob.Namespace, volume.Secret.SecretName, isOptional(volume.Secret.Optional))
+		} else if volume.ConfigMap != nil {
+			err = writeConfigMap(ctx, client, h, renovateJob.Namespace, volume.ConfigMap.Name, isOptional(volume.ConfigMap.Optional))
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:8]), nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// write a length prefixed field, so adjacent values cannot be confused
+func writeField(h hash.Hash, name string, value []byte) {
+	_, _ = fmt.Fprintf(h, "%s:%d:", name, len(value))
+	_, _ = h.Write(value)
+}
+
+func writeSecret(ctx context.Context, client crclient.Client, h hash.Hash, namespace string, name string, optional bool) error {
+	secret := &corev1.Secret{}
+	err := client.Get(ctx, crclient.ObjectKey{Name: name, Namespace: namespace}, secret)
+	if errors.IsNotFound(err) && optional {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading secret %s: %w", name, err)
+	}
+	writeField(h, "secret", []byte(name))
+	for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+		writeField(h, key, secret.Data[key])
+	}
+	return nil
+}
+
+func writeConfigMap(ctx context.Context, client crclient.Client, h hash.Hash, namespace string, name string, optional bool) error {
+	configMap := &corev1.ConfigMap{}
+	err := client.Get(ctx, crclient.ObjectKey{Name: name, Namespace: namespace}, configMap)
+	if errors.IsNotFound(err) && optional {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading config map %s: %w", name, err)
+	}
+	writeField(h, "configmap", []byte(name))
+	for _, key := range slices.Sorted(maps.Keys(configMap.Data)) {
+		writeField(h, key, []byte(configMap.Data[key]))
+	}
+	for _, key := range slices.Sorted(maps.Keys(configMap.BinaryData)) {
+		writeField(h, key, configMap.BinaryData[key])
+	}
+	return nil
+}
+
+// store the result of a config validation in the status of a renovatejob.
+// a nil condition removes the ConfigValid condition, e.g. once the validation is disabled.
+func (r *renovateJobManager) UpdateConfigValidation(ctx context.Context, job RenovateJobIdentifier, configHash string, condition *v1.Condition) error {
+	defer r.renovateJobLock(job)()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
+		if err != nil {
+			return err
+		}
+
+		previous := meta.FindStatusCondition(renovateJob.Status.Conditions, api.ConditionConfigValid)
+		if condition == nil {
+			if previous == nil && renovateJob.Status.ValidatedConfigHash == "" {
+				return nil
+			}
+			meta.RemoveStatusCondition(&renovateJob.Status.Conditions, api.ConditionConfigValid)
+			renovateJob.Status.ValidatedConfigHash = ""
+			_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
+			return err
+		}
+
+		if previous != nil && renovateJob.Status.ValidatedConfigHash == configHash && previous.Status == condition.Status &&
+			previous.Reason == condition.Reason && previous.Message == condition.Message && previous.ObservedGeneration == renovateJob.Generation {
+			return nil
+		}
+		changed := condition.DeepCopy()
+		changed.ObservedGeneration = renovateJob.Generation
+		meta.SetStatusCondition(&renovateJob.Status.Conditions, *changed)
+		renovateJob.Status.ValidatedConfigHash = configHash
+		if _, err = updateRenovateJobStatus(ctx, renovateJob, r.client); err != nil {
+			return err
+		}
+
+		if condition.Status == v1.ConditionFalse && (previous == nil || previous.Status != v1.ConditionFalse || previous.Message != condition.Message) {
+			r.recorder.Eventf(renovateJob, nil, corev1.EventTypeWarning, "ConfigInvalid", "ValidateConfig", "%s", condition.Message)
+		} else if condition.Status == v1.ConditionTrue && previous != nil && previous.Status == v1.ConditionFalse {
+			r.recorder.Eventf(renovateJob, nil, corev1.EventTypeNormal, "ConfigValid", "ValidateConfig", "The renovate config is valid again")
+		}
+		return nil
+	})
+}
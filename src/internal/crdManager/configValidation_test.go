@@ -0,0 +1,144 @@
+package crdmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+)
+
+func TestConfigFingerprint(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "default"},
+		Spec: api.RenovateJobSpec{
+			Image:     "renovate/renovate:41",
+			SecretRef: "renovate-secret",
+			ExtraEnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "renovate-env"}}},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}, Optional: ptr.To(true)}},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate-secret", Namespace: "default"},
+		Data:       map[string][]byte{"RENOVATE_TOKEN": []byte("token")},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate-env", Namespace: "default"},
+		Data:       map[string]string{"RENOVATE_ONBOARDING": "false"},
+	}
+	client := makeClient(t, secret, configMap)
+	ctx := context.Background()
+
+	fingerprint, err := ConfigFingerprint(ctx, client, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again, _ := ConfigFingerprint(ctx, client, job); again != fingerprint {
+		t.Errorf("expected a stable fingerprint, got %s and %s", fingerprint, again)
+	}
+
+	// changed secret contents change the fingerprint
+	secret.Data["RENOVATE_TOKEN"] = []byte("rotated")
+	if err := client.Update(ctx, secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rotated, err := ConfigFingerprint(ctx, client, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated == fingerprint {
+		t.Error("expected the fingerprint to change with the secret contents")
+	}
+
+	// the image is part of the fingerprint
+	job.Spec.Image = "renovate/renovate:42"
+	if updated, _ := ConfigFingerprint(ctx, client, job); updated == rotated {
+		t.Error("expected the fingerprint to change with the image")
+	}
+
+	// missing references that are not optional cannot be read
+	job.Spec.SecretRef = "other-secret"
+	if _, err := ConfigFingerprint(ctx, client, job); err == nil || !strings.Contains(err.Error(), "other-secret") {
+		t.Errorf("expected an error for the missing secret, got %v", err)
+	}
+}
+
+func TestIsConfigValidationBlocking(t *testing.T) {
+	job := &api.RenovateJob{}
+	if IsConfigValidationBlocking(job) {
+		t.Error("expected no blocking without config validation")
+	}
+
+	job.Spec.ConfigValidation = &api.RenovateConfigValidation{Mode: api.ConfigValidationModeWarn}
+	if IsConfigValidationBlocking(job) {
+		t.Error("expected no blocking in warn mode")
+	}
+
+	job.Spec.ConfigValidation.Mode = api.ConfigValidationModeBlock
+	if !IsConfigValidationBlocking(job) {
+		t.Error("expected blocking until the config validated")
+	}
+	meta.SetStatusCondition(&job.Status.Conditions, metav1.Condition{Type: api.ConditionConfigValid, Status: metav1.ConditionTrue, Reason: ConfigValidationReasonValid})
+	if IsConfigValidationBlocking(job) {
+		t.Error("expected no blocking for a valid config")
+	}
+}
+
+func TestUpdateConfigValidation(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j)
+	recorder := events.NewFakeRecorder(10)
+	mgr := NewRenovateJobManager(cl, cl, recorder)
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	invalid := &metav1.Condition{
+		Type:    api.ConditionConfigValid,
+		Status:  metav1.ConditionFalse,
+		Reason:  ConfigValidationReasonInvalid,
+		Message: "The renovate config has 1 errors: Invalid configuration option: prHourlyLimt",
+	}
+	if err := mgr.UpdateConfigValidation(ctx, jobId, "abc", invalid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the same result is not recorded twice
+	if err := mgr.UpdateConfigValidation(ctx, jobId, "abc", invalid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	condition := meta.FindStatusCondition(job.Status.Conditions, api.ConditionConfigValid)
+	if condition == nil || condition.Status != metav1.ConditionFalse || job.Status.ValidatedConfigHash != "abc" {
+		t.Fatalf("expected the invalid config to be recorded, got %v with hash %q", job.Status.Conditions, job.Status.ValidatedConfigHash)
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expected a single event, got %d", len(recorder.Events))
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "ConfigInvalid") {
+		t.Errorf("unexpected event: %s", event)
+	}
+
+	// removing the condition once the validation is disabled
+	if err := mgr.UpdateConfigValidation(ctx, jobId, "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job, err = mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if meta.FindStatusCondition(job.Status.Conditions, api.ConditionConfigValid) != nil || job.Status.ValidatedConfigHash != "" {
+		t.Errorf("expected the validation result to be removed, got %v", job.Status.Conditions)
+	}
+}
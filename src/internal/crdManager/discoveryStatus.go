@@ -0,0 +1,120 @@
+package crdmanager
+
+import (
+	"context"
+	"fmt"
+
+	api "renovate-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// number of discovery results kept in the status of a renovatejob
+const discoveryHistoryLimit = 10
+
+// maximum number of project names stored per list of a discovery result
+const discoveryResultProjectLimit = 50
+
+// result of a discovery adding and removing the given projects
+func newDiscoveryResult(source string, discovered int, added []string, removed []string, blocked bool) *api.RenovateDiscoveryResult {
+	return &api.RenovateDiscoveryResult{
+		Source:       source,
+		Time:         v1.Now(),
+		Discovered:   int32(discovered),
+		AddedCount:   int32(len(added)),
+		RemovedCount: int32(len(removed)),
+		Added:        truncateProjects(added),
+		Removed:      truncateProjects(removed),
+		Blocked:      blocked,
+	}
+}
+
+func truncateProjects(projects []string) []string {
+	if len(projects) > discoveryResultProjectLimit {
+		projects = projects[:discoveryResultProjectLimit]
+	}
+	return append([]string(nil), projects...)
+}
+
+// record events on the renovatejob describing the result of a discovery
+func (r *renovateJobManager) recordDiscoveryEvents(renovateJob *api.RenovateJob, result *api.RenovateDiscoveryResult) {
+	if result.Source == api.DiscoverySourceRepositoryEvent {
+		if result.Blocked {
+			r.recorder.Eventf(renovateJob, nil, corev1.EventTypeWarning, "ProjectRemovalBlocked", "RemoveProjects",
+				"Repository events would remove %d projects, which exceeds maxProjectRemovals. Confirm the removal to apply it", result.RemovedCount)
+		} else {
+			r.recorder.Eventf(renovateJob, nil, corev1.EventTypeNormal, "ProjectsRemoved", "RemoveProjects",
+				"Removed %d projects for repository events", result.RemovedCount)
+		}
+		return
+	}
+	if result.Blocked {
+		r.recorder.Eventf(renovateJob, nil, corev1.EventTypeWarning, "ProjectRemovalBlocked", "Discover",
+			"Discovery would remove %d projects, which exceeds maxProjectRemovals. Confirm the removal to apply it", result.RemovedCount)
+		return
+	}
+	if result.AddedCount > 0 {
+		r.recorder.Eventf(renovateJob, nil, corev1.EventTypeNormal, "ProjectsAdded", "Discover",
+			"Discovered %d new projects", result.AddedCount)
+	}
+	if result.RemovedCount > 0 {
+		r.recorder.Eventf(renovateJob, nil, corev1.EventTypeNormal, "ProjectsRemoved", "Discover",
+			"Removed %d projects that are no longer discovered", result.RemovedCount)
+	}
+}
+
+// store the result of a discovery and its pending project removal in the status of a renovatejob.
+// a nil removal clears a pending removal and the ProjectRemovalBlocked condition, unless the result is of repository events.
+// without a result, the status is only written if a removal was pending.
+func (r *renovateJobManager) updateDiscoveryStatus(ctx context.Context, job RenovateJobIdentifier, result *api.RenovateDiscoveryResult, removal *api.RenovatePendingProjectRemoval, reason string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
+		if err != nil {
+			return err
+		}
+
+		condition := v1.Condition{
+			Type:               api.ConditionProjectRemovalBlocked,
+			Status:             v1.ConditionFalse,
+			Reason:             reason,
+			Message:            "Discovered projects are applied",
+			ObservedGeneration: renovateJob.Generation,
+		}
+		removalPending := renovateJob.Status.PendingProjectRemoval != nil || meta.IsStatusConditionTrue(renovateJob.Status.Conditions, api.ConditionProjectRemovalBlocked)
+		repositoryEvent := result != nil && result.Source == api.DiscoverySourceRepositoryEvent
+		if removal != nil {
+			condition.Status = v1.ConditionTrue
+			condition.Message = fmt.Sprintf("The last discovery would remove %d projects and is not applied until the removal is confirmed", len(removal.Projects))
+			if repositoryEvent {
+				condition.Message = fmt.Sprintf("Repository events would remove %d projects and are not applied until the removal is confirmed", len(removal.Projects))
+			}
+		} else if result == nil && !removalPending {
+			return nil
+		}
+
+		// removals of repository events do not resolve the pending removal of a discovery
+		if removal != nil || (removalPending && !repositoryEvent) {
+			renovateJob.Status.PendingProjectRemoval = removal
+			meta.SetStatusCondition(&renovateJob.Status.Conditions, condition)
+		}
+		switch {
+		case repositoryEvent && !result.Blocked:
+			renovateJob.Status.RepositoryEventRemovals += result.RemovedCount
+		case result != nil && !repositoryEvent:
+			// the discovery includes the removals of repository events
+			renovateJob.Status.RepositoryEventRemovals = 0
+		}
+		if result != nil {
+			history := append([]api.RenovateDiscoveryResult{*result}, renovateJob.Status.DiscoveryHistory...)
+			if len(history) > discoveryHistoryLimit {
+				history = history[:discoveryHistoryLimit]
+			}
+			renovateJob.Status.DiscoveryHistory = history
+		}
+		_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
+		return err
+	})
+}
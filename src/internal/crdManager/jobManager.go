@@ -2,6 +2,7 @@ package crdmanager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
@@ -19,6 +20,10 @@ const (
 	JOB_LABEL_TYPE       = "renovate-operator.mogenius.com/job-type"
 	JOB_LABEL_NAME       = "renovate-operator.mogenius.com/job-name"
 	JOB_LABEL_GENERATION = "renovate-operator.mogenius.com/generation"
+	// prefix of the labels marking the projects of a batch job, followed by the executor job name of each project
+	JOB_LABEL_BATCH_PREFIX = "batch.renovate-operator.mogenius.com/"
+	// annotation holding the JSON list of projects run by a batch job
+	JOB_ANNOTATION_BATCH_PROJECTS = "renovate-operator.mogenius.com/batch-projects"
 )
 
 type JobType string
@@ -26,6 +31,7 @@ type JobType string
 const (
 	DiscoveryJobType JobType = "discovery"
 	ExecutorJobType  JobType = "executor"
+	ValidatorJobType JobType = "validator"
 )
 
 type JobSelector struct {
@@ -47,7 +53,52 @@ func GetJobByLabel(ctx context.Context, client crclient.Client, selector JobSele
 	if len(allJobs) == 0 {
 		return nil, errors.NewNotFound(batchv1.Resource("jobs"), selector.JobName)
 	}
-	// get the newest job in case there are multiple jobs for the same project (e.g. due to multiple executions)
+	return newestJob(allJobs), nil
+}
+
+/*
+GetExecutorJob retrieves the most recent executor job of a project, given by its executor job name.
+The project either ran in its own job or as part of a batch job.
+Returns a not found error if neither exists.
+*/
+func GetExecutorJob(ctx context.Context, client crclient.Client, namespace string, jobName string) (*batchv1.Job, error) {
+	allJobs, err := GetJobsByLabel(ctx, client, JobSelector{
+		JobName:   jobName,
+		JobType:   ExecutorJobType,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batchJobs := &batchv1.JobList{}
+	err = client.List(ctx, batchJobs, crclient.InNamespace(namespace), crclient.HasLabels{JOB_LABEL_BATCH_PREFIX + jobName})
+	if err != nil {
+		return nil, fmt.Errorf("listing batch jobs of %s: %w", jobName, err)
+	}
+	allJobs = append(allJobs, batchJobs.Items...)
+
+	if len(allJobs) == 0 {
+		return nil, errors.NewNotFound(batchv1.Resource("jobs"), jobName)
+	}
+	return newestJob(allJobs), nil
+}
+
+// projects run by a batch job, nil for jobs running a single project
+func GetBatchProjects(job *batchv1.Job) []string {
+	raw, ok := job.Annotations[JOB_ANNOTATION_BATCH_PROJECTS]
+	if !ok {
+		return nil
+	}
+	var projects []string
+	if err := json.Unmarshal([]byte(raw), &projects); err != nil {
+		return nil
+	}
+	return projects
+}
+
+// get the newest job in case there are multiple jobs for the same project (e.g. due to multiple executions)
+func newestJob(allJobs []batchv1.Job) *batchv1.Job {
 	var currentJob *batchv1.Job
 	var maxGen int64 = -1
 
@@ -66,7 +117,7 @@ func GetJobByLabel(ctx context.Context, client crclient.Client, selector JobSele
 			currentJob = &allJobs[i]
 		}
 	}
-	return currentJob, nil
+	return currentJob
 }
 
 // Retrieve all Jobs by our standard labels
@@ -6,6 +6,7 @@ import (
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -219,3 +220,59 @@ func TestDeleteJobWithWait(t *testing.T) {
 		t.Error("Job should be deleted but still exists")
 	}
 }
+
+func TestGetExecutorJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add batch scheme: %v", err)
+	}
+
+	single := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "project-a-1",
+			Namespace: "test-ns",
+			Labels: map[string]string{
+				JOB_LABEL_NAME:       "project-a",
+				JOB_LABEL_TYPE:       string(ExecutorJobType),
+				JOB_LABEL_GENERATION: "100",
+			},
+		},
+	}
+	batch := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "batch-1",
+			Namespace: "test-ns",
+			Labels: map[string]string{
+				JOB_LABEL_NAME:                       "batch",
+				JOB_LABEL_TYPE:                       string(ExecutorJobType),
+				JOB_LABEL_GENERATION:                 "200",
+				JOB_LABEL_BATCH_PREFIX + "project-a": "true",
+				JOB_LABEL_BATCH_PREFIX + "project-b": "true",
+			},
+			Annotations: map[string]string{
+				JOB_ANNOTATION_BATCH_PROJECTS: `["org/a","org/b"]`,
+			},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(single, batch).Build()
+	ctx := context.Background()
+
+	// the batch job is newer than the job running the project on its own
+	got, err := GetExecutorJob(ctx, client, "test-ns", "project-a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "batch-1" {
+		t.Errorf("expected the newer batch job, got %s", got.Name)
+	}
+	if projects := GetBatchProjects(got); len(projects) != 2 || projects[1] != "org/b" {
+		t.Errorf("unexpected batch projects: %v", projects)
+	}
+	if projects := GetBatchProjects(single); projects != nil {
+		t.Errorf("expected no batch projects for a single job, got %v", projects)
+	}
+
+	if _, err := GetExecutorJob(ctx, client, "test-ns", "project-c"); !errors.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
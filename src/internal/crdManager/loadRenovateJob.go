@@ -9,12 +9,12 @@ import (
 )
 
 // reload a given renovatejob
-func reloadRenovateJob(ctx context.Context, renovateJob *api.RenovateJob, client client.Client) (*api.RenovateJob, error) {
+func reloadRenovateJob(ctx context.Context, renovateJob *api.RenovateJob, client client.Reader) (*api.RenovateJob, error) {
 	return loadRenovateJob(ctx, renovateJob.Name, renovateJob.Namespace, client)
 }
 
 // load a renovatejob by its name and namespace
-func loadRenovateJob(ctx context.Context, name string, namespace string, client client.Client) (*api.RenovateJob, error) {
+func loadRenovateJob(ctx context.Context, name string, namespace string, client client.Reader) (*api.RenovateJob, error) {
 	renovateJob := &api.RenovateJob{}
 	err := client.Get(ctx, types.NamespacedName{
 		Name:      name,
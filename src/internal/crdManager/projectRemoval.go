@@ -0,0 +1,43 @@
+package crdmanager
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// reasons of the ProjectRemovalBlocked condition
+const (
+	reasonTooManyProjectsRemoved  = "TooManyProjectsRemoved"
+	reasonProjectRemovalApplied   = "ProjectRemovalApplied"
+	reasonProjectRemovalConfirmed = "ProjectRemovalConfirmed"
+)
+
+// ProjectRemovalBlockedError is returned by ReconcileProjects if the discovery removed more projects than allowed.
+type ProjectRemovalBlockedError struct {
+	Removed  int
+	Existing int
+}
+
+func (e *ProjectRemovalBlockedError) Error() string {
+	return fmt.Sprintf("discovery would remove %d of %d projects, which exceeds maxProjectRemovals", e.Removed, e.Existing)
+}
+
+// IsProjectRemovalBlocked returns true if the error is a ProjectRemovalBlockedError.
+func IsProjectRemovalBlocked(err error) bool {
+	var blocked *ProjectRemovalBlockedError
+	return stderrors.As(err, &blocked)
+}
+
+// whether a discovery may remove the given number of the existing projects
+func isProjectRemovalAllowed(maxRemovals *intstr.IntOrString, removed int, existing int) (bool, error) {
+	if maxRemovals == nil || removed == 0 {
+		return true, nil
+	}
+	limit, err := intstr.GetScaledValueFromIntOrPercent(maxRemovals, existing, false)
+	if err != nil {
+		return false, fmt.Errorf("invalid maxProjectRemovals: %w", err)
+	}
+	return removed <= limit, nil
+}
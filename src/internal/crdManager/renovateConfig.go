@@ -0,0 +1,88 @@
+package crdmanager
+
+import (
+	"context"
+	"fmt"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// key of the rendered renovate config in the managed ConfigMap
+	RENOVATE_CONFIG_KEY = "config.json"
+	CONFIG_LABEL_JOB    = "renovate-operator.mogenius.com/config-of"
+	// annotation of the pods holding the hash of the renovate config they were started with
+	CONFIG_ANNOTATION_HASH = "renovate-operator.mogenius.com/config-hash"
+)
+
+/*
+EnsureRenovateConfig renders the inline renovate config of the renovatejob into its ConfigMap.
+The ConfigMap is owned by the renovatejob and deleted if the renovatejob has no inline config.
+It is called before jobs are created, so every run uses the config of the current spec.
+*/
+func EnsureRenovateConfig(ctx context.Context, client crclient.Client, renovateJob *api.RenovateJob) error {
+	rendered, err := utils.RenderRenovateConfig(&renovateJob.Spec)
+	if err != nil {
+		return err
+	}
+
+	name := utils.RenovateConfigName(renovateJob)
+	configMap := &corev1.ConfigMap{}
+	err = client.Get(ctx, crclient.ObjectKey{Name: name, Namespace: renovateJob.Namespace}, configMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get renovate config %s: %w", name, err)
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(configMap, renovateJob) {
+		return fmt.Errorf("ConfigMap %s already exists and is not managed by the RenovateJob", name)
+	}
+
+	if rendered == nil {
+		if exists {
+			if err := client.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete renovate config %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	if !exists {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: renovateJob.Namespace,
+				Labels: map[string]string{
+					CONFIG_LABEL_JOB: renovateJob.Name,
+				},
+			},
+			Data: map[string]string{
+				RENOVATE_CONFIG_KEY: string(rendered),
+			},
+		}
+		if err := controllerutil.SetControllerReference(renovateJob, configMap, client.Scheme()); err != nil {
+			return fmt.Errorf("failed to set controller reference: %w", err)
+		}
+		if err := client.Create(ctx, configMap); err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create renovate config %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if configMap.Data[RENOVATE_CONFIG_KEY] == string(rendered) && len(configMap.Data) == 1 {
+		return nil
+	}
+	configMap.Data = map[string]string{
+		RENOVATE_CONFIG_KEY: string(rendered),
+	}
+	if err := client.Update(ctx, configMap); err != nil {
+		return fmt.Errorf("failed to update renovate config %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package crdmanager
+
+import (
+	"context"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEnsureRenovateConfig(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "default", UID: "uid-1"},
+		Spec: api.RenovateJobSpec{
+			RenovateConfig: &runtime.RawExtension{Raw: []byte(`{"onboarding":false}`)},
+		},
+	}
+	client := makeClient(t, job)
+	ctx := context.Background()
+	key := crclient.ObjectKey{Name: utils.RenovateConfigName(job), Namespace: "default"}
+
+	if err := EnsureRenovateConfig(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := client.Get(ctx, key, configMap); err != nil {
+		t.Fatalf("expected the ConfigMap to be created: %v", err)
+	}
+	if configMap.Data[RENOVATE_CONFIG_KEY] != "{\n  \"onboarding\": false\n}" {
+		t.Errorf("unexpected rendered config: %q", configMap.Data[RENOVATE_CONFIG_KEY])
+	}
+	if !metav1.IsControlledBy(configMap, job) {
+		t.Error("expected the ConfigMap to be owned by the renovatejob")
+	}
+
+	// changes are written to the ConfigMap
+	job.Spec.RenovateConfig = &runtime.RawExtension{Raw: []byte(`{"onboarding":true}`)}
+	if err := EnsureRenovateConfig(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get(ctx, key, configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if configMap.Data[RENOVATE_CONFIG_KEY] != "{\n  \"onboarding\": true\n}" {
+		t.Errorf("expected the config to be updated, got %q", configMap.Data[RENOVATE_CONFIG_KEY])
+	}
+
+	// removing the config removes the ConfigMap
+	job.Spec.RenovateConfig = nil
+	if err := EnsureRenovateConfig(ctx, client, job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Get(ctx, key, configMap); !errors.IsNotFound(err) {
+		t.Errorf("expected the ConfigMap to be deleted, got %v", err)
+	}
+}
+
+func TestEnsureRenovateConfig_ForeignConfigMap(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "default", UID: "uid-1"},
+		Spec: api.RenovateJobSpec{
+			RenovateConfig: &runtime.RawExtension{Raw: []byte(`{"onboarding":false}`)},
+		},
+	}
+	foreign := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: utils.RenovateConfigName(job), Namespace: "default"},
+		Data:       map[string]string{"other": "data"},
+	}
+	client := makeClient(t, job, foreign)
+
+	if err := EnsureRenovateConfig(context.Background(), client, job); err == nil {
+		t.Fatal("expected an error for a ConfigMap not managed by the renovatejob")
+	}
+}
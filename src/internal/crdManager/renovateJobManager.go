@@ -12,12 +12,15 @@ import (
 
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/clientProvider"
+	"renovate-operator/internal/parser"
 	"renovate-operator/internal/types"
 	"renovate-operator/internal/utils"
 	"renovate-operator/metricStore"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -42,8 +45,21 @@ type RenovateJobManager interface {
 	UpdateProjectStatusBatched(ctx context.Context, fn func(p api.ProjectStatus) bool, job RenovateJobIdentifier, status *types.RenovateStatusUpdate) error
 	// GetProjectsByStatus retrieves all projects with a specific status within a RenovateJob CRD.
 	GetProjectsByStatus(ctx context.Context, job RenovateJobIdentifier, status api.RenovateProjectStatus) ([]RenovateProjectStatus, error)
-	// ReconcileProjects reconciles the list of projects in a RenovateJob CRD with the provided list.
+	// ReconcileProjects reconciles the RenovateProjects of a RenovateJob CRD with the provided list.
+	// The added and removed projects are recorded in the RenovateJob status and as events.
+	// Removals exceeding maxProjectRemovals are not applied and a ProjectRemovalBlockedError is returned.
 	ReconcileProjects(ctx context.Context, job RenovateJobIdentifier, projects []string) error
+	// ConfirmProjectRemoval applies a blocked discovery of a RenovateJob CRD, including the removal of its projects.
+	ConfirmProjectRemoval(ctx context.Context, job RenovateJobIdentifier) error
+	// AddProject adds a single scheduled project to a RenovateJob CRD, e.g. for a created repository.
+	// It returns false if the project already exists.
+	AddProject(ctx context.Context, job RenovateJobIdentifier, project string) (bool, error)
+	// RemoveProjects removes projects from a RenovateJob CRD, e.g. for deleted repositories, and returns the removed ones.
+	// Removals are counted towards maxProjectRemovals until the next discovery. If the limit is exceeded,
+	// nothing is removed and a ProjectRemovalBlockedError is returned.
+	RemoveProjects(ctx context.Context, job RenovateJobIdentifier, projects []string) ([]string, error)
+	// MigrateLegacyProjectStatus moves projects still stored in the RenovateJob status into RenovateProject CRDs.
+	MigrateLegacyProjectStatus(ctx context.Context, job RenovateJobIdentifier) error
 	// GetLogsForProject retrieves the logs for a specific project within a RenovateJob CRD.
 	GetLogsForProject(ctx context.Context, job RenovateJobIdentifier, project string) (string, error)
 	// IsWebhookTokenValid checks if the provided token is valid for the webhook of the specified RenovateJob CRD.
@@ -52,11 +68,25 @@ type RenovateJobManager interface {
 	IsWebhookSignatureValid(ctx context.Context, job RenovateJobIdentifier, signature string, body []byte) (bool, error)
 	// UpdateExecutionOptions updates the execution options for the specified RenovateJob CRD.
 	UpdateExecutionOptions(ctx context.Context, job RenovateJobIdentifier, options *api.RenovateExecutionOptions) error
+	// SetProjectSuspended suspends or resumes a specific project within a RenovateJob CRD.
+	SetProjectSuspended(ctx context.Context, project string, job RenovateJobIdentifier, suspended bool) error
+	// UpdateConfigValidation stores the result of a config validation as ConfigValid condition of the specified RenovateJob CRD.
+	// A nil condition removes the condition.
+	UpdateConfigValidation(ctx context.Context, job RenovateJobIdentifier, configHash string, condition *v1.Condition) error
+	// UpdateSecretsHash stores the fingerprint of the secrets referenced by the specified RenovateJob CRD.
+	// An empty hash removes it.
+	UpdateSecretsHash(ctx context.Context, job RenovateJobIdentifier, hash string) error
 }
 
 type renovateJobManager struct {
+	// client reading from the informer cache, used for all reads and writes
 	client client.Client
-	lock   *sync.RWMutex
+	// reader bypassing the cache, used to read the latest version of an object before modifying it
+	apiReader client.Reader
+	// records events on renovatejobs, e.g. about discovered projects
+	recorder events.EventRecorder
+	// one lock per renovatejob, serializing all writes to the renovatejob and its projects
+	locks sync.Map
 }
 
 type RenovateJobIdentifier struct {
@@ -75,83 +105,98 @@ type RenovateProjectStatus struct {
 	Priority             int32                     `json:"priority,omitempty"`
 	RenovateResultStatus *string                   `json:"renovateResultStatus,omitempty"`
 	Duration             *string                   `json:"duration,omitempty"`
+	Attempts             int32                     `json:"attempts,omitempty"`
+	NextRetry            *time.Time                `json:"nextRetry,omitempty"`
+	ConsecutiveFailures  int32                     `json:"consecutiveFailures,omitempty"`
+	NotBefore            *time.Time                `json:"notBefore,omitempty"`
+	LastSuccess          *time.Time                `json:"lastSuccess,omitempty"`
+	// the last run is longer ago than the maximum staleness of the renovatejob, not stored in the CRD
+	Stale bool `json:"stale,omitempty"`
+	// suspended projects are not started until they are resumed
+	Suspended bool `json:"suspended,omitempty"`
+	// position in the operator wide execution queue for scheduled projects, not stored in the CRD
+	QueuePosition int `json:"queuePosition,omitempty"`
 }
 
-func NewRenovateJobManager(client client.Client) RenovateJobManager {
-	return &renovateJobManager{
-		client: client,
-		lock:   &sync.RWMutex{},
+func toRenovateProjectStatus(renovateProject *api.RenovateProject) RenovateProjectStatus {
+	project := &renovateProject.Status
+	var nextRetry *time.Time
+	if project.NextRetry != nil {
+		nextRetry = &project.NextRetry.Time
+	}
+	var notBefore *time.Time
+	if project.NotBefore != nil {
+		notBefore = &project.NotBefore.Time
+	}
+	var lastSuccess *time.Time
+	if project.LastSuccess != nil {
+		lastSuccess = &project.LastSuccess.Time
+	}
+	return RenovateProjectStatus{
+		Name:                 project.Name,
+		Status:               project.Status,
+		LastRun:              project.LastRun.Time,
+		Priority:             project.Priority,
+		RenovateResultStatus: project.RenovateResultStatus,
+		Duration:             project.Duration,
+		Attempts:             project.Attempts,
+		NextRetry:            nextRetry,
+		ConsecutiveFailures:  project.ConsecutiveFailures,
+		NotBefore:            notBefore,
+		LastSuccess:          lastSuccess,
+		Suspended:            isRenovateProjectSuspended(renovateProject),
 	}
 }
 
-// globally lock the manager, if parameter is true, lock in read mode
-func (r *renovateJobManager) globalManagerLock(readonly bool) func() {
-	if readonly {
-		r.lock.RLock()
-		return func() {
-			r.lock.RUnlock()
-		}
+func NewRenovateJobManager(client client.Client, apiReader client.Reader, recorder events.EventRecorder) RenovateJobManager {
+	return &renovateJobManager{
+		client:    client,
+		apiReader: apiReader,
+		recorder:  recorder,
 	}
+}
 
-	r.lock.Lock()
+// lock a single renovatejob for writing. reads are served from the cache and do not need a lock
+func (r *renovateJobManager) renovateJobLock(job RenovateJobIdentifier) func() {
+	lock, _ := r.locks.LoadOrStore(job.Fullname(), &sync.Mutex{})
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
 	return func() {
-		r.lock.Unlock()
+		mutex.Unlock()
 	}
 }
 
 func (r *renovateJobManager) GetRenovateJob(ctx context.Context, name string, namespace string) (*api.RenovateJob, error) {
-	defer r.globalManagerLock(true)()
-
 	return loadRenovateJob(ctx, name, namespace, r.client)
 }
 
 func (r *renovateJobManager) GetProjectsByStatus(ctx context.Context, job RenovateJobIdentifier, status api.RenovateProjectStatus) ([]RenovateProjectStatus, error) {
-	defer r.globalManagerLock(true)()
-
-	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+	renovateProjects, err := listRenovateProjects(ctx, job, r.client)
 	if err != nil {
 		return nil, err
 	}
 	result := make([]RenovateProjectStatus, 0)
-	for _, project := range renovateJob.Status.Projects {
-		if project.Status == status {
-			result = append(result, RenovateProjectStatus{
-				Name:                 project.Name,
-				Status:               project.Status,
-				LastRun:              project.LastRun.Time,
-				Priority:             project.Priority,
-				RenovateResultStatus: project.RenovateResultStatus,
-				Duration:             project.Duration,
-			})
+	for _, project := range renovateProjects {
+		if project.Status.Status == status {
+			result = append(result, toRenovateProjectStatus(&project))
 		}
 	}
 	return result, nil
 }
 
 func (r *renovateJobManager) GetProjectsForRenovateJob(ctx context.Context, job RenovateJobIdentifier) ([]RenovateProjectStatus, error) {
-	defer r.globalManagerLock(true)()
-
-	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+	renovateProjects, err := listRenovateProjects(ctx, job, r.client)
 	if err != nil {
 		return nil, err
 	}
-	result := make([]RenovateProjectStatus, 0)
-	for _, project := range renovateJob.Status.Projects {
-		result = append(result, RenovateProjectStatus{
-			Name:                 project.Name,
-			Status:               project.Status,
-			LastRun:              project.LastRun.Time,
-			Priority:             project.Priority,
-			RenovateResultStatus: project.RenovateResultStatus,
-			Duration:             project.Duration,
-		})
+	result := make([]RenovateProjectStatus, 0, len(renovateProjects))
+	for _, project := range renovateProjects {
+		result = append(result, toRenovateProjectStatus(&project))
 	}
 	return result, nil
 }
 
 func (r *renovateJobManager) ListRenovateJobs(ctx context.Context) ([]RenovateJobIdentifier, error) {
-	defer r.globalManagerLock(true)()
-
 	var renovateJobs api.RenovateJobList
 	err := r.client.List(ctx, &renovateJobs)
 	if err != nil {
@@ -170,8 +215,6 @@ func (r *renovateJobManager) ListRenovateJobs(ctx context.Context) ([]RenovateJo
 }
 
 func (r *renovateJobManager) ListRenovateJobsFull(ctx context.Context) ([]api.RenovateJob, error) {
-	defer r.globalManagerLock(true)()
-
 	var renovateJobs api.RenovateJobList
 	err := r.client.List(ctx, &renovateJobs)
 	if err != nil {
@@ -182,130 +225,336 @@ func (r *renovateJobManager) ListRenovateJobsFull(ctx context.Context) ([]api.Re
 }
 
 func (r *renovateJobManager) UpdateProjectStatus(ctx context.Context, project string, job RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
-	defer r.globalManagerLock(false)()
+	defer r.renovateJobLock(job)()
 
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+		// projects are only created by reconciling the discovered and static projects, unknown projects are NotFound
+		renovateProject, err := loadRenovateProject(ctx, job, project, r.apiReader)
 		if err != nil {
 			return err
 		}
-		index := -1
-		for i := range renovateJob.Status.Projects {
-			projectStatus := renovateJob.Status.Projects[i]
-			if projectStatus.Name == project {
-				index = i
-				break
-			}
-		}
-		if index == -1 {
-			projectStatus := &api.ProjectStatus{
-				Name:     project,
-				Status:   status.Status,
-				Priority: status.Priority,
-			}
-			renovateJob.Status.Projects = append(renovateJob.Status.Projects, *projectStatus)
-		} else {
-			projectStatus := renovateJob.Status.Projects[index]
-			renovateJob.Status.Projects[index] = *utils.GetUpdateStatusForProject(&projectStatus, status)
-		}
-		_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
+		renovateProject.Status = *utils.GetUpdateStatusForProject(&renovateProject.Status, status)
+		_, err = updateRenovateProjectStatus(ctx, renovateProject, r.client)
 		return err
 	})
 }
 
 func (r *renovateJobManager) UpdateProjectStatusBatched(ctx context.Context, fn func(p api.ProjectStatus) bool, job RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
-	defer r.globalManagerLock(false)()
+	defer r.renovateJobLock(job)()
 
-	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
-		if err != nil {
+	renovateProjects, err := listRenovateProjects(ctx, job, r.client)
+	if err != nil {
+		return err
+	}
+
+	for i := range renovateProjects {
+		if !fn(renovateProjects[i].Status) {
+			continue
+		}
+		project := renovateProjects[i].Spec.Project
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			renovateProject, err := loadRenovateProject(ctx, job, project, r.apiReader)
+			if err != nil {
+				return err
+			}
+			// the project might have changed since it was listed
+			if !fn(renovateProject.Status) {
+				return nil
+			}
+			renovateProject.Status = *utils.GetUpdateStatusForProject(&renovateProject.Status, status)
+			_, err = updateRenovateProjectStatus(ctx, renovateProject, r.client)
+			return err
+		})
+		if client.IgnoreNotFound(err) != nil {
 			return err
 		}
+	}
+	return nil
+}
+
+func (r *renovateJobManager) ReconcileProjects(ctx context.Context, job RenovateJobIdentifier, projects []string) error {
+	defer r.renovateJobLock(job)()
+	return r.reconcileProjects(ctx, job, projects, reconcileDiscovery)
+}
 
-		for i := range renovateJob.Status.Projects {
-			p := renovateJob.Status.Projects[i]
+// origin of the projects applied to a renovatejob
+type reconcileMode int
 
-			if fn(p) {
-				renovateJob.Status.Projects[i] = *utils.GetUpdateStatusForProject(&p, status)
-			}
+const (
+	// the projects returned by a discovery
+	reconcileDiscovery reconcileMode = iota
+	// a blocked discovery whose removal was confirmed, applied regardless of maxProjectRemovals
+	reconcileConfirmed
+	// the existing projects without the repositories removed by repository events
+	reconcileRepositoryEvent
+)
+
+// apply the discovered projects to a renovatejob
+func (r *renovateJobManager) reconcileProjects(ctx context.Context, job RenovateJobIdentifier, projects []string, mode reconcileMode) error {
+	// the removals of repository events since the last discovery have to be current
+	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
+	if err != nil {
+		return err
+	}
+	renovateProjects, err := listRenovateProjects(ctx, job, r.client)
+	if err != nil {
+		return err
+	}
+
+	// Build a set of current projects
+	crdProjectSet := make(map[string]struct{}, len(renovateProjects))
+	for _, crdProject := range renovateProjects {
+		crdProjectSet[crdProject.Spec.Project] = struct{}{}
+	}
+
+	// Build a set of new projects for quick lookup and collect the projects that are being added
+	newProjectSet := make(map[string]struct{}, len(projects))
+	discoveredProjects := make([]string, 0, len(projects))
+	addedProjects := make([]string, 0)
+	for _, project := range projects {
+		if _, exists := newProjectSet[project]; exists {
+			continue
+		}
+		newProjectSet[project] = struct{}{}
+		discoveredProjects = append(discoveredProjects, project)
+		if _, exists := crdProjectSet[project]; !exists {
+			addedProjects = append(addedProjects, project)
+		}
+	}
+
+	// Collect projects that are being removed
+	removedProjects := make([]*api.RenovateProject, 0)
+	removedNames := make([]string, 0)
+	for i := range renovateProjects {
+		if _, exists := newProjectSet[renovateProjects[i].Spec.Project]; !exists {
+			removedProjects = append(removedProjects, &renovateProjects[i])
+			removedNames = append(removedNames, renovateProjects[i].Spec.Project)
 		}
+	}
 
-		_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
+	// a discovery removing too many projects might be broken (e.g. an expired token or a truncated list),
+	// nothing is applied until the removal is confirmed.
+	// repository events are counted together, so a burst of them can not remove more projects than a discovery.
+	allowed := true
+	switch mode {
+	case reconcileDiscovery:
+		allowed, err = isProjectRemovalAllowed(renovateJob.Spec.MaxProjectRemovals, len(removedProjects), len(renovateProjects))
+	case reconcileRepositoryEvent:
+		previous := int(renovateJob.Status.RepositoryEventRemovals)
+		allowed, err = isProjectRemovalAllowed(renovateJob.Spec.MaxProjectRemovals, previous+len(removedProjects), previous+len(renovateProjects))
+	}
+	if err != nil {
 		return err
-	})
+	}
+	source := ""
+	if mode == reconcileRepositoryEvent {
+		source = api.DiscoverySourceRepositoryEvent
+	}
+	if !allowed {
+		result := newDiscoveryResult(source, len(newProjectSet), addedProjects, removedNames, true)
+		removal := &api.RenovatePendingProjectRemoval{
+			Projects:               removedNames,
+			DiscoveredProjects:     int32(len(newProjectSet)),
+			DiscoveredProjectNames: discoveredProjects,
+			DiscoveredAt:           result.Time,
+		}
+		if err := r.updateDiscoveryStatus(ctx, job, result, removal, reasonTooManyProjectsRemoved); err != nil {
+			return fmt.Errorf("storing pending project removal: %w", err)
+		}
+		r.recordDiscoveryEvents(renovateJob, result)
+		return &ProjectRemovalBlockedError{Removed: len(removedProjects), Existing: len(renovateProjects)}
+	}
+
+	// Delete projects (and their metrics) that are being removed
+	for _, renovateProject := range removedProjects {
+		if err := deleteRenovateProject(ctx, renovateProject, r.client); err != nil {
+			return fmt.Errorf("deleting project %s: %w", renovateProject.Spec.Project, err)
+		}
+		metricStore.DeleteProjectMetrics(job.Namespace, job.Name, renovateProject.Spec.Project)
+	}
+
+	// Create projects that are new
+	for _, project := range addedProjects {
+		_, err := createRenovateProject(ctx, renovateJob, api.ProjectStatus{
+			Name:    project,
+			Status:  api.JobStatusScheduled,
+			LastRun: v1.Now(),
+		}, r.client)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("creating project %s: %w", project, err)
+		}
+	}
+
+	reason := reasonProjectRemovalApplied
+	if mode == reconcileConfirmed {
+		reason = reasonProjectRemovalConfirmed
+	}
+	result := newDiscoveryResult(source, len(newProjectSet), addedProjects, removedNames, false)
+	if err := r.updateDiscoveryStatus(ctx, job, result, nil, reason); err != nil {
+		return fmt.Errorf("storing discovery result: %w", err)
+	}
+	r.recordDiscoveryEvents(renovateJob, result)
+	return nil
 }
 
-func (r *renovateJobManager) ReconcileProjects(ctx context.Context, job RenovateJobIdentifier, projects []string) error {
-	defer r.globalManagerLock(false)()
+func (r *renovateJobManager) ConfirmProjectRemoval(ctx context.Context, job RenovateJobIdentifier) error {
+	defer r.renovateJobLock(job)()
 
-	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
-		if err != nil {
+	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
+	if err != nil {
+		return err
+	}
+	removal := renovateJob.Status.PendingProjectRemoval
+	if removal == nil {
+		return fmt.Errorf("renovatejob %s has no pending project removal", job.Fullname())
+	}
+
+	// the blocked discovery is applied like an unblocked one, including the projects it added
+	discovered := removal.DiscoveredProjectNames
+	if discovered == nil {
+		// removals blocked before the discovered projects were stored only remove the pending projects
+		if discovered, err = r.remainingProjects(ctx, job, removal.Projects); err != nil {
 			return err
 		}
+	}
+	if err := r.reconcileProjects(ctx, job, discovered, reconcileConfirmed); err != nil {
+		return err
+	}
+	r.recorder.Eventf(renovateJob, nil, corev1.EventTypeNormal, "ProjectRemovalConfirmed", "ConfirmProjectRemoval",
+		"Applied the discovery of %d projects after the removal was confirmed", len(discovered))
+	return nil
+}
 
-		// Build a set of current CRD projects
-		crdProjectSet := make(map[string]api.ProjectStatus, len(renovateJob.Status.Projects))
-		for i, crdProject := range renovateJob.Status.Projects {
-			crdProjectSet[crdProject.Name] = renovateJob.Status.Projects[i]
+// the existing projects of a renovatejob without the given ones
+func (r *renovateJobManager) remainingProjects(ctx context.Context, job RenovateJobIdentifier, removed []string) ([]string, error) {
+	renovateProjects, err := listRenovateProjects(ctx, job, r.client)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make([]string, 0, len(renovateProjects))
+	for _, renovateProject := range renovateProjects {
+		if !slices.Contains(removed, renovateProject.Spec.Project) {
+			remaining = append(remaining, renovateProject.Spec.Project)
 		}
+	}
+	return remaining, nil
+}
 
-		// Build a set of new projects for quick lookup
-		newProjectSet := make(map[string]struct{}, len(projects))
-		for _, project := range projects {
-			newProjectSet[project] = struct{}{}
-		}
+func (r *renovateJobManager) AddProject(ctx context.Context, job RenovateJobIdentifier, project string) (bool, error) {
+	defer r.renovateJobLock(job)()
 
-		// Delete metrics for projects that are being removed
-		for projectName := range crdProjectSet {
-			if _, exists := newProjectSet[projectName]; !exists {
-				// Project is being removed, clean up its metrics
-				metricStore.DeleteProjectMetrics(job.Namespace, job.Name, projectName)
-			}
+	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+	if err != nil {
+		return false, err
+	}
+	_, err = createRenovateProject(ctx, renovateJob, api.ProjectStatus{
+		Name:    project,
+		Status:  api.JobStatusScheduled,
+		LastRun: v1.Now(),
+	}, r.client)
+	if errors.IsAlreadyExists(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("creating project %s: %w", project, err)
+	}
+	r.recorder.Eventf(renovateJob, nil, corev1.EventTypeNormal, "ProjectAdded", "AddProject",
+		"Added project %s", project)
+	return true, nil
+}
+
+func (r *renovateJobManager) RemoveProjects(ctx context.Context, job RenovateJobIdentifier, projects []string) ([]string, error) {
+	defer r.renovateJobLock(job)()
+
+	renovateProjects, err := listRenovateProjects(ctx, job, r.client)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make([]string, 0, len(renovateProjects))
+	removed := make([]string, 0)
+	for _, renovateProject := range renovateProjects {
+		if slices.Contains(projects, renovateProject.Spec.Project) {
+			removed = append(removed, renovateProject.Spec.Project)
+		} else {
+			remaining = append(remaining, renovateProject.Spec.Project)
 		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	if err := r.reconcileProjects(ctx, job, remaining, reconcileRepositoryEvent); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
 
-		newProjects := make([]api.ProjectStatus, 0, len(projects))
-		for _, project := range projects {
-			if crdProject, exists := crdProjectSet[project]; exists {
-				// add project that exist in the new project list
-				newProjects = append(newProjects, crdProject)
-			} else {
-				// add new project to the list
-				newProjects = append(newProjects, api.ProjectStatus{
-					Name:    project,
-					Status:  api.JobStatusScheduled,
-					LastRun: v1.Now(),
-				})
-			}
+func (r *renovateJobManager) MigrateLegacyProjectStatus(ctx context.Context, job RenovateJobIdentifier) error {
+	defer r.renovateJobLock(job)()
+
+	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+	if err != nil {
+		return err
+	}
+	if len(renovateJob.Status.Projects) == 0 {
+		return nil
+	}
+
+	for _, project := range renovateJob.Status.Projects {
+		_, err := createRenovateProject(ctx, renovateJob, project, r.client)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("migrating project %s: %w", project.Name, err)
 		}
-		renovateJob.Status.Projects = newProjects
+	}
 
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
+		if err != nil {
+			return err
+		}
+		renovateJob.Status.Projects = nil
 		_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
 		return err
 	})
 }
 
-func (r *renovateJobManager) UpdateProjectConfigStatus(ctx context.Context, project string, job RenovateJobIdentifier, status *string) error {
-	defer r.globalManagerLock(false)()
+func (r *renovateJobManager) SetProjectSuspended(ctx context.Context, project string, job RenovateJobIdentifier, suspended bool) error {
+	defer r.renovateJobLock(job)()
 
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+		renovateProject, err := loadRenovateProject(ctx, job, project, r.apiReader)
 		if err != nil {
 			return err
 		}
-		for i := range renovateJob.Status.Projects {
-			if renovateJob.Status.Projects[i].Name == project {
-				renovateJob.Status.Projects[i].RenovateResultStatus = status
-				_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
-				return err
+		if isRenovateProjectSuspended(renovateProject) == suspended {
+			return nil
+		}
+		if suspended {
+			if renovateProject.Annotations == nil {
+				renovateProject.Annotations = map[string]string{}
 			}
+			renovateProject.Annotations[PROJECT_ANNOTATION_SUSPENDED] = "true"
+		} else {
+			delete(renovateProject.Annotations, PROJECT_ANNOTATION_SUSPENDED)
 		}
-		return nil
+		return r.client.Update(ctx, renovateProject)
+	})
+}
+
+func (r *renovateJobManager) UpdateProjectConfigStatus(ctx context.Context, project string, job RenovateJobIdentifier, status *string) error {
+	defer r.renovateJobLock(job)()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		renovateProject, err := loadRenovateProject(ctx, job, project, r.apiReader)
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		renovateProject.Status.RenovateResultStatus = status
+		_, err = updateRenovateProjectStatus(ctx, renovateProject, r.client)
+		return err
 	})
 }
 
 func (r *renovateJobManager) GetLogsForProject(ctx context.Context, job RenovateJobIdentifier, project string) (string, error) {
-	defer r.globalManagerLock(true)()
 	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
 	if err != nil {
 		return "failed to load renovate job", err
@@ -313,11 +562,7 @@ func (r *renovateJobManager) GetLogsForProject(ctx context.Context, job Renovate
 
 	executorJobName := utils.ExecutorJobName(renovateJob, project)
 
-	executorJob, err := GetJobByLabel(ctx, r.client, JobSelector{
-		JobName:   executorJobName,
-		JobType:   ExecutorJobType,
-		Namespace: job.Namespace,
-	})
+	executorJob, err := GetExecutorJob(ctx, r.client, job.Namespace, executorJobName)
 	if err != nil {
 		return "failed to get job", err
 	}
@@ -329,6 +574,10 @@ func (r *renovateJobManager) GetLogsForProject(ctx context.Context, job Renovate
 	}
 
 	logs, err := GetLastJobLog(ctx, client, executorJob)
+	if err == nil && GetBatchProjects(executorJob) != nil {
+		// only show the part of the batch belonging to the project
+		logs = parser.FilterRepositoryLogs(logs, project)
+	}
 
 	return logs, err
 }
@@ -354,8 +603,6 @@ func (r *renovateJobManager) getRenovateJobTokens(ctx context.Context, job *api.
 }
 
 func (r *renovateJobManager) IsWebhookTokenValid(ctx context.Context, job RenovateJobIdentifier, token string) (bool, error) {
-	defer r.globalManagerLock(true)()
-
 	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
 	if err != nil {
 		return false, err
@@ -379,8 +626,6 @@ func (r *renovateJobManager) IsWebhookTokenValid(ctx context.Context, job Renova
 }
 
 func (r *renovateJobManager) IsWebhookSignatureValid(ctx context.Context, job RenovateJobIdentifier, signature string, body []byte) (bool, error) {
-	defer r.globalManagerLock(true)()
-
 	renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
 	if err != nil {
 		return false, err
@@ -409,10 +654,10 @@ func (r *renovateJobManager) IsWebhookSignatureValid(ctx context.Context, job Re
 }
 
 func (r *renovateJobManager) UpdateExecutionOptions(ctx context.Context, job RenovateJobIdentifier, options *api.RenovateExecutionOptions) error {
-	defer r.globalManagerLock(false)()
+	defer r.renovateJobLock(job)()
 
 	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.client)
+		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
 		if err != nil {
 			return err
 		}
@@ -422,6 +667,23 @@ func (r *renovateJobManager) UpdateExecutionOptions(ctx context.Context, job Ren
 	})
 }
 
+func (r *renovateJobManager) UpdateSecretsHash(ctx context.Context, job RenovateJobIdentifier, hash string) error {
+	defer r.renovateJobLock(job)()
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		renovateJob, err := loadRenovateJob(ctx, job.Name, job.Namespace, r.apiReader)
+		if err != nil {
+			return err
+		}
+		if renovateJob.Status.SecretsHash == hash {
+			return nil
+		}
+		renovateJob.Status.SecretsHash = hash
+		_, err = updateRenovateJobStatus(ctx, renovateJob, r.client)
+		return err
+	})
+}
+
 func computeHMAC256(message []byte, secret string) string {
 	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write(message)
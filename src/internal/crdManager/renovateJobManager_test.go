@@ -2,13 +2,22 @@ package crdmanager
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/internal/types"
+	"renovate-operator/internal/utils"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -36,7 +45,7 @@ func TestListRenovateJobs(t *testing.T) {
 
 	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(j1, j2).Build()
 
-	mgr := NewRenovateJobManager(cl)
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
 	ctx := context.Background()
 	list, err := mgr.ListRenovateJobs(ctx)
 	if err != nil {
@@ -58,7 +67,7 @@ func TestListRenovateJobsFull(t *testing.T) {
 
 	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(j1, j2).Build()
 
-	mgr := NewRenovateJobManager(cl)
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
 	ctx := context.Background()
 	list, err := mgr.ListRenovateJobsFull(ctx)
 	if err != nil {
@@ -78,21 +87,252 @@ func TestListRenovateJobsFull(t *testing.T) {
 	}
 }
 
-func TestUpdateProjectStatus_AddAndUpdate(t *testing.T) {
+// helper to create a RenovateProject owned by the given job
+func makeProject(job *api.RenovateJob, status api.ProjectStatus) *api.RenovateProject {
+	p := &api.RenovateProject{}
+	p.ObjectMeta = metav1.ObjectMeta{
+		Name:      utils.RenovateProjectName(job.Name, status.Name),
+		Namespace: job.Namespace,
+		Labels:    map[string]string{PROJECT_LABEL_RENOVATE_JOB: utils.RenovateJobLabelValue(job.Name)},
+	}
+	p.Spec = api.RenovateProjectSpec{RenovateJob: job.Name, Project: status.Name}
+	p.Status = status
+	return p
+}
+
+// helper to create a fake client which knows the core types and the status subresource of RenovateProjects
+func makeClient(t *testing.T, objs ...client.Object) client.Client {
 	scheme := runtime.NewScheme()
 	if err := api.AddToScheme(scheme); err != nil {
 		t.Fatalf("failed to add scheme: %v", err)
 	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add core scheme: %v", err)
+	}
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&api.RenovateProject{}).
+		WithObjects(objs...).
+		Build()
+}
 
+func getProjects(t *testing.T, cl client.Client, job RenovateJobIdentifier) map[string]api.ProjectStatus {
+	projects, err := listRenovateProjects(context.Background(), job, cl)
+	if err != nil {
+		t.Fatalf("unexpected error listing projects: %v", err)
+	}
+	result := make(map[string]api.ProjectStatus, len(projects))
+	for _, p := range projects {
+		result[p.Spec.Project] = p.Status
+	}
+	return result
+}
+
+func TestUpdateProjectStatus_AddAndUpdate(t *testing.T) {
 	j := makeJob("job1", "default", nil)
-	inner := fake.NewClientBuilder().WithScheme(scheme).WithObjects(j).Build()
-	cl := inner
+	cl := makeClient(t, j)
 
-	mgr := NewRenovateJobManager(cl)
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
 	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
 
-	// shorten retries for tests
-	// override status update implementation to avoid fake client Status() issues
+	// unknown projects are not created by status updates, e.g. a misspelled project name
+	err := mgr.UpdateProjectStatus(ctx, "p1", jobId, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Priority: 1})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected a NotFound error for an unknown project, got %v", err)
+	}
+	if projects := getProjects(t, cl, jobId); len(projects) != 0 {
+		t.Fatalf("expected no project to be created, got: %v", projects)
+	}
+
+	// add new project via manager
+	if _, err := mgr.AddProject(ctx, jobId, "p1"); err != nil {
+		t.Fatalf("unexpected error adding project: %v", err)
+	}
+	err = mgr.UpdateProjectStatus(ctx, "p1", jobId, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Priority: 1})
+	if err != nil {
+		t.Fatalf("unexpected error scheduling project: %v", err)
+	}
+
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 1 || projects["p1"].Status != api.JobStatusScheduled || projects["p1"].Priority != 1 {
+		t.Fatalf("expected project p1 to be added, got: %v", projects)
+	}
+
+	// the project must be owned by the renovatejob
+	renovateProject, err := loadRenovateProject(ctx, jobId, "p1", cl)
+	if err != nil {
+		t.Fatalf("unexpected error loading project: %v", err)
+	}
+	if len(renovateProject.OwnerReferences) != 1 || renovateProject.OwnerReferences[0].Name != "job1" {
+		t.Fatalf("expected project to be owned by job1, got %v", renovateProject.OwnerReferences)
+	}
+
+	// update existing project
+	err = mgr.UpdateProjectStatus(ctx, "p1", jobId, &types.RenovateStatusUpdate{Status: api.JobStatusRunning})
+	if err != nil {
+		t.Fatalf("unexpected error updating project: %v", err)
+	}
+	projects = getProjects(t, cl, jobId)
+	if projects["p1"].Status != api.JobStatusRunning {
+		t.Fatalf("expected status running, got %v", projects["p1"].Status)
+	}
+}
+
+// names of renovatejobs can be longer than the 63 chars allowed for label values
+func TestAddProject_LongRenovateJobName(t *testing.T) {
+	name := strings.Repeat("renovate-", 10)
+	cl := makeClient(t, makeJob(name, "default", nil))
+
+	mgr := NewRenovateJobManager(cl, cl, events.NewFakeRecorder(10))
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: name, Namespace: "default"}
+
+	if _, err := mgr.AddProject(ctx, jobId, "p1"); err != nil {
+		t.Fatalf("unexpected error adding project: %v", err)
+	}
+	renovateProject, err := loadRenovateProject(ctx, jobId, "p1", cl)
+	if err != nil {
+		t.Fatalf("unexpected error loading project: %v", err)
+	}
+	if label := renovateProject.Labels[PROJECT_LABEL_RENOVATE_JOB]; len(label) > 63 {
+		t.Fatalf("expected a valid label value, got %s", label)
+	}
+	if projects := getProjects(t, cl, jobId); len(projects) != 1 {
+		t.Fatalf("expected the project to be listed, got %v", projects)
+	}
+}
+
+func TestUpdateProjectStatusBatched(t *testing.T) {
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "p1", Status: api.JobStatusRunning}),
+		makeProject(j, api.ProjectStatus{Name: "p2", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	// predicate: mark non-running projects as scheduled
+	predicate := func(p api.ProjectStatus) bool { return p.Status != api.JobStatusRunning }
+	err := mgr.UpdateProjectStatusBatched(ctx, predicate, jobId, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled})
+	if err != nil {
+		t.Fatalf("unexpected error in batched update: %v", err)
+	}
+
+	// p1 should remain running, p2 should be scheduled
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %d", len(projects))
+	}
+	if projects["p1"].Status != api.JobStatusRunning {
+		t.Fatalf("expected p1 running, got %v", projects["p1"].Status)
+	}
+	if projects["p2"].Status != api.JobStatusScheduled {
+		t.Fatalf("expected p2 scheduled, got %v", projects["p2"].Status)
+	}
+}
+
+func TestSetProjectSuspended(t *testing.T) {
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "p1", Status: api.JobStatusScheduled}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	isSuspended := func() bool {
+		projects, err := mgr.GetProjectsForRenovateJob(ctx, jobId)
+		if err != nil {
+			t.Fatalf("unexpected error getting projects: %v", err)
+		}
+		return projects[0].Suspended
+	}
+
+	if err := mgr.SetProjectSuspended(ctx, "p1", jobId, true); err != nil {
+		t.Fatalf("unexpected error suspending project: %v", err)
+	}
+	if !isSuspended() {
+		t.Fatalf("expected p1 to be suspended")
+	}
+	// the status is kept while suspended
+	if projects := getProjects(t, cl, jobId); projects["p1"].Status != api.JobStatusScheduled {
+		t.Fatalf("expected p1 to stay scheduled, got %v", projects["p1"].Status)
+	}
+
+	if err := mgr.SetProjectSuspended(ctx, "p1", jobId, false); err != nil {
+		t.Fatalf("unexpected error resuming project: %v", err)
+	}
+	if isSuspended() {
+		t.Fatalf("expected p1 to be resumed")
+	}
+
+	if err := mgr.SetProjectSuspended(ctx, "unknown", jobId, true); !errors.IsNotFound(err) {
+		t.Fatalf("expected not found error for unknown project, got %v", err)
+	}
+}
+
+func TestReconcileProjects_AddsKeepsAndRemoves(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	// existing projects 'a' and 'c' present
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "c", Status: api.JobStatusFailed}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	err := mgr.ReconcileProjects(ctx, jobId, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error in reconcile: %v", err)
+	}
+
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %v", projects)
+	}
+	// ensure a kept its existing status
+	if projects["a"].Status != api.JobStatusCompleted {
+		t.Fatalf("expected a to keep completed status, got %v", projects["a"].Status)
+	}
+	if projects["b"].Status != api.JobStatusScheduled {
+		t.Fatalf("expected b to be added as scheduled, got %v", projects["b"].Status)
+	}
+	if _, exists := projects["c"]; exists {
+		t.Fatalf("expected c to be removed")
+	}
+}
+
+func TestReconcileProjects_IgnoresOtherJobs(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j1 := makeJob("job1", "default", nil)
+	j2 := makeJob("job2", "default", nil)
+	cl := makeClient(t, j1, j2,
+		makeProject(j2, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+
+	err := mgr.ReconcileProjects(ctx, RenovateJobIdentifier{Name: "job1", Namespace: "default"}, []string{"b"})
+	if err != nil {
+		t.Fatalf("unexpected error in reconcile: %v", err)
+	}
+
+	projects := getProjects(t, cl, RenovateJobIdentifier{Name: "job2", Namespace: "default"})
+	if len(projects) != 1 || projects["a"].Status != api.JobStatusCompleted {
+		t.Fatalf("expected projects of job2 to be untouched, got %v", projects)
+	}
+}
+
+// override the status update of renovatejobs, the fake client does not know their status subresource
+func overrideRenovateJobStatusUpdate(t *testing.T) {
 	oldFn := updateRenovateJobStatusFn
 	updateRenovateJobStatusFn = func(ctx context.Context, renovateJob *api.RenovateJob, client client.Client) (*api.RenovateJob, error) {
 		if err := client.Update(ctx, renovateJob); err != nil {
@@ -100,132 +340,379 @@ func TestUpdateProjectStatus_AddAndUpdate(t *testing.T) {
 		}
 		return loadRenovateJob(ctx, renovateJob.Name, renovateJob.Namespace, client)
 	}
-	defer func() { updateRenovateJobStatusFn = oldFn }()
-	// sanity check: inner client can Get the object
-	if err := inner.Get(ctx, client.ObjectKey{Name: "job1", Namespace: "default"}, &api.RenovateJob{}); err != nil {
-		t.Fatalf("inner client cannot find object: %v", err)
+	t.Cleanup(func() { updateRenovateJobStatusFn = oldFn })
+}
+
+func TestReconcileProjects_RecordsDiscoveryHistory(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "c", Status: api.JobStatusCompleted}),
+	)
+
+	recorder := events.NewFakeRecorder(10)
+	mgr := NewRenovateJobManager(cl, cl, recorder)
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	if err := mgr.ReconcileProjects(ctx, jobId, []string{"a", "b", "b"}); err != nil {
+		t.Fatalf("unexpected error in reconcile: %v", err)
 	}
 
-	// debug: try loadRenovateJob directly using the same client
-	if lj, derr := loadRenovateJob(ctx, "job1", "default", cl); derr != nil {
-		t.Fatalf("debug: loadRenovateJob direct error: %v", derr)
-	} else {
-		t.Logf("debug: loadRenovateJob direct succeeded, name=%s ns=%s", lj.Name, lj.Namespace)
+	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if len(job.Status.DiscoveryHistory) != 1 {
+		t.Fatalf("expected 1 discovery result, got %v", job.Status.DiscoveryHistory)
+	}
+	result := job.Status.DiscoveryHistory[0]
+	if result.Discovered != 2 || result.AddedCount != 1 || result.RemovedCount != 1 {
+		t.Fatalf("unexpected counts in discovery result: %+v", result)
+	}
+	if len(result.Added) != 1 || result.Added[0] != "b" || len(result.Removed) != 1 || result.Removed[0] != "c" {
+		t.Fatalf("unexpected projects in discovery result: %+v", result)
 	}
 
-	// try direct status update to reproduce behaviour
-	if lj, _ := loadRenovateJob(ctx, "job1", "default", cl); true {
-		lj.Status.Projects = append(lj.Status.Projects, api.ProjectStatus{Name: "direct", Status: api.JobStatusScheduled})
-		if derr := cl.Status().Update(ctx, lj); derr != nil {
-			t.Logf("direct status update error: %v", derr)
-		} else {
-			t.Logf("direct status update succeeded")
+	expectedEvents := []string{
+		"Normal ProjectsAdded Discovered 1 new projects",
+		"Normal ProjectsRemoved Removed 1 projects that are no longer discovered",
+	}
+	for _, expected := range expectedEvents {
+		select {
+		case event := <-recorder.Events:
+			if event != expected {
+				t.Errorf("expected event %q, got %q", expected, event)
+			}
+		default:
+			t.Errorf("expected event %q, got none", expected)
 		}
 	}
 
-	// add new project via manager
-	err := mgr.UpdateProjectStatus(ctx, "p1", RenovateJobIdentifier{Name: "job1", Namespace: "default"}, &types.RenovateStatusUpdate{Status: api.JobStatusRunning})
+	// only the latest results are kept
+	for range discoveryHistoryLimit + 2 {
+		if err := mgr.ReconcileProjects(ctx, jobId, []string{"a", "b"}); err != nil {
+			t.Fatalf("unexpected error in reconcile: %v", err)
+		}
+	}
+	job, err = mgr.GetRenovateJob(ctx, "job1", "default")
 	if err != nil {
-		t.Fatalf("unexpected error adding project: %v", err)
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if len(job.Status.DiscoveryHistory) != discoveryHistoryLimit {
+		t.Fatalf("expected %d discovery results, got %d", discoveryHistoryLimit, len(job.Status.DiscoveryHistory))
 	}
+	if job.Status.DiscoveryHistory[0].AddedCount != 0 {
+		t.Fatalf("expected the latest result first, got %+v", job.Status.DiscoveryHistory[0])
+	}
+}
+
+func TestReconcileProjects_BlocksMassRemoval(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	maxRemovals := intstr.FromString("50%")
+	j.Spec.MaxProjectRemovals = &maxRemovals
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "b", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "c", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "d", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
 
+	// removing 3 of 4 projects exceeds 50%
+	err := mgr.ReconcileProjects(ctx, jobId, []string{"a", "e"})
+	if !IsProjectRemovalBlocked(err) {
+		t.Fatalf("expected the removal to be blocked, got %v", err)
+	}
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 4 {
+		t.Fatalf("expected no project to be added or removed, got %v", projects)
+	}
 	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
 	if err != nil {
 		t.Fatalf("unexpected error getting job: %v", err)
 	}
-	if len(job.Status.Projects) != 1 || job.Status.Projects[0].Name != "p1" {
-		t.Fatalf("expected project p1 to be added, got: %v", job.Status.Projects)
+	if job.Status.PendingProjectRemoval == nil || len(job.Status.PendingProjectRemoval.Projects) != 3 {
+		t.Fatalf("expected 3 pending project removals, got %v", job.Status.PendingProjectRemoval)
+	}
+	if !meta.IsStatusConditionTrue(job.Status.Conditions, api.ConditionProjectRemovalBlocked) {
+		t.Fatalf("expected the ProjectRemovalBlocked condition to be true, got %v", job.Status.Conditions)
 	}
 
-	// update existing project
-	err = mgr.UpdateProjectStatus(ctx, "p1", RenovateJobIdentifier{Name: "job1", Namespace: "default"}, &types.RenovateStatusUpdate{Status: api.JobStatusRunning})
-	if err != nil {
-		t.Fatalf("unexpected error updating project: %v", err)
+	// removing 2 of 4 projects is allowed and clears the pending removal
+	if err := mgr.ReconcileProjects(ctx, jobId, []string{"a", "b"}); err != nil {
+		t.Fatalf("unexpected error in reconcile: %v", err)
+	}
+	projects = getProjects(t, cl, jobId)
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects, got %v", projects)
 	}
 	job, err = mgr.GetRenovateJob(ctx, "job1", "default")
 	if err != nil {
-		t.Fatalf("unexpected error getting job after update: %v", err)
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if job.Status.PendingProjectRemoval != nil {
+		t.Fatalf("expected the pending removal to be cleared, got %v", job.Status.PendingProjectRemoval)
 	}
-	if job.Status.Projects[0].Status != api.JobStatusRunning {
-		t.Fatalf("expected status running, got %v", job.Status.Projects[0].Status)
+	if meta.IsStatusConditionTrue(job.Status.Conditions, api.ConditionProjectRemovalBlocked) {
+		t.Fatalf("expected the ProjectRemovalBlocked condition to be false, got %v", job.Status.Conditions)
 	}
 }
 
-func TestUpdateProjectStatusBatched(t *testing.T) {
-	scheme := runtime.NewScheme()
-	if err := api.AddToScheme(scheme); err != nil {
-		t.Fatalf("failed to add scheme: %v", err)
+func TestConfirmProjectRemoval(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	maxRemovals := intstr.FromInt32(1)
+	j.Spec.MaxProjectRemovals = &maxRemovals
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "b", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "c", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	if err := mgr.ConfirmProjectRemoval(ctx, jobId); err == nil {
+		t.Fatalf("expected an error without a pending removal")
+	}
+	if err := mgr.ReconcileProjects(ctx, jobId, []string{"a", "d"}); !IsProjectRemovalBlocked(err) {
+		t.Fatalf("expected the removal to be blocked, got %v", err)
+	}
+	if projects := getProjects(t, cl, jobId); len(projects) != 3 {
+		t.Fatalf("expected the blocked discovery not to be applied, got %v", projects)
+	}
+	if err := mgr.ConfirmProjectRemoval(ctx, jobId); err != nil {
+		t.Fatalf("unexpected error confirming the removal: %v", err)
+	}
+
+	// the confirmed discovery is applied with the projects it added
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 2 || projects["a"].Status != api.JobStatusCompleted || projects["d"].Status != api.JobStatusScheduled {
+		t.Fatalf("expected projects a and d, got %v", projects)
+	}
+	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if job.Status.PendingProjectRemoval != nil {
+		t.Fatalf("expected the pending removal to be cleared, got %v", job.Status.PendingProjectRemoval)
+	}
+	condition := meta.FindStatusCondition(job.Status.Conditions, api.ConditionProjectRemovalBlocked)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != reasonProjectRemovalConfirmed {
+		t.Fatalf("expected the removal to be confirmed, got %v", job.Status.Conditions)
+	}
+	if len(job.Status.DiscoveryHistory) != 2 || job.Status.DiscoveryHistory[0].Blocked || job.Status.DiscoveryHistory[0].AddedCount != 1 {
+		t.Fatalf("expected the applied discovery in the history, got %v", job.Status.DiscoveryHistory)
+	}
+}
+
+// pending removals stored without the discovered projects only remove the listed projects
+func TestConfirmProjectRemoval_WithoutDiscoveredProjects(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	j.Status.PendingProjectRemoval = &api.RenovatePendingProjectRemoval{Projects: []string{"b"}, DiscoveredProjects: 1}
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "b", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+	if err := mgr.ConfirmProjectRemoval(context.Background(), jobId); err != nil {
+		t.Fatalf("unexpected error confirming the removal: %v", err)
+	}
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 1 || projects["a"].Status != api.JobStatusCompleted {
+		t.Fatalf("expected only project a to remain, got %v", projects)
 	}
+}
 
-	projects := []api.ProjectStatus{{Name: "p1", Status: api.JobStatusRunning}, {Name: "p2", Status: api.JobStatusScheduled}}
-	j := makeJob("job1", "default", projects)
-	inner := fake.NewClientBuilder().WithScheme(scheme).WithObjects(j).Build()
-	cl := inner
+func TestAddAndRemoveProjects(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+	)
 
-	mgr := NewRenovateJobManager(cl)
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
 	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
 
-	// override status update implementation to avoid fake client Status() issues
-	oldFn := updateRenovateJobStatusFn
-	updateRenovateJobStatusFn = func(ctx context.Context, renovateJob *api.RenovateJob, client client.Client) (*api.RenovateJob, error) {
-		if err := client.Update(ctx, renovateJob); err != nil {
-			return nil, err
-		}
-		return loadRenovateJob(ctx, renovateJob.Name, renovateJob.Namespace, client)
+	if added, err := mgr.AddProject(ctx, jobId, "b"); err != nil || !added {
+		t.Fatalf("expected project b to be added, got %v, %v", added, err)
 	}
-	defer func() { updateRenovateJobStatusFn = oldFn }()
-	// sanity check: inner client can Get the object
-	if err := inner.Get(ctx, client.ObjectKey{Name: "job1", Namespace: "default"}, &api.RenovateJob{}); err != nil {
-		t.Fatalf("inner client cannot find object: %v", err)
+	if added, err := mgr.AddProject(ctx, jobId, "a"); err != nil || added {
+		t.Fatalf("expected existing project a not to be added, got %v, %v", added, err)
+	}
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 2 || projects["b"].Status != api.JobStatusScheduled || projects["a"].Status != api.JobStatusCompleted {
+		t.Fatalf("unexpected projects after adding: %v", projects)
 	}
 
-	// debug: try loadRenovateJob directly using the same client
-	if _, derr := loadRenovateJob(ctx, "job1", "default", cl); derr != nil {
-		t.Fatalf("debug: loadRenovateJob direct error: %v", derr)
+	if removed, err := mgr.RemoveProjects(ctx, jobId, []string{"a", "missing"}); err != nil || !slices.Equal(removed, []string{"a"}) {
+		t.Fatalf("expected only project a to be removed, got %v, %v", removed, err)
+	}
+	if removed, err := mgr.RemoveProjects(ctx, jobId, []string{"missing"}); err != nil || len(removed) != 0 {
+		t.Fatalf("expected missing project not to be removed, got %v, %v", removed, err)
+	}
+	projects = getProjects(t, cl, jobId)
+	if _, ok := projects["b"]; len(projects) != 1 || !ok {
+		t.Fatalf("expected only project b to remain, got %v", projects)
 	}
 
-	// predicate: mark non-running projects as scheduled
-	predicate := func(p api.ProjectStatus) bool { return p.Status != api.JobStatusRunning }
-	err := mgr.UpdateProjectStatusBatched(ctx, predicate, RenovateJobIdentifier{Name: "job1", Namespace: "default"}, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled})
+	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
 	if err != nil {
-		t.Fatalf("unexpected error in batched update: %v", err)
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if len(job.Status.DiscoveryHistory) != 1 || job.Status.DiscoveryHistory[0].Source != api.DiscoverySourceRepositoryEvent || job.Status.DiscoveryHistory[0].RemovedCount != 1 {
+		t.Fatalf("expected the removal in the discovery history, got %v", job.Status.DiscoveryHistory)
+	}
+	if job.Status.RepositoryEventRemovals != 1 {
+		t.Fatalf("expected 1 removal by repository events, got %d", job.Status.RepositoryEventRemovals)
+	}
+}
+
+// removals of repository events are counted together until the next discovery
+func TestRemoveProjects_BlocksMassRemoval(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	maxRemovals := intstr.FromInt32(2)
+	j.Spec.MaxProjectRemovals = &maxRemovals
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "b", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "c", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "d", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "e", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	for _, project := range []string{"a", "b"} {
+		if _, err := mgr.RemoveProjects(ctx, jobId, []string{project}); err != nil {
+			t.Fatalf("unexpected error removing %s: %v", project, err)
+		}
+	}
+	if _, err := mgr.RemoveProjects(ctx, jobId, []string{"c"}); !IsProjectRemovalBlocked(err) {
+		t.Fatalf("expected the third removal to be blocked, got %v", err)
+	}
+	if projects := getProjects(t, cl, jobId); len(projects) != 3 {
+		t.Fatalf("expected the blocked removal not to be applied, got %v", projects)
 	}
 	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
 	if err != nil {
 		t.Fatalf("unexpected error getting job: %v", err)
 	}
-	// p1 should remain running, p2 should be scheduled
-	if len(job.Status.Projects) != 2 {
-		t.Fatalf("expected 2 projects, got %d", len(job.Status.Projects))
-	}
-	foundP2 := false
-	for _, p := range job.Status.Projects {
-		if p.Name == "p2" {
-			foundP2 = true
-			if p.Status != api.JobStatusScheduled {
-				t.Fatalf("expected p2 scheduled, got %v", p.Status)
-			}
-		}
+	if job.Status.PendingProjectRemoval == nil || !slices.Equal(job.Status.PendingProjectRemoval.Projects, []string{"c"}) {
+		t.Fatalf("expected the pending removal of project c, got %v", job.Status.PendingProjectRemoval)
+	}
+	if !job.Status.DiscoveryHistory[0].Blocked {
+		t.Fatalf("expected the blocked removal in the history, got %v", job.Status.DiscoveryHistory)
 	}
-	if !foundP2 {
-		t.Fatalf("p2 not found after batched update")
+
+	// a discovery resets the count of removals
+	if err := mgr.ReconcileProjects(ctx, jobId, []string{"c", "d", "e"}); err != nil {
+		t.Fatalf("unexpected error in reconcile: %v", err)
+	}
+	if _, err := mgr.RemoveProjects(ctx, jobId, []string{"c"}); err != nil {
+		t.Fatalf("unexpected error removing c after the discovery: %v", err)
+	}
+	job, err = mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if job.Status.RepositoryEventRemovals != 1 || job.Status.PendingProjectRemoval != nil {
+		t.Fatalf("expected 1 removal and no pending removal, got %d, %v", job.Status.RepositoryEventRemovals, job.Status.PendingProjectRemoval)
 	}
 }
 
-func TestReconcileProjects_AddsAndKeepsExisting(t *testing.T) {
-	scheme := runtime.NewScheme()
-	if err := api.AddToScheme(scheme); err != nil {
-		t.Fatalf("failed to add scheme: %v", err)
+// removals of repository events do not clear the pending removal of a discovery
+func TestRemoveProjects_KeepsPendingDiscoveryRemoval(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	j := makeJob("job1", "default", nil)
+	maxRemovals := intstr.FromInt32(1)
+	j.Spec.MaxProjectRemovals = &maxRemovals
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "b", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "c", Status: api.JobStatusCompleted}),
+	)
+
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	if err := mgr.ReconcileProjects(ctx, jobId, []string{"a"}); !IsProjectRemovalBlocked(err) {
+		t.Fatalf("expected the discovery to be blocked, got %v", err)
 	}
+	if _, err := mgr.RemoveProjects(ctx, jobId, []string{"a"}); err != nil {
+		t.Fatalf("unexpected error removing a: %v", err)
+	}
+	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if job.Status.PendingProjectRemoval == nil || !meta.IsStatusConditionTrue(job.Status.Conditions, api.ConditionProjectRemovalBlocked) {
+		t.Fatalf("expected the pending removal of the discovery to be kept, got %v, %v", job.Status.PendingProjectRemoval, job.Status.Conditions)
+	}
+}
+
+func TestIsProjectRemovalAllowed(t *testing.T) {
+	count := intstr.FromInt32(5)
+	percent := intstr.FromString("10%")
+	invalid := intstr.FromString("ten")
+
+	tests := []struct {
+		name        string
+		maxRemovals *intstr.IntOrString
+		removed     int
+		existing    int
+		expected    bool
+		expectErr   bool
+	}{
+		{name: "not limited", maxRemovals: nil, removed: 100, existing: 100, expected: true},
+		{name: "nothing removed", maxRemovals: &count, removed: 0, existing: 100, expected: true},
+		{name: "count within limit", maxRemovals: &count, removed: 5, existing: 100, expected: true},
+		{name: "count above limit", maxRemovals: &count, removed: 6, existing: 100, expected: false},
+		{name: "percentage within limit", maxRemovals: &percent, removed: 10, existing: 100, expected: true},
+		{name: "percentage above limit", maxRemovals: &percent, removed: 11, existing: 100, expected: false},
+		{name: "invalid value", maxRemovals: &invalid, removed: 1, existing: 100, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isProjectRemovalAllowed(tt.maxRemovals, tt.removed, tt.existing)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
 
-	// existing project 'a' present
-	projects := []api.ProjectStatus{{Name: "a", Status: api.JobStatusCompleted}}
-	j := makeJob("job1", "default", projects)
-	inner := fake.NewClientBuilder().WithScheme(scheme).WithObjects(j).Build()
-	cl := inner
+func TestMigrateLegacyProjectStatus(t *testing.T) {
+	legacy := []api.ProjectStatus{
+		{Name: "a", Status: api.JobStatusCompleted, Priority: 0, LastRun: metav1.Now()},
+		{Name: "b", Status: api.JobStatusScheduled, Priority: 2, LastRun: metav1.Now()},
+	}
+	j := makeJob("job1", "default", legacy)
+	// project a has already been migrated by a previous, interrupted attempt
+	cl := makeClient(t, j, makeProject(j, legacy[0]))
 
-	mgr := NewRenovateJobManager(cl)
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
 	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
 
 	// override status update implementation to avoid fake client Status() issues
 	oldFn := updateRenovateJobStatusFn
@@ -236,56 +723,36 @@ func TestReconcileProjects_AddsAndKeepsExisting(t *testing.T) {
 		return loadRenovateJob(ctx, renovateJob.Name, renovateJob.Namespace, client)
 	}
 	defer func() { updateRenovateJobStatusFn = oldFn }()
-	// sanity check: inner client can Get the object
-	if err := inner.Get(ctx, client.ObjectKey{Name: "job1", Namespace: "default"}, &api.RenovateJob{}); err != nil {
-		t.Fatalf("inner client cannot find object: %v", err)
-	}
-	// debug: try loadRenovateJob directly using the same client
-	if _, derr := loadRenovateJob(ctx, "job1", "default", cl); derr != nil {
-		t.Fatalf("debug: loadRenovateJob direct error: %v", derr)
+
+	if err := mgr.MigrateLegacyProjectStatus(ctx, jobId); err != nil {
+		t.Fatalf("unexpected error in migration: %v", err)
 	}
 
-	err := mgr.ReconcileProjects(ctx, RenovateJobIdentifier{Name: "job1", Namespace: "default"}, []string{"a", "b"})
-	if err != nil {
-		t.Fatalf("unexpected error in reconcile: %v", err)
+	projects := getProjects(t, cl, jobId)
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 migrated projects, got %v", projects)
+	}
+	if projects["b"].Status != api.JobStatusScheduled || projects["b"].Priority != 2 {
+		t.Fatalf("expected b to keep its status, got %v", projects["b"])
 	}
+
 	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
 	if err != nil {
 		t.Fatalf("unexpected error getting job: %v", err)
 	}
-	if len(job.Status.Projects) != 2 {
-		t.Fatalf("expected 2 projects, got %d", len(job.Status.Projects))
-	}
-	// ensure a kept its existing status
-	var statusA api.RenovateProjectStatus
-	var hasB bool
-	for _, p := range job.Status.Projects {
-		if p.Name == "a" {
-			statusA = p.Status
-		}
-		if p.Name == "b" {
-			hasB = true
-		}
-	}
-	if statusA != api.JobStatusCompleted {
-		t.Fatalf("expected a to keep completed status, got %v", statusA)
-	}
-	if !hasB {
-		t.Fatalf("expected b to be added")
+	if len(job.Status.Projects) != 0 {
+		t.Fatalf("expected legacy project list to be cleared, got %v", job.Status.Projects)
 	}
 }
 
 func TestGetProjectsFilters(t *testing.T) {
-	scheme := runtime.NewScheme()
-	if err := api.AddToScheme(scheme); err != nil {
-		t.Fatalf("failed to add scheme: %v", err)
-	}
-
-	projects := []api.ProjectStatus{{Name: "a", Status: api.JobStatusCompleted}, {Name: "b", Status: api.JobStatusScheduled}}
-	j := makeJob("job1", "default", projects)
-	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(j).Build()
+	j := makeJob("job1", "default", nil)
+	cl := makeClient(t, j,
+		makeProject(j, api.ProjectStatus{Name: "a", Status: api.JobStatusCompleted}),
+		makeProject(j, api.ProjectStatus{Name: "b", Status: api.JobStatusScheduled}),
+	)
 
-	mgr := NewRenovateJobManager(cl)
+	mgr := NewRenovateJobManager(cl, cl, &events.FakeRecorder{})
 	ctx := context.Background()
 
 	list, err := mgr.GetProjectsByStatus(ctx, RenovateJobIdentifier{Name: "job1", Namespace: "default"}, api.JobStatusCompleted)
@@ -304,3 +771,67 @@ func TestGetProjectsFilters(t *testing.T) {
 		t.Fatalf("expected 2 projects from GetProjectsForRenovateJob, got %d", len(all))
 	}
 }
+
+func TestRenovateJobLock_PerJob(t *testing.T) {
+	mgr := NewRenovateJobManager(nil, nil, &events.FakeRecorder{}).(*renovateJobManager)
+	job1 := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+	job2 := RenovateJobIdentifier{Name: "job2", Namespace: "default"}
+
+	unlock := mgr.renovateJobLock(job1)
+
+	// a different job must not be blocked
+	done := make(chan struct{})
+	go func() {
+		mgr.renovateJobLock(job2)()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("lock of job2 was blocked by job1")
+	}
+
+	// the same job must be blocked until it is unlocked
+	done = make(chan struct{})
+	go func() {
+		mgr.renovateJobLock(job1)()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatalf("lock of job1 was acquired twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("lock of job1 was not released")
+	}
+}
+
+func TestUpdateSecretsHash(t *testing.T) {
+	overrideRenovateJobStatusUpdate(t)
+	cl := makeClient(t, makeJob("job1", "default", nil))
+	mgr := NewRenovateJobManager(cl, cl, events.NewFakeRecorder(10))
+	ctx := context.Background()
+	jobId := RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	if err := mgr.UpdateSecretsHash(ctx, jobId, "abc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	job, err := mgr.GetRenovateJob(ctx, "job1", "default")
+	if err != nil {
+		t.Fatalf("unexpected error getting job: %v", err)
+	}
+	if job.Status.SecretsHash != "abc" {
+		t.Fatalf("expected the fingerprint to be stored, got %q", job.Status.SecretsHash)
+	}
+
+	if err := mgr.UpdateSecretsHash(ctx, jobId, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job, _ = mgr.GetRenovateJob(ctx, "job1", "default"); job.Status.SecretsHash != "" {
+		t.Fatalf("expected the fingerprint to be removed, got %q", job.Status.SecretsHash)
+	}
+}
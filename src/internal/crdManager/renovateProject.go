@@ -0,0 +1,95 @@
+package crdmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// label put on every RenovateProject, holding the name of the owning RenovateJob, hashed if it is too long for a label
+const PROJECT_LABEL_RENOVATE_JOB = "renovate-operator.mogenius.com/renovate-job"
+
+// annotation on a RenovateProject, the project is not started while it is set to "true"
+const PROJECT_ANNOTATION_SUSPENDED = "renovate-operator.mogenius.com/suspended"
+
+// whether the project is suspended by its annotation
+func isRenovateProjectSuspended(renovateProject *api.RenovateProject) bool {
+	return renovateProject.Annotations[PROJECT_ANNOTATION_SUSPENDED] == "true"
+}
+
+// load the renovateproject of a project within a renovatejob
+func loadRenovateProject(ctx context.Context, job RenovateJobIdentifier, project string, client client.Reader) (*api.RenovateProject, error) {
+	renovateProject := &api.RenovateProject{}
+	err := client.Get(ctx, types.NamespacedName{
+		Name:      utils.RenovateProjectName(job.Name, project),
+		Namespace: job.Namespace,
+	}, renovateProject)
+	if err != nil {
+		return nil, err
+	}
+
+	return renovateProject, nil
+}
+
+// list all renovateprojects belonging to a renovatejob, sorted by project name
+func listRenovateProjects(ctx context.Context, job RenovateJobIdentifier, c client.Reader) ([]api.RenovateProject, error) {
+	var renovateProjects api.RenovateProjectList
+	err := c.List(ctx, &renovateProjects,
+		client.InNamespace(job.Namespace),
+		client.MatchingLabels{PROJECT_LABEL_RENOVATE_JOB: utils.RenovateJobLabelValue(job.Name)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing renovateprojects for %s: %w", job.Fullname(), err)
+	}
+
+	sort.Slice(renovateProjects.Items, func(i, j int) bool {
+		return renovateProjects.Items[i].Spec.Project < renovateProjects.Items[j].Spec.Project
+	})
+	return renovateProjects.Items, nil
+}
+
+// create a renovateproject owned by the given renovatejob and initialize its status
+func createRenovateProject(ctx context.Context, renovateJob *api.RenovateJob, status api.ProjectStatus, client client.Client) (*api.RenovateProject, error) {
+	renovateProject := &api.RenovateProject{}
+	renovateProject.Name = utils.RenovateProjectName(renovateJob.Name, status.Name)
+	renovateProject.Namespace = renovateJob.Namespace
+	renovateProject.Labels = map[string]string{
+		PROJECT_LABEL_RENOVATE_JOB: utils.RenovateJobLabelValue(renovateJob.Name),
+	}
+	renovateProject.Spec = api.RenovateProjectSpec{
+		RenovateJob: renovateJob.Name,
+		Project:     status.Name,
+	}
+	if err := controllerutil.SetControllerReference(renovateJob, renovateProject, client.Scheme()); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	if err := client.Create(ctx, renovateProject); err != nil {
+		return nil, err
+	}
+
+	// status is ignored on create, it has to be written through the status subresource
+	renovateProject.Status = status
+	return updateRenovateProjectStatus(ctx, renovateProject, client)
+}
+
+// update the status of the provided renovateproject and return the updated version
+func updateRenovateProjectStatus(ctx context.Context, renovateProject *api.RenovateProject, client client.Client) (*api.RenovateProject, error) {
+	err := client.Status().Update(ctx, renovateProject)
+	if err != nil {
+		return nil, err
+	}
+	return renovateProject, nil
+}
+
+// delete a renovateproject
+func deleteRenovateProject(ctx context.Context, renovateProject *api.RenovateProject, c client.Client) error {
+	return client.IgnoreNotFound(c.Delete(ctx, renovateProject))
+}
@@ -0,0 +1,66 @@
+package crdmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+
+	api "renovate-operator/api/v1alpha1"
+
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// field index of renovatejobs by the names of the secrets they reference
+const SECRET_REF_INDEX = "renovate-operator.mogenius.com/secret-refs"
+
+// names of the secrets in the namespace of the renovatejob used by its jobs, the webhook and the webhook sync
+func ReferencedSecrets(renovateJob *api.RenovateJob) []string {
+	spec := &renovateJob.Spec
+	secrets := []string{}
+	if spec.SecretRef != "" {
+		secrets = append(secrets, spec.SecretRef)
+	}
+	for _, source := range spec.ExtraEnvFrom {
+		if source.SecretRef != nil {
+			secrets = append(secrets, source.SecretRef.Name)
+		}
+	}
+	for _, env := range spec.ExtraEnv {
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			secrets = append(secrets, env.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	for _, volume := range spec.ExtraVolumes {
+		if volume.Secret != nil {
+			secrets = append(secrets, volume.Secret.SecretName)
+		}
+	}
+	if webhook := spec.Webhook; webhook != nil {
+		if webhook.Authentication != nil && webhook.Authentication.SecretRef != nil {
+			secrets = append(secrets, webhook.Authentication.SecretRef.Name)
+		}
+		if webhook.Forgejo != nil && webhook.Forgejo.Sync != nil {
+			if ref := webhook.Forgejo.Sync.TokenSecretRef; ref != nil {
+				secrets = append(secrets, ref.Name)
+			}
+			if ref := webhook.Forgejo.Sync.AuthTokenSecretRef; ref != nil {
+				secrets = append(secrets, ref.Name)
+			}
+		}
+	}
+	secrets = slices.DeleteFunc(secrets, func(name string) bool { return name == "" })
+	slices.Sort(secrets)
+	return slices.Compact(secrets)
+}
+
+// SecretsFingerprint hashes the contents of all secrets referenced by the renovatejob. Missing secrets are skipped.
+func SecretsFingerprint(ctx context.Context, client crclient.Client, renovateJob *api.RenovateJob) (string, error) {
+	h := sha256.New()
+	for _, name := range ReferencedSecrets(renovateJob) {
+		if err := writeSecret(ctx, client, h, renovateJob.Namespace, name, true); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:8]), nil
+}
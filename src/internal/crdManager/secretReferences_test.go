@@ -0,0 +1,90 @@
+package crdmanager
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReferencedSecrets(t *testing.T) {
+	job := &api.RenovateJob{
+		Spec: api.RenovateJobSpec{
+			SecretRef: "renovate-secret",
+			ExtraEnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-secret"}}},
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-configmap"}}},
+			},
+			ExtraEnv: []corev1.EnvVar{
+				{Name: "GITHUB_COM_TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "github-secret"}, Key: "token",
+				}}},
+				{Name: "LOG_LEVEL", Value: "debug"},
+			},
+			ExtraVolumes: []corev1.Volume{
+				{Name: "config", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "renovate-secret"}}},
+			},
+			Webhook: &api.RenovateWebhook{
+				Authentication: &api.RenovateWebhookAuth{SecretRef: &api.RenovateSecretKeyReference{Name: "webhook-secret", Key: "token"}},
+				Forgejo: &api.RenovateWebhookForgejo{Sync: &api.RenovateWebhookForgejoSync{
+					TokenSecretRef:     &api.RenovateSecretKeyReference{Name: "forgejo-secret", Key: "token"},
+					AuthTokenSecretRef: &api.RenovateSecretKeyReference{Name: "webhook-secret", Key: "token"},
+				}},
+			},
+		},
+	}
+
+	expected := []string{"env-secret", "forgejo-secret", "github-secret", "renovate-secret", "webhook-secret"}
+	if secrets := ReferencedSecrets(job); !slices.Equal(secrets, expected) {
+		t.Errorf("expected %v, got %v", expected, secrets)
+	}
+	if secrets := ReferencedSecrets(&api.RenovateJob{}); len(secrets) != 0 {
+		t.Errorf("expected no secrets, got %v", secrets)
+	}
+}
+
+func TestSecretsFingerprint(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "default"},
+		Spec: api.RenovateJobSpec{
+			SecretRef: "renovate-secret",
+			Webhook: &api.RenovateWebhook{Forgejo: &api.RenovateWebhookForgejo{Sync: &api.RenovateWebhookForgejoSync{
+				TokenSecretRef: &api.RenovateSecretKeyReference{Name: "missing", Key: "token"},
+			}}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate-secret", Namespace: "default"},
+		Data:       map[string][]byte{"RENOVATE_TOKEN": []byte("token")},
+	}
+	client := makeClient(t, secret)
+	ctx := context.Background()
+
+	// missing secrets are skipped
+	fingerprint, err := SecretsFingerprint(ctx, client, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secret.Data["RENOVATE_TOKEN"] = []byte("rotated")
+	if err := client.Update(ctx, secret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rotated, err := SecretsFingerprint(ctx, client, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rotated == fingerprint {
+		t.Error("expected the fingerprint to change with the secret contents")
+	}
+
+	// the image is not part of the fingerprint
+	job.Spec.Image = "renovate/renovate:42"
+	if updated, _ := SecretsFingerprint(ctx, client, job); updated != rotated {
+		t.Errorf("expected the fingerprint to only depend on the secrets, got %s and %s", rotated, updated)
+	}
+}
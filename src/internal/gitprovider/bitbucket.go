@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 // BitbucketClient implements GitProviderClient for the Bitbucket Cloud API.
@@ -44,3 +45,60 @@ func (c *BitbucketClient) IsFork(ctx context.Context, project string) (bool, err
 	}
 	return repo.Parent != nil, nil
 }
+
+func (c *BitbucketClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	if len(topics) > 0 {
+		return nil, fmt.Errorf("bitbucket does not support discovering repositories by topics")
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer " + c.token,
+		"Accept":        "application/json",
+	}
+	next := c.endpoint + "/2.0/repositories?role=contributor&pagelen=100"
+
+	var projects []string
+	for page := 0; next != ""; page++ {
+		if page >= maxRepositoryPages {
+			return nil, fmt.Errorf("bitbucket API returned more than %d pages of repositories", maxRepositoryPages)
+		}
+		var result struct {
+			Values []struct {
+				FullName string `json:"full_name"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		if _, err := getJSON(ctx, c.httpClient, "bitbucket", next, headers, &result); err != nil {
+			return nil, err
+		}
+		for _, repo := range result.Values {
+			projects = append(projects, repo.FullName)
+		}
+		next = result.Next
+	}
+	return projects, nil
+}
+
+func (c *BitbucketClient) GetRepositoryMetadata(ctx context.Context, project string) (*RepositoryMetadata, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + c.token,
+		"Accept":        "application/json",
+	}
+	var repo struct {
+		UpdatedOn  time.Time `json:"updated_on"`
+		MainBranch *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+	}
+	if _, err := getJSON(ctx, c.httpClient, "bitbucket", fmt.Sprintf("%s/2.0/repositories/%s", c.endpoint, project), headers, &repo); err != nil {
+		return nil, err
+	}
+	// bitbucket cloud has no archived repositories, repositories without commits have no main branch
+	metadata := &RepositoryMetadata{
+		Empty:    repo.MainBranch == nil,
+		LastPush: repo.UpdatedOn,
+	}
+	if repo.MainBranch != nil {
+		metadata.DefaultBranch = repo.MainBranch.Name
+	}
+	return metadata, nil
+}
@@ -18,6 +18,11 @@ import (
 type GitProviderClient interface {
 	// IsFork returns true if the given project is a fork.
 	IsFork(ctx context.Context, project string) (bool, error)
+	// ListRepositories returns the full names of all non-archived repositories the token has access to.
+	// If topics are given, only repositories with at least one of the topics are returned.
+	ListRepositories(ctx context.Context, topics []string) ([]string, error)
+	// GetRepositoryMetadata returns the archived and empty flags, last push and default branch of the given project.
+	GetRepositoryMetadata(ctx context.Context, project string) (*RepositoryMetadata, error)
 }
 
 // ClientFactory creates a GitProviderClient for a given RenovateJob.
@@ -30,12 +35,12 @@ func NewClientFactory(c client.Client) ClientFactory {
 	return func(ctx context.Context, job *api.RenovateJob) (GitProviderClient, error) {
 		platform, endpoint := utils.GetPlatformAndEndpoint(job.Spec.Provider)
 		if platform == "" {
-			return nil, fmt.Errorf("skipForks requires a provider to be configured")
+			return nil, fmt.Errorf("a provider must be configured to use the platform API")
 		}
 
 		token, err := readToken(ctx, c, job)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read platform token for fork filtering: %w", err)
+			return nil, fmt.Errorf("failed to read platform token: %w", err)
 		}
 
 		httpClient := &http.Client{Timeout: 10 * time.Second}
@@ -50,7 +55,7 @@ func NewClientFactory(c client.Client) ClientFactory {
 		case "bitbucket":
 			return &BitbucketClient{endpoint: endpoint, token: token, httpClient: httpClient}, nil
 		default:
-			return nil, fmt.Errorf("skipForks is not supported for platform %q", platform)
+			return nil, fmt.Errorf("the platform API is not supported for platform %q", platform)
 		}
 	}
 }
@@ -59,15 +64,33 @@ func NewClientFactory(c client.Client) ClientFactory {
 // using the given client. On API errors for individual repos, the repo is kept
 // (fail-open) to avoid accidentally excluding valid projects.
 func FilterForks(ctx context.Context, providerClient GitProviderClient, logger logr.Logger, projects []string) ([]string, error) {
+	filtered := filterProjects(ctx, logger, projects, func(ctx context.Context, project string) (string, error) {
+		isFork, err := providerClient.IsFork(ctx, project)
+		if err != nil || !isFork {
+			return "", err
+		}
+		return "fork", nil
+	})
+
+	removed := len(projects) - len(filtered)
+	if removed > 0 {
+		logger.Info("Filtered forked repositories", "removed", removed, "remaining", len(filtered))
+	}
+	return filtered, nil
+}
+
+// filterProjects checks all projects concurrently and keeps those the check returns no exclusion reason for.
+// On errors for individual repos, the repo is kept (fail-open).
+func filterProjects(ctx context.Context, logger logr.Logger, projects []string, check func(ctx context.Context, project string) (string, error)) []string {
 	if len(projects) == 0 {
-		return projects, nil
+		return projects
 	}
 
 	const maxConcurrency = 10
 
 	type result struct {
 		project string
-		isFork  bool
+		reason  string
 		err     error
 	}
 
@@ -82,8 +105,8 @@ func FilterForks(ctx context.Context, providerClient GitProviderClient, logger l
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			isFork, err := providerClient.IsFork(ctx, proj)
-			results[idx] = result{project: proj, isFork: isFork, err: err}
+			reason, err := check(ctx, proj)
+			results[idx] = result{project: proj, reason: reason, err: err}
 		}(i, project)
 	}
 	wg.Wait()
@@ -91,29 +114,24 @@ func FilterForks(ctx context.Context, providerClient GitProviderClient, logger l
 	filtered := make([]string, 0, len(projects))
 	for _, r := range results {
 		if r.err != nil {
-			logger.V(1).Info("Failed to check if repo is fork, keeping it", "project", r.project, "error", r.err)
+			logger.V(1).Info("Failed to check repository, keeping it", "project", r.project, "error", r.err)
 			filtered = append(filtered, r.project)
 			continue
 		}
-		if !r.isFork {
+		if r.reason == "" {
 			filtered = append(filtered, r.project)
 		} else {
-			logger.V(2).Info("Excluding forked repository", "project", r.project)
+			logger.V(2).Info("Excluding repository", "project", r.project, "reason", r.reason)
 		}
 	}
-
-	removed := len(projects) - len(filtered)
-	if removed > 0 {
-		logger.Info("Filtered forked repositories", "removed", removed, "remaining", len(filtered))
-	}
-	return filtered, nil
+	return filtered
 }
 
 // readToken reads the platform API token from the Kubernetes secret referenced
 // by the RenovateJob. It checks common key names used by Renovate.
 func readToken(ctx context.Context, c client.Client, job *api.RenovateJob) (string, error) {
 	if job.Spec.SecretRef == "" {
-		return "", fmt.Errorf("secretRef must be set when skipForks is enabled")
+		return "", fmt.Errorf("secretRef must be set to use the platform API")
 	}
 
 	secret := &corev1.Secret{}
@@ -17,13 +17,23 @@ import (
 
 // fakeClient is a test double for GitProviderClient.
 type fakeClient struct {
-	isForkFn func(ctx context.Context, project string) (bool, error)
+	isForkFn           func(ctx context.Context, project string) (bool, error)
+	listRepositoriesFn func(ctx context.Context, topics []string) ([]string, error)
+	metadataFn         func(ctx context.Context, project string) (*RepositoryMetadata, error)
 }
 
 func (f *fakeClient) IsFork(ctx context.Context, project string) (bool, error) {
 	return f.isForkFn(ctx, project)
 }
 
+func (f *fakeClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	return f.listRepositoriesFn(ctx, topics)
+}
+
+func (f *fakeClient) GetRepositoryMetadata(ctx context.Context, project string) (*RepositoryMetadata, error) {
+	return f.metadataFn(ctx, project)
+}
+
 func newTestJob(platform, endpoint, secretRef, namespace string, skipForks bool) *api.RenovateJob {
 	return &api.RenovateJob{
 		ObjectMeta: metav1.ObjectMeta{
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 )
 
 // GiteaClient implements GitProviderClient for the Gitea and Forgejo APIs.
@@ -44,3 +45,68 @@ func (c *GiteaClient) IsFork(ctx context.Context, project string) (bool, error)
 	}
 	return repo.Fork, nil
 }
+
+func (c *GiteaClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	headers := map[string]string{
+		"Authorization": "token " + c.token,
+		"Accept":        "application/json",
+	}
+
+	// only repositories the user of the token is a member of are discovered
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if _, err := getJSON(ctx, c.httpClient, "gitea", c.endpoint+"/api/v1/user", headers, &user); err != nil {
+		return nil, err
+	}
+
+	const limit = 50
+	var projects []string
+	for page := 1; ; page++ {
+		if page > maxRepositoryPages {
+			return nil, fmt.Errorf("gitea API returned more than %d pages of repositories", maxRepositoryPages)
+		}
+		var result struct {
+			Data []struct {
+				FullName string   `json:"full_name"`
+				Archived bool     `json:"archived"`
+				Topics   []string `json:"topics"`
+			} `json:"data"`
+		}
+		url := fmt.Sprintf("%s/api/v1/repos/search?uid=%d&archived=false&limit=%d&page=%d", c.endpoint, user.ID, limit, page)
+		if _, err := getJSON(ctx, c.httpClient, "gitea", url, headers, &result); err != nil {
+			return nil, err
+		}
+		for _, repo := range result.Data {
+			if !repo.Archived && hasAnyTopic(repo.Topics, topics) {
+				projects = append(projects, repo.FullName)
+			}
+		}
+		if len(result.Data) < limit {
+			return projects, nil
+		}
+	}
+}
+
+func (c *GiteaClient) GetRepositoryMetadata(ctx context.Context, project string) (*RepositoryMetadata, error) {
+	headers := map[string]string{
+		"Authorization": "token " + c.token,
+		"Accept":        "application/json",
+	}
+	var repo struct {
+		Archived      bool      `json:"archived"`
+		Empty         bool      `json:"empty"`
+		UpdatedAt     time.Time `json:"updated_at"`
+		DefaultBranch string    `json:"default_branch"`
+	}
+	if _, err := getJSON(ctx, c.httpClient, "gitea", fmt.Sprintf("%s/api/v1/repos/%s", c.endpoint, project), headers, &repo); err != nil {
+		return nil, err
+	}
+	// gitea and forgejo do not report the last push, updated_at is changed by pushes
+	return &RepositoryMetadata{
+		Archived:      repo.Archived,
+		Empty:         repo.Empty,
+		LastPush:      repo.UpdatedAt,
+		DefaultBranch: repo.DefaultBranch,
+	}, nil
+}
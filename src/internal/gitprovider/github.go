@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // GitHubClient implements GitProviderClient for the GitHub API.
@@ -43,3 +45,110 @@ func (c *GitHubClient) IsFork(ctx context.Context, project string) (bool, error)
 	}
 	return repo.Fork, nil
 }
+
+func (c *GitHubClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	type repository struct {
+		FullName string   `json:"full_name"`
+		Archived bool     `json:"archived"`
+		Topics   []string `json:"topics"`
+	}
+	headers := map[string]string{
+		"Authorization": "Bearer " + c.token,
+		"Accept":        "application/vnd.github+json",
+	}
+
+	// installation tokens of GitHub apps can not list the repositories of a user
+	installation := strings.HasPrefix(c.token, "ghs_")
+	next := c.endpoint + "/user/repos?per_page=100"
+	if installation {
+		next = c.endpoint + "/installation/repositories?per_page=100"
+	}
+
+	var projects []string
+	for page := 0; next != ""; page++ {
+		if page >= maxRepositoryPages {
+			return nil, fmt.Errorf("github API returned more than %d pages of repositories", maxRepositoryPages)
+		}
+		var repositories []repository
+		var header http.Header
+		var err error
+		if installation {
+			var body struct {
+				Repositories []repository `json:"repositories"`
+			}
+			header, err = getJSON(ctx, c.httpClient, "github", next, headers, &body)
+			repositories = body.Repositories
+		} else {
+			header, err = getJSON(ctx, c.httpClient, "github", next, headers, &repositories)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repositories {
+			if !repo.Archived && hasAnyTopic(repo.Topics, topics) {
+				projects = append(projects, repo.FullName)
+			}
+		}
+		next = nextLink(header)
+	}
+	return projects, nil
+}
+
+func (c *GitHubClient) GetRepositoryMetadata(ctx context.Context, project string) (*RepositoryMetadata, error) {
+	headers := map[string]string{
+		"Authorization": "Bearer " + c.token,
+		"Accept":        "application/vnd.github+json",
+	}
+	var repo struct {
+		Archived      bool      `json:"archived"`
+		Size          int64     `json:"size"`
+		PushedAt      time.Time `json:"pushed_at"`
+		DefaultBranch string    `json:"default_branch"`
+	}
+	if _, err := getJSON(ctx, c.httpClient, "github", fmt.Sprintf("%s/repos/%s", c.endpoint, project), headers, &repo); err != nil {
+		return nil, err
+	}
+	// github updates the size lazily, small or just pushed repositories report a size of zero as well.
+	// the size only rules out empty repositories, the commits are checked for the others
+	empty := false
+	if repo.Size == 0 {
+		var err error
+		empty, err = c.hasNoCommits(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &RepositoryMetadata{
+		Archived:      repo.Archived,
+		Empty:         empty,
+		LastPush:      repo.PushedAt,
+		DefaultBranch: repo.DefaultBranch,
+	}, nil
+}
+
+// whether the repository has no commits, github answers the commits of an empty repository with a conflict
+func (c *GitHubClient) hasNoCommits(ctx context.Context, project string) (bool, error) {
+	url := fmt.Sprintf("%s/repos/%s/commits?per_page=1", c.endpoint, project)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return false, nil
+	case http.StatusConflict:
+		return true, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("github API returned status %d for the commits of %s: %s", resp.StatusCode, project, string(body))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // GitLabClient implements GitProviderClient for the GitLab API.
@@ -44,3 +45,52 @@ func (c *GitLabClient) IsFork(ctx context.Context, project string) (bool, error)
 	}
 	return proj.ForkedFromProject != nil, nil
 }
+
+func (c *GitLabClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	headers := map[string]string{"PRIVATE-TOKEN": c.token}
+	next := c.endpoint + "/projects?membership=true&archived=false&per_page=100"
+
+	var projects []string
+	for page := 0; next != ""; page++ {
+		if page >= maxRepositoryPages {
+			return nil, fmt.Errorf("gitlab API returned more than %d pages of projects", maxRepositoryPages)
+		}
+		var repositories []struct {
+			PathWithNamespace string   `json:"path_with_namespace"`
+			Archived          bool     `json:"archived"`
+			Topics            []string `json:"topics"`
+		}
+		header, err := getJSON(ctx, c.httpClient, "gitlab", next, headers, &repositories)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repositories {
+			if !repo.Archived && hasAnyTopic(repo.Topics, topics) {
+				projects = append(projects, repo.PathWithNamespace)
+			}
+		}
+		next = nextLink(header)
+	}
+	return projects, nil
+}
+
+func (c *GitLabClient) GetRepositoryMetadata(ctx context.Context, project string) (*RepositoryMetadata, error) {
+	headers := map[string]string{"PRIVATE-TOKEN": c.token}
+	var proj struct {
+		Archived       bool      `json:"archived"`
+		EmptyRepo      bool      `json:"empty_repo"`
+		LastActivityAt time.Time `json:"last_activity_at"`
+		DefaultBranch  string    `json:"default_branch"`
+	}
+	apiURL := fmt.Sprintf("%s/projects/%s", c.endpoint, url.PathEscape(project))
+	if _, err := getJSON(ctx, c.httpClient, "gitlab", apiURL, headers, &proj); err != nil {
+		return nil, err
+	}
+	// gitlab does not report the last push, the last activity includes pushes
+	return &RepositoryMetadata{
+		Archived:      proj.Archived,
+		Empty:         proj.EmptyRepo,
+		LastPush:      proj.LastActivityAt,
+		DefaultBranch: proj.DefaultBranch,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package gitprovider
+
+import (
+	"context"
+	api "renovate-operator/api/v1alpha1"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// RepositoryMetadata describes the state of a repository on the platform.
+type RepositoryMetadata struct {
+	Archived bool
+	// Empty is true if the repository has no commits
+	Empty bool
+	// LastPush is the time of the last push, zero if the platform does not report it
+	LastPush      time.Time
+	DefaultBranch string
+}
+
+// FiltersByMetadata returns true if the RenovateJob skips projects based on their repository metadata.
+func FiltersByMetadata(spec *api.RenovateJobSpec) bool {
+	return spec.SkipArchived || spec.SkipEmpty || spec.SkipInactiveFor != nil
+}
+
+// FilterByMetadata filters archived, empty and inactive repositories from a list of discovered projects,
+// depending on the skipArchived, skipEmpty and skipInactiveFor options of the RenovateJob.
+// On API errors for individual repos, the repo is kept (fail-open) to avoid accidentally excluding valid projects.
+func FilterByMetadata(ctx context.Context, providerClient GitProviderClient, logger logr.Logger, projects []string, spec *api.RenovateJobSpec, now time.Time) ([]string, error) {
+	if !FiltersByMetadata(spec) {
+		return projects, nil
+	}
+
+	filtered := filterProjects(ctx, logger, projects, func(ctx context.Context, project string) (string, error) {
+		metadata, err := providerClient.GetRepositoryMetadata(ctx, project)
+		if err != nil {
+			return "", err
+		}
+		return metadataExclusionReason(metadata, spec, now), nil
+	})
+
+	removed := len(projects) - len(filtered)
+	if removed > 0 {
+		logger.Info("Filtered archived, empty or inactive repositories", "removed", removed, "remaining", len(filtered))
+	}
+	return filtered, nil
+}
+
+// reason for excluding a repository with the given metadata, empty if it is kept
+func metadataExclusionReason(metadata *RepositoryMetadata, spec *api.RenovateJobSpec, now time.Time) string {
+	switch {
+	case spec.SkipArchived && metadata.Archived:
+		return "archived"
+	case spec.SkipEmpty && metadata.Empty:
+		return "empty"
+	case spec.SkipInactiveFor != nil && !metadata.LastPush.IsZero() && now.Sub(metadata.LastPush) > spec.SkipInactiveFor.Duration:
+		return "inactive"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,183 @@
+package gitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	api "renovate-operator/api/v1alpha1"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFilterByMetadata(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	metadata := map[string]*RepositoryMetadata{
+		"org/active":   {LastPush: now.Add(-24 * time.Hour)},
+		"org/archived": {Archived: true, LastPush: now.Add(-24 * time.Hour)},
+		"org/empty":    {Empty: true},
+		"org/inactive": {LastPush: now.Add(-400 * 24 * time.Hour)},
+	}
+	fc := &fakeClient{
+		metadataFn: func(ctx context.Context, project string) (*RepositoryMetadata, error) {
+			if m, ok := metadata[project]; ok {
+				return m, nil
+			}
+			return nil, fmt.Errorf("not found")
+		},
+	}
+	projects := []string{"org/active", "org/archived", "org/empty", "org/inactive", "org/unknown"}
+
+	tests := []struct {
+		name     string
+		spec     api.RenovateJobSpec
+		expected []string
+	}{
+		{name: "no options", spec: api.RenovateJobSpec{}, expected: projects},
+		{name: "skip archived", spec: api.RenovateJobSpec{SkipArchived: true}, expected: []string{"org/active", "org/empty", "org/inactive", "org/unknown"}},
+		{name: "skip empty", spec: api.RenovateJobSpec{SkipEmpty: true}, expected: []string{"org/active", "org/archived", "org/inactive", "org/unknown"}},
+		{
+			name:     "skip inactive",
+			spec:     api.RenovateJobSpec{SkipInactiveFor: &metav1.Duration{Duration: 180 * 24 * time.Hour}},
+			expected: []string{"org/active", "org/archived", "org/empty", "org/unknown"},
+		},
+		{
+			name:     "all options",
+			spec:     api.RenovateJobSpec{SkipArchived: true, SkipEmpty: true, SkipInactiveFor: &metav1.Duration{Duration: 180 * 24 * time.Hour}},
+			expected: []string{"org/active", "org/unknown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FilterByMetadata(context.Background(), fc, logr.Discard(), projects, &tt.spec, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetRepositoryMetadata(t *testing.T) {
+	pushed := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		path     string
+		response map[string]any
+		client   func(endpoint string) GitProviderClient
+		expected RepositoryMetadata
+	}{
+		{
+			name:     "github",
+			path:     "/repos/org/repo",
+			response: map[string]any{"archived": true, "size": 42, "pushed_at": pushed, "default_branch": "main"},
+			client: func(endpoint string) GitProviderClient {
+				return &GitHubClient{endpoint: endpoint, token: "test-token", httpClient: http.DefaultClient}
+			},
+			expected: RepositoryMetadata{Archived: true, LastPush: pushed, DefaultBranch: "main"},
+		},
+		{
+			name:     "gitlab",
+			path:     "/projects/org/repo",
+			response: map[string]any{"archived": false, "empty_repo": false, "last_activity_at": pushed, "default_branch": "master"},
+			client: func(endpoint string) GitProviderClient {
+				return &GitLabClient{endpoint: endpoint, token: "test-token", httpClient: http.DefaultClient}
+			},
+			expected: RepositoryMetadata{LastPush: pushed, DefaultBranch: "master"},
+		},
+		{
+			name:     "gitea",
+			path:     "/api/v1/repos/org/repo",
+			response: map[string]any{"archived": true, "empty": true, "updated_at": pushed, "default_branch": "main"},
+			client: func(endpoint string) GitProviderClient {
+				return &GiteaClient{endpoint: endpoint, token: "test-token", httpClient: http.DefaultClient}
+			},
+			expected: RepositoryMetadata{Archived: true, Empty: true, LastPush: pushed, DefaultBranch: "main"},
+		},
+		{
+			name:     "bitbucket",
+			path:     "/2.0/repositories/org/repo",
+			response: map[string]any{"updated_on": pushed, "mainbranch": map[string]any{"name": "develop"}},
+			client: func(endpoint string) GitProviderClient {
+				return &BitbucketClient{endpoint: endpoint, token: "test-token", httpClient: http.DefaultClient}
+			},
+			expected: RepositoryMetadata{LastPush: pushed, DefaultBranch: "develop"},
+		},
+		{
+			name:     "empty bitbucket repository",
+			path:     "/2.0/repositories/org/repo",
+			response: map[string]any{"updated_on": pushed, "mainbranch": nil},
+			client: func(endpoint string) GitProviderClient {
+				return &BitbucketClient{endpoint: endpoint, token: "test-token", httpClient: http.DefaultClient}
+			},
+			expected: RepositoryMetadata{Empty: true, LastPush: pushed},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != tt.path && r.URL.Path != tt.path {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			metadata, err := tt.client(server.URL).GetRepositoryMetadata(context.Background(), "org/repo")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !metadata.LastPush.Equal(tt.expected.LastPush) {
+				t.Errorf("expected last push %v, got %v", tt.expected.LastPush, metadata.LastPush)
+			}
+			metadata.LastPush = tt.expected.LastPush
+			if *metadata != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, *metadata)
+			}
+		})
+	}
+}
+
+// github reports a size of zero for small or just pushed repositories, only repositories without commits are empty
+func TestGetRepositoryMetadata_GitHubEmpty(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/org/", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"size": 0, "default_branch": "main"})
+	})
+	mux.HandleFunc("/repos/org/small/commits", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{{"sha": "abc"}})
+	})
+	mux.HandleFunc("/repos/org/empty/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]any{"message": "Git Repository is empty."})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &GitHubClient{endpoint: server.URL, token: "test-token", httpClient: http.DefaultClient}
+	small, err := client.GetRepositoryMetadata(context.Background(), "org/small")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if small.Empty {
+		t.Error("expected a repository with commits not to be empty")
+	}
+	empty, err := client.GetRepositoryMetadata(context.Background(), "org/empty")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !empty.Empty {
+		t.Error("expected a repository without commits to be empty")
+	}
+}
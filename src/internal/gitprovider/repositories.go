@@ -0,0 +1,159 @@
+package gitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/utils"
+	"slices"
+	"strings"
+)
+
+// maximum number of pages requested while listing repositories, protects against endless pagination
+const maxRepositoryPages = 1000
+
+// DiscoverProjects lists the repositories matching the discoveryFilter and discoverTopics of the
+// RenovateJob through the platform API, following the autodiscover semantics of Renovate.
+func DiscoverProjects(ctx context.Context, providerClient GitProviderClient, job *api.RenovateJob) ([]string, error) {
+	repositories, err := providerClient.ListRepositories(ctx, splitList(job.Spec.DiscoverTopics))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	return filterDiscoveredProjects(repositories, splitList(job.Spec.DiscoveryFilter))
+}
+
+// MatchesDiscovery returns true if a single repository would be discovered by the RenovateJob, i.e. it passes
+// the discoveryFilter and projectFilters and has one of the discoverTopics. Topics are only checked if known.
+func MatchesDiscovery(job *api.RenovateJob, project string, topics []string) (bool, error) {
+	if topics != nil && !hasAnyTopic(topics, splitList(job.Spec.DiscoverTopics)) {
+		return false, nil
+	}
+	projects, err := filterDiscoveredProjects([]string{project}, splitList(job.Spec.DiscoveryFilter))
+	if err != nil {
+		return false, err
+	}
+	projects, err = utils.ApplyProjectFilters(projects, job.Spec.ProjectFilters)
+	if err != nil {
+		return false, err
+	}
+	return len(projects) == 1, nil
+}
+
+// filterDiscoveredProjects keeps the repositories matching at least one of the filters.
+// Filters are globs or regular expressions enclosed in slashes, both can be negated with a leading "!".
+func filterDiscoveredProjects(repositories []string, filters []string) ([]string, error) {
+	matchers := make([]func(string) bool, 0, len(filters))
+	for _, filter := range filters {
+		matcher, err := compileDiscoveryFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	projects := make([]string, 0, len(repositories))
+	for _, repository := range repositories {
+		if len(matchers) == 0 || slices.ContainsFunc(matchers, func(match func(string) bool) bool { return match(repository) }) {
+			projects = append(projects, repository)
+		}
+	}
+	slices.Sort(projects)
+	return slices.Compact(projects), nil
+}
+
+func compileDiscoveryFilter(filter string) (func(string) bool, error) {
+	negated := strings.HasPrefix(filter, "!")
+	expr := strings.TrimPrefix(filter, "!")
+	if len(expr) > 2 && strings.HasPrefix(expr, "/") && (strings.HasSuffix(expr, "/") || strings.HasSuffix(expr, "/i")) {
+		flags := ""
+		if strings.HasSuffix(expr, "/i") {
+			flags = "(?i)"
+			expr = strings.TrimSuffix(expr, "i")
+		}
+		regex, err := regexp.Compile(flags + expr[1:len(expr)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid discovery filter %q: %w", filter, err)
+		}
+		return func(name string) bool { return regex.MatchString(name) != negated }, nil
+	}
+
+	glob, err := utils.CompileGlob(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery filter %q: %w", filter, err)
+	}
+	return func(name string) bool { return glob.MatchString(name) != negated }, nil
+}
+
+// splitList splits a list given as comma separated values or as JSON array, like Renovate does for environment variables
+func splitList(value string) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	var values []string
+	if strings.HasPrefix(value, "[") && json.Unmarshal([]byte(value), &values) == nil {
+		return values
+	}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// hasAnyTopic returns true if no topics are requested or the repository has at least one of them
+func hasAnyTopic(repositoryTopics []string, topics []string) bool {
+	if len(topics) == 0 {
+		return true
+	}
+	for _, topic := range topics {
+		if slices.ContainsFunc(repositoryTopics, func(t string) bool { return strings.EqualFold(t, topic) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// getJSON requests the given URL and decodes the JSON response into out.
+// The response headers are returned for pagination.
+func getJSON(ctx context.Context, httpClient *http.Client, platform string, url string, headers map[string]string, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s API returned status %d for %s: %s", platform, resp.StatusCode, url, string(body))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, fmt.Errorf("failed to decode %s API response for %s: %w", platform, url, err)
+	}
+	return resp.Header, nil
+}
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextLink returns the URL of the next page from the Link header, empty on the last page
+func nextLink(header http.Header) string {
+	for _, link := range header.Values("Link") {
+		if match := linkNextPattern.FindStringSubmatch(link); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
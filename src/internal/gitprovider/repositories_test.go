@@ -0,0 +1,272 @@
+package gitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	api "renovate-operator/api/v1alpha1"
+	"slices"
+	"testing"
+)
+
+func TestFilterDiscoveredProjects(t *testing.T) {
+	repositories := []string{"org/api", "org/web", "org/group/worker", "other/api"}
+
+	tests := []struct {
+		name     string
+		filters  []string
+		expected []string
+	}{
+		{name: "no filter", filters: nil, expected: []string{"org/api", "org/group/worker", "org/web", "other/api"}},
+		{name: "glob", filters: []string{"org/*"}, expected: []string{"org/api", "org/web"}},
+		{name: "recursive glob", filters: []string{"org/**"}, expected: []string{"org/api", "org/group/worker", "org/web"}},
+		{name: "multiple globs", filters: []string{"org/web", "other/*"}, expected: []string{"org/web", "other/api"}},
+		{name: "regex", filters: []string{"/api$/"}, expected: []string{"org/api", "other/api"}},
+		{name: "case insensitive regex", filters: []string{"/^ORG/WEB$/i"}, expected: []string{"org/web"}},
+		{name: "negated regex", filters: []string{"!/^org/"}, expected: []string{"other/api"}},
+		{name: "negated glob", filters: []string{"!org/**"}, expected: []string{"other/api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := filterDiscoveredProjects(repositories, tt.filters)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterDiscoveredProjects_InvalidFilter(t *testing.T) {
+	if _, err := filterDiscoveredProjects([]string{"org/api"}, []string{"/[a-/"}); err == nil {
+		t.Fatal("expected an error for an invalid regex")
+	}
+}
+
+func TestMatchesDiscovery(t *testing.T) {
+	job := newTestJob("github", "", "secret", "default", false)
+	job.Spec.DiscoveryFilter = "org/*"
+	job.Spec.DiscoverTopics = "renovate"
+	job.Spec.ProjectFilters = []api.RenovateProjectFilter{{Action: api.ProjectFilterExclude, Glob: "org/legacy-*"}}
+
+	tests := []struct {
+		project  string
+		topics   []string
+		expected bool
+	}{
+		{project: "org/api", topics: []string{"renovate"}, expected: true},
+		{project: "org/api", topics: nil, expected: true},
+		{project: "org/api", topics: []string{}, expected: false},
+		{project: "other/api", topics: []string{"renovate"}, expected: false},
+		{project: "org/legacy-api", topics: []string{"renovate"}, expected: false},
+	}
+	for _, tt := range tests {
+		got, err := MatchesDiscovery(job, tt.project, tt.topics)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.expected {
+			t.Errorf("MatchesDiscovery(%q, %v) = %v, want %v", tt.project, tt.topics, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []string
+	}{
+		{value: "", expected: nil},
+		{value: "org/*", expected: []string{"org/*"}},
+		{value: "org/*, other/*", expected: []string{"org/*", "other/*"}},
+		{value: `["org/*","other/*"]`, expected: []string{"org/*", "other/*"}},
+	}
+	for _, tt := range tests {
+		if got := splitList(tt.value); !slices.Equal(got, tt.expected) {
+			t.Errorf("splitList(%q) = %v, want %v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestDiscoverProjects(t *testing.T) {
+	var requestedTopics []string
+	fc := &fakeClient{
+		listRepositoriesFn: func(ctx context.Context, topics []string) ([]string, error) {
+			requestedTopics = topics
+			return []string{"org/b", "org/a", "other/c"}, nil
+		},
+	}
+	job := newTestJob("github", "", "secret", "default", false)
+	job.Spec.DiscoveryFilter = "org/*"
+	job.Spec.DiscoverTopics = "renovate,deps"
+
+	projects, err := DiscoverProjects(context.Background(), fc, job)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(projects, []string{"org/a", "org/b"}) {
+		t.Fatalf("unexpected projects: %v", projects)
+	}
+	if !slices.Equal(requestedTopics, []string{"renovate", "deps"}) {
+		t.Fatalf("unexpected topics: %v", requestedTopics)
+	}
+}
+
+func TestListGitHubRepositories(t *testing.T) {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/user/repos?per_page=100&page=2>; rel="next", <%s/user/repos?per_page=100&page=2>; rel="last"`, server.URL, server.URL))
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"full_name": "org/repo1", "topics": []string{"renovate"}},
+				{"full_name": "org/archived", "archived": true, "topics": []string{"renovate"}},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"full_name": "org/repo2", "topics": []string{"other"}},
+			{"full_name": "org/repo3", "topics": []string{"Renovate"}},
+		})
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &GitHubClient{endpoint: server.URL, token: "test-token", httpClient: http.DefaultClient}
+	projects, err := client.ListRepositories(context.Background(), []string{"renovate"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(projects, []string{"org/repo1", "org/repo3"}) {
+		t.Fatalf("unexpected projects: %v", projects)
+	}
+}
+
+func TestListGitHubRepositories_Installation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/installation/repositories", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"repositories": []map[string]any{{"full_name": "org/repo1"}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &GitHubClient{endpoint: server.URL, token: "ghs_installation", httpClient: http.DefaultClient}
+	projects, err := client.ListRepositories(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(projects, []string{"org/repo1"}) {
+		t.Fatalf("unexpected projects: %v", projects)
+	}
+}
+
+func TestListGitLabRepositories(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("archived") != "false" {
+			t.Errorf("expected archived projects to be excluded")
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"path_with_namespace": "group/sub/repo1"},
+			{"path_with_namespace": "group/repo2"},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &GitLabClient{endpoint: server.URL, token: "test-token", httpClient: http.DefaultClient}
+	projects, err := client.ListRepositories(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(projects, []string{"group/sub/repo1", "group/repo2"}) {
+		t.Fatalf("unexpected projects: %v", projects)
+	}
+}
+
+func TestListGiteaRepositories(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42})
+	})
+	mux.HandleFunc("/api/v1/repos/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("uid") != "42" {
+			t.Errorf("expected repositories of user 42, got %s", r.URL.Query().Get("uid"))
+		}
+		data := []map[string]any{}
+		if r.URL.Query().Get("page") == "1" {
+			for i := range 50 {
+				data = append(data, map[string]any{"full_name": fmt.Sprintf("org/repo%d", i)})
+			}
+		} else {
+			data = append(data, map[string]any{"full_name": "org/last"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": data})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &GiteaClient{endpoint: server.URL, token: "test-token", httpClient: http.DefaultClient}
+	projects, err := client.ListRepositories(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 51 || projects[50] != "org/last" {
+		t.Fatalf("expected 51 projects, got %d: %v", len(projects), projects)
+	}
+}
+
+func TestListBitbucketRepositories(t *testing.T) {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.0/repositories", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"values": []map[string]any{{"full_name": "workspace/repo1"}},
+				"next":   server.URL + "/2.0/repositories?role=contributor&pagelen=100&page=2",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"values": []map[string]any{{"full_name": "workspace/repo2"}},
+		})
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	client := &BitbucketClient{endpoint: server.URL, token: "test-token", httpClient: http.DefaultClient}
+	projects, err := client.ListRepositories(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(projects, []string{"workspace/repo1", "workspace/repo2"}) {
+		t.Fatalf("unexpected projects: %v", projects)
+	}
+
+	if _, err := client.ListRepositories(context.Background(), []string{"renovate"}); err == nil {
+		t.Fatal("expected an error when discovering by topics")
+	}
+}
+
+func TestListRepositories_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{endpoint: server.URL, token: "expired", httpClient: http.DefaultClient}
+	if _, err := client.ListRepositories(context.Background(), nil); err == nil {
+		t.Fatal("expected an error for an unauthorized request")
+	}
+}
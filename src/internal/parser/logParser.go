@@ -3,6 +3,7 @@ package parser
 import (
 	"bufio"
 	"encoding/json"
+	"slices"
 	"strings"
 
 	"k8s.io/utils/ptr"
@@ -14,10 +15,21 @@ type LogParseResult struct {
 	RenovateResultStatus *string // nil = unknown, true = config found, false = no config (onboarding detected)
 }
 
+// RepositoryLogParseResult contains the result of parsing the logs of a single repository of a renovate run
+type RepositoryLogParseResult struct {
+	LogParseResult
+	Finished bool // true if renovate logged that it finished the repository
+	Failed   bool // true if the repository finished with an error
+}
+
+// results of finished repositories that count as a failed run
+var failedResults = []string{"error", "external-host-error"}
+
 // renovateLogEntry represents a single line in Renovate's JSON log output
 type renovateLogEntry struct {
-	Level int    `json:"level"`
-	Msg   string `json:"msg"`
+	Level      int    `json:"level"`
+	Msg        string `json:"msg"`
+	Repository string `json:"repository,omitempty"`
 }
 
 type repositoryFinishedEntry struct {
@@ -54,34 +66,140 @@ func ParseRenovateLogs(logs string) *LogParseResult {
 			// Line is not valid JSON, skip it
 			continue
 		}
+		parseLogEntry(result, &entry, line)
+	}
+
+	return result
+}
+
+/*
+ParseRenovateLogsByRepository parses the logs of a renovate run processing several repositories.
+Entries are assigned to a repository by their "repository" field, entries without it are ignored.
+Repositories that did not log anything are missing in the result.
+*/
+func ParseRenovateLogsByRepository(logs string) map[string]*RepositoryLogParseResult {
+	results := map[string]*RepositoryLogParseResult{}
 
-		// Renovate log levels: 10=trace, 20=debug, 30=info, 40=warn, 50=error, 60=fatal
-		if entry.Level >= 40 {
-			result.HasIssues = true
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB initial, 1MB max
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
 		}
 
-		// Parse the "Repository finished" line which has the definitive status
+		var entry renovateLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Repository == "" {
+			continue
+		}
+		result, ok := results[entry.Repository]
+		if !ok {
+			result = &RepositoryLogParseResult{}
+			results[entry.Repository] = result
+		}
+		parseLogEntry(&result.LogParseResult, &entry, line)
 		if entry.Msg == "Repository finished" {
+			result.Finished = true
 			var finished repositoryFinishedEntry
 			if err := json.Unmarshal([]byte(line), &finished); err == nil {
-				switch finished.Result {
-				case "disabled-by-config":
-					result.RenovateResultStatus = ptr.To("Disabled")
-				case "disabled-closed-onboarding":
-					result.RenovateResultStatus = ptr.To("Onboarding Closed")
-				case "disabled-no-config":
-					result.RenovateResultStatus = ptr.To("No Config")
-				default:
-					if finished.Result == "" {
-						result.RenovateResultStatus = ptr.To("Unknown")
-					} else {
-						result.RenovateResultStatus = ptr.To(finished.Result)
-					}
-				}
+				result.Failed = slices.Contains(failedResults, finished.Result)
+			}
+		}
+	}
+
+	return results
+}
+
+// FilterRepositoryLogs keeps the log lines of the given repository and the lines not belonging to any repository
+func FilterRepositoryLogs(logs string, repository string) string {
+	var filtered strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB initial, 1MB max
+	for scanner.Scan() {
+		line := scanner.Text()
+		var entry renovateLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err == nil && entry.Repository != "" && entry.Repository != repository {
+			continue
+		}
+		filtered.WriteString(line)
+		filtered.WriteString("\n")
+	}
+	return filtered.String()
+}
+
+func parseLogEntry(result *LogParseResult, entry *renovateLogEntry, line string) {
+	// Renovate log levels: 10=trace, 20=debug, 30=info, 40=warn, 50=error, 60=fatal
+	if entry.Level >= 40 {
+		result.HasIssues = true
+	}
 
+	// Parse the "Repository finished" line which has the definitive status
+	if entry.Msg == "Repository finished" {
+		var finished repositoryFinishedEntry
+		if err := json.Unmarshal([]byte(line), &finished); err == nil {
+			switch finished.Result {
+			case "disabled-by-config":
+				result.RenovateResultStatus = ptr.To("Disabled")
+			case "disabled-closed-onboarding":
+				result.RenovateResultStatus = ptr.To("Onboarding Closed")
+			case "disabled-no-config":
+				result.RenovateResultStatus = ptr.To("No Config")
+			default:
+				if finished.Result == "" {
+					result.RenovateResultStatus = ptr.To("Unknown")
+				} else {
+					result.RenovateResultStatus = ptr.To(finished.Result)
+				}
 			}
+
 		}
 	}
+}
 
-	return result
+// validation messages logged by renovate-config-validator
+type configValidatorEntry struct {
+	Errors   []configValidationMessage `json:"errors,omitempty"`
+	Warnings []configValidationMessage `json:"warnings,omitempty"`
+}
+
+type configValidationMessage struct {
+	Topic   string `json:"topic,omitempty"`
+	Message string `json:"message"`
+}
+
+/*
+ParseConfigValidatorLogs extracts the errors and warnings from the JSON logs of renovate-config-validator.
+The validation messages logged with an entry are returned instead of the message of the entry itself.
+*/
+func ParseConfigValidatorLogs(logs string) (errorMessages []string, warningMessages []string) {
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 64KB initial, 1MB max
+	for scanner.Scan() {
+		line := scanner.Text()
+		var entry renovateLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil || entry.Level < 40 {
+			continue
+		}
+		var validation configValidatorEntry
+		_ = json.Unmarshal([]byte(line), &validation)
+
+		messages := []string{}
+		for _, message := range append(validation.Errors, validation.Warnings...) {
+			if message.Topic != "" {
+				messages = append(messages, message.Topic+": "+message.Message)
+			} else {
+				messages = append(messages, message.Message)
+			}
+		}
+		if len(messages) == 0 {
+			messages = append(messages, entry.Msg)
+		}
+
+		if entry.Level >= 50 {
+			errorMessages = append(errorMessages, messages...)
+		} else {
+			warningMessages = append(warningMessages, messages...)
+		}
+	}
+	return errorMessages, warningMessages
 }
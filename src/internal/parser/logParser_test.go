@@ -180,3 +180,77 @@ func TestParseRenovateLogsConfigDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRenovateLogsByRepository(t *testing.T) {
+	logs := strings.Join([]string{
+		`{"level":30,"msg":"Renovate started"}`,
+		`{"level":40,"msg":"Global warning"}`,
+		`{"level":30,"repository":"org/a","msg":"Repository started"}`,
+		`{"level":30,"repository":"org/a","result":"done","msg":"Repository finished"}`,
+		`{"level":30,"repository":"org/b","msg":"Repository started"}`,
+		`{"level":40,"repository":"org/b","msg":"Dependency lookup failed"}`,
+		`{"level":30,"repository":"org/b","result":"disabled-no-config","msg":"Repository finished"}`,
+		`{"level":30,"repository":"org/c","msg":"Repository started"}`,
+		`{"level":50,"repository":"org/c","result":"error","msg":"Repository finished"}`,
+		`{"level":30,"repository":"org/d","msg":"Repository started"}`,
+		"not json",
+	}, "\n")
+
+	results := ParseRenovateLogsByRepository(logs)
+	if len(results) != 4 {
+		t.Fatalf("expected results for 4 repositories, got %d", len(results))
+	}
+
+	a := results["org/a"]
+	if !a.Finished || a.Failed || a.HasIssues || *a.RenovateResultStatus != "done" {
+		t.Errorf("unexpected result for org/a: %+v", a)
+	}
+	b := results["org/b"]
+	if !b.Finished || b.Failed || !b.HasIssues || *b.RenovateResultStatus != "No Config" {
+		t.Errorf("unexpected result for org/b: %+v", b)
+	}
+	c := results["org/c"]
+	if !c.Finished || !c.Failed {
+		t.Errorf("expected org/c to have failed: %+v", c)
+	}
+	if d := results["org/d"]; d.Finished {
+		t.Errorf("expected org/d not to be finished: %+v", d)
+	}
+}
+
+func TestFilterRepositoryLogs(t *testing.T) {
+	logs := strings.Join([]string{
+		`{"level":30,"msg":"Renovate started"}`,
+		`{"level":30,"repository":"org/a","msg":"Repository started"}`,
+		`{"level":30,"repository":"org/b","msg":"Repository started"}`,
+		"plain output",
+	}, "\n")
+
+	expected := `{"level":30,"msg":"Renovate started"}` + "\n" + `{"level":30,"repository":"org/a","msg":"Repository started"}` + "\n" + "plain output\n"
+	if got := FilterRepositoryLogs(logs, "org/a"); got != expected {
+		t.Errorf("FilterRepositoryLogs() = %q, want %q", got, expected)
+	}
+}
+
+func TestParseConfigValidatorLogs(t *testing.T) {
+	logs := strings.Join([]string{
+		`{"level":30,"msg":"Validating /etc/renovate/config.json"}`,
+		`{"level":40,"msg":"Config validation warnings found","warnings":[{"topic":"Deprecation Warning","message":"The \"endpoint\" option is deprecated"}]}`,
+		`{"level":50,"msg":"Found errors in configuration","errors":[{"topic":"Configuration Error","message":"Invalid configuration option: prHourlyLimt"}]}`,
+		`{"level":50,"msg":"/etc/renovate/config.json contains errors"}`,
+		"not json",
+	}, "\n")
+
+	errs, warnings := ParseConfigValidatorLogs(logs)
+	expectedErrors := []string{"Configuration Error: Invalid configuration option: prHourlyLimt", "/etc/renovate/config.json contains errors"}
+	if strings.Join(errs, "|") != strings.Join(expectedErrors, "|") {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	if len(warnings) != 1 || warnings[0] != `Deprecation Warning: The "endpoint" option is deprecated` {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	if errs, warnings := ParseConfigValidatorLogs(`{"level":30,"msg":"Config validated successfully"}`); errs != nil || warnings != nil {
+		t.Errorf("expected no messages, got %v and %v", errs, warnings)
+	}
+}
@@ -0,0 +1,176 @@
+package renovate
+
+import (
+	context "context"
+	"fmt"
+	api "renovate-operator/api/v1alpha1"
+	crdManager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/parser"
+	"renovate-operator/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// a validator job failing without reporting errors, e.g. by a timeout, is retried after this interval
+const validationRetryInterval = 10 * time.Minute
+
+// maximum number of validation messages shown in the ConfigValid condition
+const validationMessageLimit = 5
+
+/*
+ConfigValidator validates the renovate config of a RenovateJob CRD with renovate-config-validator.
+*/
+type ConfigValidator interface {
+	// Validate starts a validator job if the config of the given RenovateJob CRD changed and records the
+	// result of a finished validator job as ConfigValid condition. It does not wait for the validator job.
+	// A failed validation is retried later, the returned duration tells when to call Validate again.
+	Validate(ctx context.Context, job *api.RenovateJob) (time.Duration, error)
+}
+
+type configValidator struct {
+	client  client.Client
+	manager crdManager.RenovateJobManager
+	logger  logr.Logger
+	scheme  *runtime.Scheme
+	// allow tests to override how logs are read
+	getJobLogsFn func(ctx context.Context, job *batchv1.Job) (string, error)
+}
+
+func NewConfigValidator(scheme *runtime.Scheme, manager crdManager.RenovateJobManager, client client.Client, logger logr.Logger) ConfigValidator {
+	return &configValidator{
+		client:       client,
+		manager:      manager,
+		logger:       logger,
+		scheme:       scheme,
+		getJobLogsFn: getJobLogs,
+	}
+}
+
+func (v *configValidator) Validate(ctx context.Context, renovateJob *api.RenovateJob) (time.Duration, error) {
+	jobId := crdManager.RenovateJobIdentifier{
+		Name:      renovateJob.Name,
+		Namespace: renovateJob.Namespace,
+	}
+	if renovateJob.Spec.ConfigValidation == nil {
+		return 0, v.manager.UpdateConfigValidation(ctx, jobId, "", nil)
+	}
+
+	fingerprint, err := crdManager.ConfigFingerprint(ctx, v.client, renovateJob)
+	if err != nil {
+		return 0, v.manager.UpdateConfigValidation(ctx, jobId, "", &v1.Condition{
+			Type:    api.ConditionConfigValid,
+			Status:  v1.ConditionFalse,
+			Reason:  crdManager.ConfigValidationReasonUnavailable,
+			Message: fmt.Sprintf("The renovate config cannot be read: %v", err),
+		})
+	}
+
+	condition := meta.FindStatusCondition(renovateJob.Status.Conditions, api.ConditionConfigValid)
+	recorded := condition != nil && condition.Status != v1.ConditionUnknown && renovateJob.Status.ValidatedConfigHash == fingerprint
+	if recorded && condition.Reason != crdManager.ConfigValidationReasonFailed {
+		return 0, nil
+	}
+	if recorded && time.Since(condition.LastTransitionTime.Time) < validationRetryInterval {
+		return validationRetryInterval - time.Since(condition.LastTransitionTime.Time), nil
+	}
+
+	validatorJob, err := crdManager.GetJobByLabel(ctx, v.client, crdManager.JobSelector{
+		JobName:   utils.ValidatorJobName(renovateJob),
+		JobType:   crdManager.ValidatorJobType,
+		Namespace: renovateJob.Namespace,
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		return 0, fmt.Errorf("failed to get validator job: %w", err)
+	}
+	if err == nil && validatorJob.Annotations[crdManager.JOB_ANNOTATION_CONFIG_FINGERPRINT] == fingerprint {
+		status, _, _ := getJobStatus(validatorJob)
+		if status == api.JobStatusRunning {
+			return 0, v.manager.UpdateConfigValidation(ctx, jobId, fingerprint, validatingCondition(validatorJob.Name))
+		}
+		if !recorded {
+			result := v.getValidationResult(ctx, validatorJob, status)
+			if err := v.manager.UpdateConfigValidation(ctx, jobId, fingerprint, result); err != nil {
+				return 0, err
+			}
+			if result.Reason == crdManager.ConfigValidationReasonFailed {
+				return validationRetryInterval, nil
+			}
+			return 0, nil
+		}
+	}
+
+	// the config changed or the last validation is retried
+	validatorJob = newValidatorJob(renovateJob, fingerprint)
+	if err := controllerutil.SetControllerReference(renovateJob, validatorJob, v.scheme); err != nil {
+		return 0, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+	if _, err := crdManager.CreateJobWithGeneration(ctx, v.client, validatorJob, crdManager.JobSelector{
+		JobName:   utils.ValidatorJobName(renovateJob),
+		JobType:   crdManager.ValidatorJobType,
+		Namespace: renovateJob.Namespace,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to create validator job: %w", err)
+	}
+	v.logger.V(2).Info("Validating renovate config", "job", renovateJob.Fullname(), "fingerprint", fingerprint)
+	return 0, v.manager.UpdateConfigValidation(ctx, jobId, fingerprint, validatingCondition(validatorJob.Name))
+}
+
+func validatingCondition(jobName string) *v1.Condition {
+	return &v1.Condition{
+		Type:    api.ConditionConfigValid,
+		Status:  v1.ConditionUnknown,
+		Reason:  crdManager.ConfigValidationReasonValidating,
+		Message: fmt.Sprintf("The renovate config is validated by job %s", jobName),
+	}
+}
+
+// ConfigValid condition from the logs of a finished validator job
+func (v *configValidator) getValidationResult(ctx context.Context, validatorJob *batchv1.Job, status api.RenovateProjectStatus) *v1.Condition {
+	condition := &v1.Condition{Type: api.ConditionConfigValid}
+	logs, err := v.getJobLogsFn(ctx, validatorJob)
+	if err != nil {
+		v.logger.Error(err, "failed to get logs of validator job", "job", validatorJob.Name)
+	}
+	errorMessages, warningMessages := parser.ParseConfigValidatorLogs(logs)
+
+	switch {
+	case status == api.JobStatusCompleted:
+		condition.Status = v1.ConditionTrue
+		condition.Reason = crdManager.ConfigValidationReasonValid
+		condition.Message = "The renovate config is valid"
+		if len(warningMessages) > 0 {
+			condition.Message += fmt.Sprintf(" with %d warnings: %s", len(warningMessages), joinValidationMessages(warningMessages))
+		}
+	case len(errorMessages) > 0:
+		condition.Status = v1.ConditionFalse
+		condition.Reason = crdManager.ConfigValidationReasonInvalid
+		condition.Message = fmt.Sprintf("The renovate config has %d errors: %s", len(errorMessages), joinValidationMessages(errorMessages))
+	default:
+		condition.Status = v1.ConditionFalse
+		condition.Reason = crdManager.ConfigValidationReasonFailed
+		condition.Message = fmt.Sprintf("Validator job %s failed without reporting errors", validatorJob.Name)
+		for _, jobCondition := range validatorJob.Status.Conditions {
+			if jobCondition.Type == batchv1.JobFailed && jobCondition.Status == corev1.ConditionTrue && jobCondition.Message != "" {
+				condition.Message += ": " + jobCondition.Message
+			}
+		}
+	}
+	return condition
+}
+
+func joinValidationMessages(messages []string) string {
+	if len(messages) > validationMessageLimit {
+		return strings.Join(messages[:validationMessageLimit], "; ") + fmt.Sprintf("; and %d more", len(messages)-validationMessageLimit)
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,239 @@
+package renovate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/config"
+	crdManager "renovate-operator/internal/crdManager"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// records the config validation results instead of writing them to the renovatejob
+type validationRecorder struct {
+	crdManager.RenovateJobManager
+	calls      int
+	configHash string
+	condition  *metav1.Condition
+}
+
+func (m *validationRecorder) UpdateConfigValidation(ctx context.Context, job crdManager.RenovateJobIdentifier, configHash string, condition *metav1.Condition) error {
+	m.calls++
+	m.configHash = configHash
+	m.condition = condition
+	return nil
+}
+
+// the validator jobs of the renovatejob with the given fingerprint
+func listValidatorJobs(t *testing.T, c client.Client, fingerprint string) []batchv1.Job {
+	jobs := &batchv1.JobList{}
+	if err := c.List(context.Background(), jobs, client.MatchingLabels{crdManager.JOB_LABEL_TYPE: string(crdManager.ValidatorJobType)}); err != nil {
+		t.Fatalf("failed to list jobs: %v", err)
+	}
+	var result []batchv1.Job
+	for _, job := range jobs.Items {
+		if job.Annotations[crdManager.JOB_ANNOTATION_CONFIG_FINGERPRINT] == fingerprint {
+			result = append(result, job)
+		}
+	}
+	return result
+}
+
+func TestConfigValidator_Validate(t *testing.T) {
+	if err := config.InitializeConfigModule([]config.ConfigItemDescription{{Key: "JOB_TIMEOUT_SECONDS", Optional: true, Default: "10"}}); err != nil {
+		t.Fatalf("failed to initialize config module: %v", err)
+	}
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{api.AddToScheme, batchv1.AddToScheme, corev1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to add scheme: %v", err)
+		}
+	}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "ns", UID: "uid-1"},
+		Spec: api.RenovateJobSpec{
+			Image:            "renovate:dev",
+			SecretRef:        "renovate-secret",
+			ConfigValidation: &api.RenovateConfigValidation{Mode: api.ConfigValidationModeBlock},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate-secret", Namespace: "ns"},
+		Data:       map[string][]byte{"RENOVATE_CONFIG": []byte(`{"prHourlyLimt": 2}`)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(renovateJob, secret).Build()
+	manager := &validationRecorder{}
+	validator := &configValidator{
+		client:  c,
+		manager: manager,
+		logger:  testLogger,
+		scheme:  scheme,
+		getJobLogsFn: func(ctx context.Context, job *batchv1.Job) (string, error) {
+			return `{"level":50,"msg":"Found errors in configuration","errors":[{"topic":"Configuration Error","message":"Invalid configuration option: prHourlyLimt"}]}`, nil
+		},
+	}
+	ctx := context.Background()
+
+	// a changed config starts a validator job
+	if _, err := validator.Validate(ctx, renovateJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fingerprint := manager.configHash
+	jobs := listValidatorJobs(t, c, fingerprint)
+	if fingerprint == "" || len(jobs) != 1 {
+		t.Fatalf("expected a validator job for fingerprint %q, got %d", fingerprint, len(jobs))
+	}
+	if manager.condition.Status != metav1.ConditionUnknown || manager.condition.Reason != crdManager.ConfigValidationReasonValidating {
+		t.Fatalf("expected the config to be validating, got %+v", manager.condition)
+	}
+	renovateJob.Status.ValidatedConfigHash = fingerprint
+	renovateJob.Status.Conditions = []metav1.Condition{*manager.condition}
+
+	// the errors of a failed validator job are recorded
+	validatorJob := &jobs[0]
+	validatorJob.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}
+	if err := c.Status().Update(ctx, validatorJob); err != nil {
+		t.Fatalf("failed to update validator job: %v", err)
+	}
+	if _, err := validator.Validate(ctx, renovateJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manager.condition.Status != metav1.ConditionFalse || manager.condition.Reason != crdManager.ConfigValidationReasonInvalid ||
+		!strings.Contains(manager.condition.Message, "Invalid configuration option: prHourlyLimt") {
+		t.Fatalf("expected the config to be invalid, got %+v", manager.condition)
+	}
+	renovateJob.Status.Conditions = []metav1.Condition{*manager.condition}
+
+	// a recorded result is not validated again
+	calls := manager.calls
+	if retryAfter, err := validator.Validate(ctx, renovateJob); err != nil || retryAfter != 0 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manager.calls != calls {
+		t.Fatalf("expected the recorded result to be kept, got %+v", manager.condition)
+	}
+
+	// fixing the secret validates the config again
+	secret.Data["RENOVATE_CONFIG"] = []byte(`{"prHourlyLimit": 2}`)
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+	if _, err := validator.Validate(ctx, renovateJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manager.configHash == fingerprint || len(listValidatorJobs(t, c, manager.configHash)) != 1 {
+		t.Fatalf("expected a validator job for the changed config, got fingerprint %q", manager.configHash)
+	}
+	if manager.condition.Reason != crdManager.ConfigValidationReasonValidating {
+		t.Fatalf("expected the config to be validating, got %+v", manager.condition)
+	}
+
+	// disabling the validation removes the result
+	renovateJob.Spec.ConfigValidation = nil
+	if _, err := validator.Validate(ctx, renovateJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manager.condition != nil {
+		t.Fatalf("expected the condition to be removed, got %+v", manager.condition)
+	}
+}
+
+func TestConfigValidator_MissingSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{api.AddToScheme, batchv1.AddToScheme, corev1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to add scheme: %v", err)
+		}
+	}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			SecretRef:        "missing",
+			ConfigValidation: &api.RenovateConfigValidation{Mode: api.ConfigValidationModeWarn},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(renovateJob).Build()
+	manager := &validationRecorder{}
+	validator := NewConfigValidator(scheme, manager, c, testLogger)
+
+	if _, err := validator.Validate(context.Background(), renovateJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manager.condition == nil || manager.condition.Reason != crdManager.ConfigValidationReasonUnavailable {
+		t.Fatalf("expected the config to be unavailable, got %+v", manager.condition)
+	}
+	if jobs := listValidatorJobs(t, c, ""); len(jobs) != 0 {
+		t.Fatalf("expected no validator job, got %d", len(jobs))
+	}
+}
+
+func TestConfigValidator_RetryFailedValidation(t *testing.T) {
+	if err := config.InitializeConfigModule([]config.ConfigItemDescription{{Key: "JOB_TIMEOUT_SECONDS", Optional: true, Default: "10"}}); err != nil {
+		t.Fatalf("failed to initialize config module: %v", err)
+	}
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{api.AddToScheme, batchv1.AddToScheme, corev1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to add scheme: %v", err)
+		}
+	}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "renovate", Namespace: "ns", UID: "uid-1"},
+		Spec:       api.RenovateJobSpec{ConfigValidation: &api.RenovateConfigValidation{Mode: api.ConfigValidationModeWarn}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(renovateJob).Build()
+	manager := &validationRecorder{}
+	validator := &configValidator{
+		client:  c,
+		manager: manager,
+		logger:  testLogger,
+		scheme:  scheme,
+		getJobLogsFn: func(ctx context.Context, job *batchv1.Job) (string, error) {
+			return "", nil
+		},
+	}
+	ctx := context.Background()
+
+	if _, err := validator.Validate(ctx, renovateJob); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jobs := listValidatorJobs(t, c, manager.configHash)
+	if len(jobs) != 1 {
+		t.Fatalf("expected a validator job, got %d", len(jobs))
+	}
+	renovateJob.Status.ValidatedConfigHash = manager.configHash
+	renovateJob.Status.Conditions = []metav1.Condition{*manager.condition}
+
+	// a validator job failing without errors is retried after the retry interval
+	jobs[0].Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "DeadlineExceeded"}}
+	if err := c.Status().Update(ctx, &jobs[0]); err != nil {
+		t.Fatalf("failed to update validator job: %v", err)
+	}
+	retryAfter, err := validator.Validate(ctx, renovateJob)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manager.condition.Reason != crdManager.ConfigValidationReasonFailed || retryAfter != validationRetryInterval {
+		t.Fatalf("expected a retry after %v, got %v with %+v", validationRetryInterval, retryAfter, manager.condition)
+	}
+
+	failed := *manager.condition
+	failed.LastTransitionTime = metav1.NewTime(time.Now().Add(-validationRetryInterval / 2))
+	renovateJob.Status.Conditions = []metav1.Condition{failed}
+	retryAfter, err = validator.Validate(ctx, renovateJob)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retryAfter <= 0 || retryAfter > validationRetryInterval/2 {
+		t.Fatalf("expected a retry within the remaining interval, got %v", retryAfter)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	api "renovate-operator/api/v1alpha1"
 	crdManager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/gitprovider"
 	"renovate-operator/internal/utils"
 	"sync"
 	"time"
@@ -36,6 +37,8 @@ type discoveryAgent struct {
 	logger logr.Logger
 	scheme *runtime.Scheme
 	syncer map[string]*sync.RWMutex
+	// creates the platform clients used by the api discovery mode
+	gitProviderClientFactory gitprovider.ClientFactory
 	// allow tests to override how logs are extracted
 	getDiscoveredProjectsFromJobLogsFn func(ctx context.Context, c client.Client, job *batchv1.Job) ([]string, error)
 	// allow tests to override how status is checked
@@ -49,6 +52,7 @@ func NewDiscoveryAgent(scheme *runtime.Scheme, client client.Client, logger logr
 		scheme: scheme,
 		syncer: make(map[string]*sync.RWMutex),
 	}
+	da.gitProviderClientFactory = gitprovider.NewClientFactory(client)
 	// default to the internal implementation
 	da.getDiscoveredProjectsFromJobLogsFn = da.getDiscoveredProjectsFromJobLogs
 	da.getDiscoveryJobStatusFn = da.getDiscoveryJobStatusInternal
@@ -58,10 +62,31 @@ func NewDiscoveryAgent(scheme *runtime.Scheme, client client.Client, logger logr
 func (e *discoveryAgent) Discover(ctx context.Context, job *api.RenovateJob) ([]string, error) {
 	name := job.Fullname()
 
+	if job.Spec.DiscoveryMode == api.DiscoveryModeAPI {
+		projects, err := e.discoverFromAPI(ctx, job)
+		if err == nil {
+			e.logger.V(2).Info("Discovered projects through the platform API", "count", len(projects), "job", name)
+			return projects, nil
+		}
+		e.logger.Error(err, "Failed to discover projects through the platform API, falling back to the discovery job", "job", name)
+	}
+
 	e.logger.V(2).Info("Discovering projects for RenovateJob", "job", name)
 	return e.discoverIntern(ctx, job)
 }
 
+// list the projects through the platform API instead of starting a discovery job
+func (e *discoveryAgent) discoverFromAPI(ctx context.Context, job *api.RenovateJob) ([]string, error) {
+	if e.gitProviderClientFactory == nil {
+		return nil, fmt.Errorf("no git provider client factory configured")
+	}
+	providerClient, err := e.gitProviderClientFactory(ctx, job)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create git provider client: %w", err)
+	}
+	return gitprovider.DiscoverProjects(ctx, providerClient, job)
+}
+
 func (e *discoveryAgent) discoverIntern(ctx context.Context, job *api.RenovateJob) ([]string, error) {
 	// 1. Create the discovery job - replaces existing job
 	generation, err := e.CreateDiscoveryJob(ctx, *job)
@@ -172,7 +197,16 @@ func (e *discoveryAgent) CreateDiscoveryJob(ctx context.Context, renovateJob api
 	lock.Lock()
 	defer lock.Unlock()
 
+	// the config of the current spec is written before the job is created, the controller removes it once unset
+	if renovateJob.Spec.RenovateConfig != nil {
+		if err := crdManager.EnsureRenovateConfig(ctx, e.client, &renovateJob); err != nil {
+			return "", fmt.Errorf("failed to write the renovate config: %w", err)
+		}
+	}
 	discoveryJob := newDiscoveryJob(&renovateJob)
+	if err := applyPodTemplate(discoveryJob, getDiscoveryPodTemplate(renovateJob.Spec)); err != nil {
+		return "", fmt.Errorf("failed to apply the discovery pod template: %w", err)
+	}
 	if err := controllerutil.SetControllerReference(&renovateJob, discoveryJob, e.scheme); err != nil {
 		return "", fmt.Errorf("failed to set controller reference: %w", err)
 	}
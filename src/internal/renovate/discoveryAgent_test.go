@@ -2,11 +2,13 @@ package renovate
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/config"
 	crdManager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/gitprovider"
 
 	"github.com/go-logr/logr"
 	batchv1 "k8s.io/api/batch/v1"
@@ -129,3 +131,92 @@ func TestCreateDiscoveryJobAndWait(t *testing.T) {
 		t.Fatalf("expected 2 projects, got %d", len(projects))
 	}
 }
+
+// fakeGitProviderClient lists a fixed set of repositories
+type fakeGitProviderClient struct {
+	repositories []string
+}
+
+func (f *fakeGitProviderClient) IsFork(ctx context.Context, project string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeGitProviderClient) ListRepositories(ctx context.Context, topics []string) ([]string, error) {
+	return f.repositories, nil
+}
+
+func (f *fakeGitProviderClient) GetRepositoryMetadata(ctx context.Context, project string) (*gitprovider.RepositoryMetadata, error) {
+	return &gitprovider.RepositoryMetadata{}, nil
+}
+
+func TestDiscover_APIMode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := api.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add api scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add batch scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	da := NewDiscoveryAgent(scheme, c, testLogger).(*discoveryAgent)
+	da.gitProviderClientFactory = func(ctx context.Context, job *api.RenovateJob) (gitprovider.GitProviderClient, error) {
+		return &fakeGitProviderClient{repositories: []string{"org/b", "org/a", "other/c"}}, nil
+	}
+	da.getDiscoveryJobStatusFn = func(ctx context.Context, job *api.RenovateJob, generation string) (api.RenovateProjectStatus, error) {
+		t.Fatal("expected no discovery job to be started")
+		return api.JobStatusFailed, nil
+	}
+
+	rj := &api.RenovateJob{}
+	rj.Name = "job1"
+	rj.Namespace = "ns"
+	rj.Spec.DiscoveryMode = api.DiscoveryModeAPI
+	rj.Spec.DiscoveryFilter = "org/*"
+
+	projects, err := da.Discover(context.Background(), rj)
+	if err != nil {
+		t.Fatalf("Discover returned error: %v", err)
+	}
+	if len(projects) != 2 || projects[0] != "org/a" || projects[1] != "org/b" {
+		t.Fatalf("expected [org/a org/b], got %v", projects)
+	}
+}
+
+func TestDiscover_APIModeFallsBackToDiscoveryJob(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := api.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add api scheme: %v", err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add batch scheme: %v", err)
+	}
+	_ = config.InitializeConfigModule([]config.ConfigItemDescription{
+		{Key: "JOB_TIMEOUT_SECONDS", Optional: true, Default: "1"},
+	})
+	c := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&batchv1.Job{}).Build()
+
+	da := NewDiscoveryAgent(scheme, c, testLogger).(*discoveryAgent)
+	da.gitProviderClientFactory = func(ctx context.Context, job *api.RenovateJob) (gitprovider.GitProviderClient, error) {
+		return nil, fmt.Errorf("unsupported platform")
+	}
+	da.getDiscoveredProjectsFromJobLogsFn = func(ctx context.Context, c client.Client, job *batchv1.Job) ([]string, error) {
+		return []string{"a", "b"}, nil
+	}
+	da.getDiscoveryJobStatusFn = func(ctx context.Context, job *api.RenovateJob, generation string) (api.RenovateProjectStatus, error) {
+		return api.JobStatusCompleted, nil
+	}
+
+	rj := &api.RenovateJob{}
+	rj.Name = "job1"
+	rj.Namespace = "ns"
+	rj.Spec.DiscoveryMode = api.DiscoveryModeAPI
+
+	projects, err := da.Discover(context.Background(), rj)
+	if err != nil {
+		t.Fatalf("Discover returned error: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("expected 2 projects from the discovery job, got %v", projects)
+	}
+}
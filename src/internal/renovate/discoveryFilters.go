@@ -0,0 +1,43 @@
+package renovate
+
+import (
+	"context"
+	"fmt"
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/gitprovider"
+	"renovate-operator/internal/utils"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+/*
+ApplyDiscoveryFilters applies the project filters and skip options of a RenovateJob CRD to its discovered projects
+and merges them with its static projects. Scheduled discoveries and discoveries started from the UI share it,
+so both apply the same projects. The git provider is only queried if a skip option is set.
+*/
+func ApplyDiscoveryFilters(ctx context.Context, logger logr.Logger, renovateJob *api.RenovateJob, discovered []string, clientFactory gitprovider.ClientFactory) ([]string, error) {
+	projects, err := utils.ApplyProjectFilters(discovered, renovateJob.Spec.ProjectFilters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply project filters: %w", err)
+	}
+
+	if (renovateJob.Spec.SkipForks || gitprovider.FiltersByMetadata(&renovateJob.Spec)) && clientFactory != nil {
+		providerClient, err := clientFactory(ctx, renovateJob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create git provider client for repository filtering: %w", err)
+		}
+		if renovateJob.Spec.SkipForks {
+			projects, err = gitprovider.FilterForks(ctx, providerClient, logger, projects)
+			if err != nil {
+				return nil, fmt.Errorf("failed to filter forked repositories: %w", err)
+			}
+			logger.V(2).Info("Filtered forked repositories", "remaining", len(projects))
+		}
+		projects, err = gitprovider.FilterByMetadata(ctx, providerClient, logger, projects, &renovateJob.Spec, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter archived, empty or inactive repositories: %w", err)
+		}
+	}
+	return utils.MergeProjects(renovateJob.Spec.Projects, projects), nil
+}
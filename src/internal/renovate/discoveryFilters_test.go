@@ -0,0 +1,45 @@
+package renovate
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/internal/gitprovider"
+)
+
+func TestApplyDiscoveryFilters(t *testing.T) {
+	renovateJob := &api.RenovateJob{
+		Spec: api.RenovateJobSpec{
+			Projects:       []string{"org/static"},
+			ProjectFilters: []api.RenovateProjectFilter{{Action: api.ProjectFilterExclude, Glob: "org/legacy-*"}},
+		},
+	}
+	factoryCalls := 0
+	factory := func(ctx context.Context, job *api.RenovateJob) (gitprovider.GitProviderClient, error) {
+		factoryCalls++
+		return &fakeGitProviderClient{}, nil
+	}
+	discovered := []string{"org/api", "org/legacy-web", "org/static"}
+
+	// the git provider is only queried for skip options
+	projects, err := ApplyDiscoveryFilters(context.Background(), testLogger, renovateJob, discovered, factory)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"org/api", "org/static"}; !slices.Equal(slices.Sorted(slices.Values(projects)), expected) {
+		t.Fatalf("expected %v, got %v", expected, projects)
+	}
+	if factoryCalls != 0 {
+		t.Fatalf("expected no git provider client without skip options, got %d", factoryCalls)
+	}
+
+	renovateJob.Spec.SkipForks = true
+	if _, err := ApplyDiscoveryFilters(context.Background(), testLogger, renovateJob, discovered, factory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if factoryCalls != 1 {
+		t.Fatalf("expected a git provider client for skipForks, got %d", factoryCalls)
+	}
+}
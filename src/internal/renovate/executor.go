@@ -8,7 +8,7 @@ import (
 	"renovate-operator/config"
 	"renovate-operator/health"
 	"renovate-operator/metricStore"
-	"sort"
+	"slices"
 	"sync"
 	"time"
 
@@ -18,6 +18,7 @@ import (
 	"renovate-operator/internal/utils"
 
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,14 +26,23 @@ import (
 )
 
 /*
-RenovateExecutor is the interface that periodically executes RenovateJob CRDs.
+RenovateExecutor is the interface that executes RenovateJob CRDs.
 It checks the status of each project and starts new jobs as needed based on the specified parameters.
+Executions are triggered by changes to RenovateJobs, their projects and executor jobs, with a periodic resync as a safety net.
 */
 type RenovateExecutor interface {
-	// Start begins the periodic execution of RenovateJob CRDs.
+	// Start begins the execution of RenovateJob CRDs.
 	Start(ctx context.Context) error
+	// Trigger requests an execution of the given RenovateJob as soon as possible.
+	Trigger(job crdManager.RenovateJobIdentifier)
 }
 
+// interval in which all RenovateJobs are executed, regardless of any trigger
+const resyncInterval = 5 * time.Minute
+
+// time in which the informer cache is expected to contain the changes made by the executor
+const cacheSyncGracePeriod = 30 * time.Second
+
 type RenovateJobInfo struct {
 	Name      string
 	Namespace string
@@ -46,17 +56,45 @@ type renovateExecutor struct {
 	logger        logr.Logger
 	health        health.HealthCheck
 	manager       crdManager.RenovateJobManager
+
+	// renovatejobs waiting to be executed, keyed by their fullname
+	pending     map[string]crdManager.RenovateJobIdentifier
+	pendingLock sync.Mutex
+	wakeup      chan struct{}
+
+	// status transitions written by the executor that might not be visible in the cache yet
+	expectations     map[string]statusExpectation
+	expectationsLock sync.Mutex
+
+	// renovatejobs that shall be executed at a later point in time, keyed by their fullname
+	delayedTriggers     map[string]delayedTrigger
+	delayedTriggersLock sync.Mutex
+}
+
+type delayedTrigger struct {
+	timer *time.Timer
+	at    time.Time
+}
+
+type statusExpectation struct {
+	from  api.RenovateProjectStatus
+	to    api.RenovateProjectStatus
+	until time.Time
 }
 
 func NewRenovateExecutor(scheme *runtime.Scheme, manager crdManager.RenovateJobManager, client client.Client, logger logr.Logger, health health.HealthCheck) RenovateExecutor {
 	return &renovateExecutor{
-		syncer:        make(map[string]*sync.Mutex),
-		updateJobSync: make(map[string]*sync.Mutex),
-		client:        client,
-		scheme:        scheme,
-		manager:       manager,
-		logger:        logger,
-		health:        health,
+		syncer:          make(map[string]*sync.Mutex),
+		updateJobSync:   make(map[string]*sync.Mutex),
+		client:          client,
+		scheme:          scheme,
+		manager:         manager,
+		logger:          logger,
+		health:          health,
+		pending:         make(map[string]crdManager.RenovateJobIdentifier),
+		wakeup:          make(chan struct{}, 1),
+		expectations:    make(map[string]statusExpectation),
+		delayedTriggers: make(map[string]delayedTrigger),
 	}
 }
 
@@ -68,29 +106,66 @@ func (e *renovateExecutor) Start(ctx context.Context) error {
 			return eHealth
 		})
 		e.logger.Info("starting renovate executor loop")
+
+		// execute everything once on startup, afterwards only on triggers and resync
+		err := e.execute()
+		if err != nil {
+			e.logger.Error(err, "an error occured in execution loop")
+		}
+
+		resync := time.NewTicker(resyncInterval)
+		defer resync.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				e.logger.Info("executor loop stopped due to context cancellation")
 				return
-			default:
+			case <-e.wakeup:
+				e.executePending()
+			case <-resync.C:
 				err := e.execute()
 				if err != nil {
 					e.logger.Error(err, "an error occured in execution loop")
 				}
-				// Wait for 10 seconds or until context is done
-				select {
-				case <-ctx.Done():
-					e.logger.Info("executor loop stopped during sleep due to context cancellation")
-					return
-				case <-time.After(10 * time.Second):
-				}
 			}
 		}
 	}()
 	return nil
 }
 
+func (e *renovateExecutor) Trigger(job crdManager.RenovateJobIdentifier) {
+	e.pendingLock.Lock()
+	e.pending[job.Fullname()] = job
+	e.pendingLock.Unlock()
+
+	// wake up the executor loop, if it is not already woken up
+	select {
+	case e.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// trigger the execution of a renovatejob at the given time. an earlier pending trigger of the same renovatejob is kept
+func (e *renovateExecutor) triggerAt(job crdManager.RenovateJobIdentifier, at time.Time) {
+	e.delayedTriggersLock.Lock()
+	defer e.delayedTriggersLock.Unlock()
+
+	key := job.Fullname()
+	existing, ok := e.delayedTriggers[key]
+	if ok && existing.at.After(time.Now()) {
+		if !existing.at.After(at) {
+			return
+		}
+		existing.timer.Stop()
+	}
+	e.delayedTriggers[key] = delayedTrigger{
+		at: at,
+		timer: time.AfterFunc(time.Until(at), func() {
+			e.Trigger(job)
+		}),
+	}
+}
+
 func (e *renovateExecutor) execute() error {
 	ctx := context.Background()
 
@@ -105,7 +180,34 @@ func (e *renovateExecutor) execute() error {
 			e.logger.Error(err, "renovate loop execution failed for job")
 		}
 	}
-	return nil
+	return e.startScheduledProjects(ctx)
+}
+
+// execute all triggered renovatejobs
+func (e *renovateExecutor) executePending() {
+	ctx := context.Background()
+
+	e.pendingLock.Lock()
+	pending := e.pending
+	e.pending = make(map[string]crdManager.RenovateJobIdentifier)
+	e.pendingLock.Unlock()
+
+	for _, job := range pending {
+		err := e.executeRenovateJob(ctx, &job)
+		if errors.IsNotFound(err) {
+			// renovatejob got deleted in the meantime
+			continue
+		}
+		if err != nil {
+			e.logger.Error(err, "renovate execution failed for job", "job", job.Fullname())
+		}
+	}
+
+	// slots might have been freed, which can be used by any renovatejob
+	err := e.startScheduledProjects(ctx)
+	if err != nil {
+		e.logger.Error(err, "failed to start scheduled projects")
+	}
 }
 
 func (e *renovateExecutor) syncOnJobExecution(name string) (bool, func()) {
@@ -157,37 +259,49 @@ func (e *renovateExecutor) executeRenovateJob(ctx context.Context, job *crdManag
 }
 
 func (e *renovateExecutor) reconcileProjects(ctx context.Context, renovateJob *api.RenovateJob) error {
-	// determine how many projects are currently running
-	runningProjects := 0
-	for i := range renovateJob.Status.Projects {
-		project := &renovateJob.Status.Projects[i]
-		if project.Status == api.JobStatusRunning {
-			runningProjects++
-		}
-	}
-
 	jobId := crdManager.RenovateJobIdentifier{
 		Name:      renovateJob.Name,
 		Namespace: renovateJob.Namespace,
 	}
 
-	// Pass 1: process running projects to free slots
-	for i := range renovateJob.Status.Projects {
-		project := &renovateJob.Status.Projects[i]
+	projects, err := e.manager.GetProjectsForRenovateJob(ctx, jobId)
+	if err != nil {
+		return err
+	}
+	e.applyExpectations(jobId, projects)
+
+	// logs of finished batch jobs are parsed once for all of their projects
+	batchResults := make(map[string]map[string]*parser.RepositoryLogParseResult)
+	completedBatchProjects := make(map[string]int)
+	batchJobs := make(map[string]*batchv1.Job)
+
+	// process running projects to free slots, scheduled projects are started by startScheduledProjects
+	for i := range projects {
+		project := &projects[i]
+		metricStore.SetProjectQuarantined(renovateJob.Namespace, renovateJob.Name, project.Name, project.Status == api.JobStatusQuarantined)
+		if project.LastSuccess != nil {
+			metricStore.SetLastSuccess(renovateJob.Namespace, renovateJob.Name, project.Name, *project.LastSuccess)
+		}
+		if project.Status == api.JobStatusFailed && project.NextRetry != nil && !renovateJob.Spec.Suspend && !project.Suspended {
+			err := e.retryProject(ctx, jobId, project)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if project.Status != api.JobStatusRunning {
 			continue
 		}
 
-		job, err := crdManager.GetJobByLabel(ctx, e.client, crdManager.JobSelector{
-			JobName:   utils.ExecutorJobName(renovateJob, project.Name),
-			JobType:   crdManager.ExecutorJobType,
-			Namespace: renovateJob.Namespace,
-		})
+		job, err := crdManager.GetExecutorJob(ctx, e.client, renovateJob.Namespace, utils.ExecutorJobName(renovateJob, project.Name))
 
 		var newStatus api.RenovateProjectStatus
 		var durationStr string
 		if err != nil {
-			if errors.IsNotFound(err) {
+			if errors.IsNotFound(err) && e.isRecentlyStarted(jobId, project.Name) {
+				// the job was just created and is not yet visible in the cache
+				continue
+			} else if errors.IsNotFound(err) {
 				newStatus = api.JobStatusFailed
 			} else {
 				return err
@@ -200,82 +314,472 @@ func (e *renovateExecutor) reconcileProjects(ctx context.Context, renovateJob *a
 		}
 
 		if newStatus != api.JobStatusRunning {
+			logResult := &parser.LogParseResult{}
+			if job != nil {
+				newStatus, logResult = e.getProjectRunResult(ctx, job, project.Name, newStatus, batchResults)
+			}
 			newProjectStatus := &types.RenovateStatusUpdate{
-				Status:   newStatus,
-				Duration: &durationStr,
+				Status:               newStatus,
+				Duration:             &durationStr,
+				RenovateResultStatus: logResult.RenovateResultStatus,
 			}
-			hasIssues := false
-			if job != nil {
-				cp := clientProvider.StaticClientProvider()
-				if clientset, err := cp.K8sClientSet(); err == nil {
-					if logs, err := crdManager.GetLastJobLog(ctx, clientset, job); err == nil {
-						parseResult := parser.ParseRenovateLogs(logs)
-						hasIssues = parseResult.HasIssues
-						newProjectStatus.RenovateResultStatus = parseResult.RenovateResultStatus
-					} else {
-						e.logger.Error(err, "failed to get logs for metrics parsing", "project", project.Name)
-					}
+			if newStatus == api.JobStatusFailed {
+				if isQuarantineThresholdReached(renovateJob, project) {
+					newStatus = api.JobStatusQuarantined
+					newProjectStatus.Status = newStatus
 				} else {
-					e.logger.Error(err, "failed to create Kubernetes clientset for metrics parsing", "project", project.Name)
+					newProjectStatus.NextRetry = getNextRetry(renovateJob.Spec.RetryPolicy, project.Attempts+1, time.Now())
 				}
 			}
+			hasIssues := logResult.HasIssues
 
-			metricStore.SetRunFailed(renovateJob.Namespace, renovateJob.Name, project.Name, newStatus == api.JobStatusFailed)
+			runFailed := newStatus != api.JobStatusCompleted
+			metricStore.SetRunFailed(renovateJob.Namespace, renovateJob.Name, project.Name, runFailed)
 			metricStore.SetDependencyIssues(renovateJob.Namespace, renovateJob.Name, project.Name, hasIssues)
-			metricStore.CaptureRenovateProjectExecution(renovateJob.Namespace, renovateJob.Name, project.Name, string(newStatus))
+			metricStore.SetProjectQuarantined(renovateJob.Namespace, renovateJob.Name, project.Name, newStatus == api.JobStatusQuarantined)
+			if newStatus == api.JobStatusCompleted {
+				metricStore.SetLastSuccess(renovateJob.Namespace, renovateJob.Name, project.Name, time.Now())
+			}
+			runStatus := api.JobStatusCompleted
+			if runFailed {
+				runStatus = api.JobStatusFailed
+			}
+			metricStore.CaptureRenovateProjectExecution(renovateJob.Namespace, renovateJob.Name, project.Name, string(runStatus))
 
 			err = e.manager.UpdateProjectStatus(ctx, project.Name, jobId, newProjectStatus)
-			runningProjects--
+			if errors.IsNotFound(err) {
+				// the project was removed while it was running
+				continue
+			}
 			if err != nil {
 				return err
 			}
+			e.expectStatus(jobId, project.Name, api.JobStatusRunning, newStatus)
+			if newStatus == api.JobStatusQuarantined {
+				e.logger.Info("project failed repeatedly and got quarantined", "job", jobId.Fullname(), "project", project.Name, "consecutiveFailures", project.ConsecutiveFailures+1)
+			}
+			if newProjectStatus.NextRetry != nil {
+				e.logger.Info("renovate run failed, retrying project", "job", jobId.Fullname(), "project", project.Name, "attempts", project.Attempts+1, "nextRetry", newProjectStatus.NextRetry.Time)
+				e.triggerAt(jobId, newProjectStatus.NextRetry.Time)
+			}
 
 			deleteSuccessfulJobs := config.GetValue("DELETE_SUCCESSFUL_JOBS")
 			if newStatus == api.JobStatusCompleted && deleteSuccessfulJobs == "true" && job != nil {
-				err = crdManager.DeleteJob(ctx, e.client, job)
-				if err != nil {
+				if crdManager.GetBatchProjects(job) != nil {
+					// batch jobs are deleted once all of their projects completed
+					completedBatchProjects[job.Name]++
+					batchJobs[job.Name] = job
+				} else if err := crdManager.DeleteJob(ctx, e.client, job); err != nil {
 					return err
 				}
 			}
 		}
 	}
 
-	// Pass 2: sort by priority descending, then start scheduled projects in priority order
-	sort.SliceStable(renovateJob.Status.Projects, func(i, j int) bool {
-		return renovateJob.Status.Projects[i].Priority > renovateJob.Status.Projects[j].Priority
+	for name, job := range batchJobs {
+		if completedBatchProjects[name] < len(crdManager.GetBatchProjects(job)) {
+			continue
+		}
+		if err := crdManager.DeleteJob(ctx, e.client, job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+status and parsed logs of the finished run of a project.
+the status of a batch job covers all of its projects, so the status of each project is taken from its own
+log entries. projects that did not finish or finished with an error failed, regardless of the status of the job.
+*/
+func (e *renovateExecutor) getProjectRunResult(ctx context.Context, job *batchv1.Job, project string, jobStatus api.RenovateProjectStatus, batchResults map[string]map[string]*parser.RepositoryLogParseResult) (api.RenovateProjectStatus, *parser.LogParseResult) {
+	if crdManager.GetBatchProjects(job) == nil {
+		logs, err := getJobLogs(ctx, job)
+		if err != nil {
+			e.logger.Error(err, "failed to get logs for metrics parsing", "project", project)
+			return jobStatus, &parser.LogParseResult{}
+		}
+		return jobStatus, parser.ParseRenovateLogs(logs)
+	}
+
+	results, ok := batchResults[job.Name]
+	if !ok {
+		logs, err := getJobLogs(ctx, job)
+		if err != nil {
+			e.logger.Error(err, "failed to get logs for metrics parsing", "job", job.Name)
+		} else {
+			results = parser.ParseRenovateLogsByRepository(logs)
+		}
+		batchResults[job.Name] = results
+	}
+	if results == nil {
+		// without logs, the status of the batch job is the best guess
+		return jobStatus, &parser.LogParseResult{}
+	}
+
+	result, ok := results[project]
+	if !ok {
+		return api.JobStatusFailed, &parser.LogParseResult{}
+	}
+	if !result.Finished || result.Failed {
+		return api.JobStatusFailed, &result.LogParseResult
+	}
+	return api.JobStatusCompleted, &result.LogParseResult
+}
+
+func getJobLogs(ctx context.Context, job *batchv1.Job) (string, error) {
+	cp := clientProvider.StaticClientProvider()
+	clientset, err := cp.K8sClientSet()
+	if err != nil {
+		return "", fmt.Errorf("failed to create Kubernetes clientset: %w", err)
+	}
+	return crdManager.GetLastJobLog(ctx, clientset, job)
+}
+
+// whether the failing run of a project exceeds the consecutive failures allowed by the renovatejob
+func isQuarantineThresholdReached(renovateJob *api.RenovateJob, project *crdManager.RenovateProjectStatus) bool {
+	threshold := renovateJob.Spec.QuarantineThreshold
+	return threshold > 0 && project.ConsecutiveFailures+1 >= threshold
+}
+
+// schedule a failed project again once its retry is due, otherwise make sure the executor is triggered at that time
+func (e *renovateExecutor) retryProject(ctx context.Context, jobId crdManager.RenovateJobIdentifier, project *crdManager.RenovateProjectStatus) error {
+	if time.Now().Before(*project.NextRetry) {
+		e.triggerAt(jobId, *project.NextRetry)
+		return nil
+	}
+
+	err := e.manager.UpdateProjectStatus(ctx, project.Name, jobId, &types.RenovateStatusUpdate{
+		Status:   api.JobStatusScheduled,
+		Priority: retryPriority,
+		Retry:    true,
 	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.expectStatus(jobId, project.Name, api.JobStatusFailed, api.JobStatusScheduled)
+	return nil
+}
+
+/*
+start scheduled projects of all renovatejobs in the order of the execution queue.
+every renovatejob is limited by its parallelism, all of them together by the execution budget of the operator.
+*/
+func (e *renovateExecutor) startScheduledProjects(ctx context.Context) error {
+	renovateJobs, err := e.manager.ListRenovateJobsFull(ctx)
+	if err != nil {
+		return err
+	}
 
-	for i := range renovateJob.Status.Projects {
-		project := &renovateJob.Status.Projects[i]
-		if project.Status != api.JobStatusScheduled {
+	now := time.Now()
+	totalRunning := 0
+	runningPerJob := make(map[string]int, len(renovateJobs))
+	runningPerNamespace := make(map[string]int)
+	outsideWindow := make(map[string]bool)
+	configBlocked := make(map[string]bool)
+	busyCacheVolumes := make(map[string]bool)
+	queuedJobs := make([]QueuedRenovateJob, 0, len(renovateJobs))
+	for i := range renovateJobs {
+		renovateJob := &renovateJobs[i]
+		jobId := crdManager.RenovateJobIdentifier{
+			Name:      renovateJob.Name,
+			Namespace: renovateJob.Namespace,
+		}
+		projects, err := e.manager.GetProjectsForRenovateJob(ctx, jobId)
+		if err != nil {
+			return err
+		}
+		e.applyExpectations(jobId, projects)
+
+		running := []string{}
+		for _, project := range projects {
+			if project.Status == api.JobStatusRunning {
+				running = append(running, project.Name)
+				if key, exclusive := exclusiveCacheVolume(renovateJob, project.Name); exclusive {
+					busyCacheVolumes[key] = true
+				}
+			}
+			if project.Status == api.JobStatusScheduled && isWaitingForSpread(project, now) {
+				e.triggerAt(jobId, *project.NotBefore)
+			}
+		}
+		runningJobs := e.countRunningExecutorJobs(ctx, renovateJob, running)
+		totalRunning += runningJobs
+		runningPerJob[renovateJob.Fullname()] = runningJobs
+		runningPerNamespace[renovateJob.Namespace] += runningJobs
+		queuedJobs = append(queuedJobs, QueuedRenovateJob{Job: renovateJob, Projects: projects, RunningJobs: runningJobs})
+		outsideWindow[renovateJob.Fullname()] = !e.isInExecutionWindow(renovateJob, jobId, projects, now)
+		// the controller triggers the executor again once the config validated
+		configBlocked[renovateJob.Fullname()] = crdManager.IsConfigValidationBlocking(renovateJob)
+		if configBlocked[renovateJob.Fullname()] {
+			e.logger.V(2).Info("renovate config did not validate yet, scheduled projects are not started", "job", renovateJob.Fullname())
+		}
+	}
+
+	budget := GetExecutionBudget()
+	queue := BuildQueue(queuedJobs, now)
+	started := make(map[string]bool)
+	for i, entry := range queue {
+		if started[queueEntryKey(entry)] {
+			// already started as part of a batch
+			continue
+		}
+		if budget.MaxRunning > 0 && totalRunning >= budget.MaxRunning {
+			e.logger.V(2).Info("operator wide limit of running executor jobs reached", "limit", budget.MaxRunning)
+			break
+		}
+		renovateJob := entry.Job
+		if budget.MaxRunningPerNamespace > 0 && runningPerNamespace[renovateJob.Namespace] >= budget.MaxRunningPerNamespace {
+			continue
+		}
+		if runningPerJob[renovateJob.Fullname()] >= int(renovateJob.Spec.Parallelism) {
+			continue
+		}
+		if outsideWindow[renovateJob.Fullname()] {
+			continue
+		}
+		if configBlocked[renovateJob.Fullname()] {
+			continue
+		}
+		cacheVolume, exclusive := exclusiveCacheVolume(renovateJob, entry.Project)
+		if exclusive && busyCacheVolumes[cacheVolume] {
+			// the project starts once the project using its cache volume finished
 			continue
 		}
 
-		if runningProjects < int(renovateJob.Spec.Parallelism) {
-			job := newRenovateJob(renovateJob, project.Name)
-			if err := controllerutil.SetControllerReference(renovateJob, job, e.scheme); err != nil {
-				return fmt.Errorf("failed to set controller reference: %w", err)
-			}
+		projects := getBatch(queue[i:], started)
+		err := e.startProjects(ctx, renovateJob, projects)
+		if err != nil {
+			e.logger.Error(err, "failed to start project", "job", renovateJob.Fullname(), "projects", projects)
+			continue
+		}
+		for _, project := range projects {
+			started[renovateJob.Fullname()+"/"+project] = true
+		}
+		totalRunning++
+		runningPerJob[renovateJob.Fullname()]++
+		runningPerNamespace[renovateJob.Namespace]++
+		if exclusive {
+			busyCacheVolumes[cacheVolume] = true
+		}
+	}
+	return nil
+}
 
-			_, err := crdManager.CreateJobWithGeneration(ctx, e.client, job, crdManager.JobSelector{
-				JobName:   utils.ExecutorJobName(renovateJob, project.Name),
-				JobType:   crdManager.ExecutorJobType,
-				Namespace: renovateJob.Namespace,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to create RenovateJob for project %s: %w", project.Name, err)
-			}
-			runningProjects++
+func queueEntryKey(entry QueueEntry) string {
+	return entry.Job.Fullname() + "/" + entry.Project
+}
 
-			err = e.manager.UpdateProjectStatus(ctx, project.Name, jobId, &types.RenovateStatusUpdate{
-				Status: api.JobStatusRunning,
-			})
-			if err != nil {
-				return err
-			}
+// number of projects of the renovatejob run together in one executor job
+func getBatchSize(renovateJob *api.RenovateJob) int {
+	if renovateJob.Spec.BatchSize < 1 {
+		return 1
+	}
+	return int(renovateJob.Spec.BatchSize)
+}
+
+// whether a project can run in a batch job, i.e. no project override changes its executor job
+func isBatchable(renovateJob *api.RenovateJob, project string) bool {
+	override := utils.GetProjectOverride(&renovateJob.Spec, project)
+	return override.Image == "" && override.Resources == nil && len(override.ExtraEnv) == 0 &&
+		override.NodeSelector == nil && override.TimeoutSeconds == nil
+}
+
+/*
+projects started together with the first entry of the queue.
+up to batchSize projects of the same renovatejob are taken in the order of the queue,
+skipping projects that were already started or cannot share the executor job and cache volume.
+*/
+func getBatch(queue []QueueEntry, started map[string]bool) []string {
+	first := queue[0]
+	projects := []string{first.Project}
+	size := getBatchSize(first.Job)
+	if size <= 1 || !isBatchable(first.Job, first.Project) {
+		return projects
+	}
+
+	spec := &first.Job.Spec
+	shard := utils.GetCacheShard(spec, first.Project)
+	for _, entry := range queue[1:] {
+		if len(projects) >= size {
+			break
+		}
+		if entry.Job.Fullname() != first.Job.Fullname() || started[queueEntryKey(entry)] {
+			continue
+		}
+		if !isBatchable(entry.Job, entry.Project) || utils.GetCacheShard(spec, entry.Project) != shard {
+			continue
+		}
+		projects = append(projects, entry.Project)
+	}
+	return projects
+}
 
+/*
+number of executor jobs running the given projects of the renovatejob, projects of a batch share a job.
+projects whose job is not visible yet are counted as a job of their own.
+*/
+func (e *renovateExecutor) countRunningExecutorJobs(ctx context.Context, renovateJob *api.RenovateJob, running []string) int {
+	if getBatchSize(renovateJob) <= 1 {
+		return len(running)
+	}
+	jobs := make(map[string]bool, len(running))
+	for _, project := range running {
+		name := utils.ExecutorJobName(renovateJob, project)
+		if job, err := crdManager.GetExecutorJob(ctx, e.client, renovateJob.Namespace, name); err == nil {
+			name = job.Name
 		}
+		jobs[name] = true
 	}
+	return len(jobs)
+}
 
+// the cache volume of a project, if it can only be used by a single running project at a time
+func exclusiveCacheVolume(renovateJob *api.RenovateJob, project string) (string, bool) {
+	if renovateJob.Spec.Cache == nil || utils.IsCacheShared(renovateJob.Spec.Cache) {
+		return "", false
+	}
+	return renovateJob.Namespace + "/" + utils.CacheVolumeName(renovateJob, utils.GetCacheShard(&renovateJob.Spec, project)), true
+}
+
+/*
+whether scheduled projects of the renovatejob may be started now.
+outside of the execution windows, the executor is triggered again once the next window opens
+*/
+func (e *renovateExecutor) isInExecutionWindow(renovateJob *api.RenovateJob, jobId crdManager.RenovateJobIdentifier, projects []crdManager.RenovateProjectStatus, now time.Time) bool {
+	inWindow, err := utils.IsInExecutionWindow(&renovateJob.Spec, now)
+	if err != nil {
+		e.logger.Error(err, "invalid execution window, no projects are started", "job", renovateJob.Fullname())
+		return false
+	}
+	if inWindow {
+		return true
+	}
+
+	hasScheduled := slices.ContainsFunc(projects, func(p crdManager.RenovateProjectStatus) bool {
+		return p.Status == api.JobStatusScheduled
+	})
+	if !hasScheduled {
+		return false
+	}
+	next, err := utils.GetNextExecutionWindow(&renovateJob.Spec, now)
+	if err != nil {
+		e.logger.Error(err, "invalid execution window, no projects are started", "job", renovateJob.Fullname())
+		return false
+	}
+	if !next.IsZero() {
+		e.logger.V(2).Info("outside of execution window, waiting for the next window", "job", renovateJob.Fullname(), "nextWindow", next)
+		e.triggerAt(jobId, next)
+	}
+	return false
+}
+
+// create the executor job for one or a batch of projects and mark the projects as running
+func (e *renovateExecutor) startProjects(ctx context.Context, renovateJob *api.RenovateJob, projects []string) error {
+	// the config of the current spec is written before the job is created, the controller removes it once unset
+	if renovateJob.Spec.RenovateConfig != nil {
+		if err := crdManager.EnsureRenovateConfig(ctx, e.client, renovateJob); err != nil {
+			return fmt.Errorf("failed to write the renovate config: %w", err)
+		}
+	}
+	job := newRenovateJob(renovateJob, projects[0])
+	jobName := utils.ExecutorJobName(renovateJob, projects[0])
+	if len(projects) > 1 {
+		job = newRenovateBatchJob(renovateJob, projects)
+		jobName = utils.BatchJobName(renovateJob, projects)
+	}
+	if err := applyPodTemplate(job, getExecutorPodTemplate(renovateJob.Spec)); err != nil {
+		return fmt.Errorf("failed to apply the executor pod template: %w", err)
+	}
+	if err := controllerutil.SetControllerReference(renovateJob, job, e.scheme); err != nil {
+		return fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	_, err := crdManager.CreateJobWithGeneration(ctx, e.client, job, crdManager.JobSelector{
+		JobName:   jobName,
+		JobType:   crdManager.ExecutorJobType,
+		Namespace: renovateJob.Namespace,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create RenovateJob for projects %v: %w", projects, err)
+	}
+
+	jobId := crdManager.RenovateJobIdentifier{
+		Name:      renovateJob.Name,
+		Namespace: renovateJob.Namespace,
+	}
+	for _, project := range projects {
+		err = e.manager.UpdateProjectStatus(ctx, project, jobId, &types.RenovateStatusUpdate{
+			Status: api.JobStatusRunning,
+		})
+		if errors.IsNotFound(err) {
+			// the project was removed after the job was created, its result is ignored
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		e.expectStatus(jobId, project, api.JobStatusScheduled, api.JobStatusRunning)
+	}
 	return nil
 }
+
+func expectationKey(job crdManager.RenovateJobIdentifier, project string) string {
+	return job.Fullname() + "/" + project
+}
+
+// remember a status transition written by the executor until the cache is expected to contain it
+func (e *renovateExecutor) expectStatus(job crdManager.RenovateJobIdentifier, project string, from api.RenovateProjectStatus, to api.RenovateProjectStatus) {
+	e.expectationsLock.Lock()
+	defer e.expectationsLock.Unlock()
+
+	e.expectations[expectationKey(job, project)] = statusExpectation{
+		from:  from,
+		to:    to,
+		until: time.Now().Add(cacheSyncGracePeriod),
+	}
+}
+
+// replace stale project states read from the cache with the status the executor has written.
+// this prevents starting or finishing a project twice
+func (e *renovateExecutor) applyExpectations(job crdManager.RenovateJobIdentifier, projects []crdManager.RenovateProjectStatus) {
+	e.expectationsLock.Lock()
+	defer e.expectationsLock.Unlock()
+
+	now := time.Now()
+	for key, expectation := range e.expectations {
+		if now.After(expectation.until) {
+			delete(e.expectations, key)
+		}
+	}
+
+	for i := range projects {
+		key := expectationKey(job, projects[i].Name)
+		expectation, ok := e.expectations[key]
+		if !ok {
+			continue
+		}
+		switch projects[i].Status {
+		case expectation.from:
+			projects[i].Status = expectation.to
+		case expectation.to:
+			// the cache is up to date
+		default:
+			// the project was changed by someone else
+			delete(e.expectations, key)
+		}
+	}
+}
+
+// whether the executor started the project within the cache grace period
+func (e *renovateExecutor) isRecentlyStarted(job crdManager.RenovateJobIdentifier, project string) bool {
+	e.expectationsLock.Lock()
+	defer e.expectationsLock.Unlock()
+
+	expectation, ok := e.expectations[expectationKey(job, project)]
+	return ok && expectation.to == api.JobStatusRunning && time.Now().Before(expectation.until)
+}
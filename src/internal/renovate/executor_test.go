@@ -0,0 +1,197 @@
+package renovate
+
+import (
+	"testing"
+	"time"
+
+	api "renovate-operator/api/v1alpha1"
+	crdManager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTrigger_DeduplicatesPendingJobs(t *testing.T) {
+	e := NewRenovateExecutor(nil, nil, nil, testLogger, nil).(*renovateExecutor)
+	job := crdManager.RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	e.Trigger(job)
+	e.Trigger(job)
+	e.Trigger(crdManager.RenovateJobIdentifier{Name: "job2", Namespace: "default"})
+
+	if len(e.pending) != 2 {
+		t.Fatalf("expected 2 pending jobs, got %d", len(e.pending))
+	}
+	select {
+	case <-e.wakeup:
+	default:
+		t.Fatalf("expected the executor loop to be woken up")
+	}
+	select {
+	case <-e.wakeup:
+		t.Fatalf("expected only a single wakeup for multiple triggers")
+	default:
+	}
+}
+
+func TestApplyExpectations(t *testing.T) {
+	e := NewRenovateExecutor(nil, nil, nil, testLogger, nil).(*renovateExecutor)
+	job := crdManager.RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	e.expectStatus(job, "stale", api.JobStatusScheduled, api.JobStatusRunning)
+	e.expectStatus(job, "synced", api.JobStatusScheduled, api.JobStatusRunning)
+	e.expectStatus(job, "changed", api.JobStatusRunning, api.JobStatusCompleted)
+
+	projects := []crdManager.RenovateProjectStatus{
+		{Name: "stale", Status: api.JobStatusScheduled},
+		{Name: "synced", Status: api.JobStatusRunning},
+		{Name: "changed", Status: api.JobStatusScheduled},
+		{Name: "other", Status: api.JobStatusScheduled},
+	}
+	e.applyExpectations(job, projects)
+
+	expected := map[string]api.RenovateProjectStatus{
+		"stale":   api.JobStatusRunning,
+		"synced":  api.JobStatusRunning,
+		"changed": api.JobStatusScheduled,
+		"other":   api.JobStatusScheduled,
+	}
+	for _, p := range projects {
+		if p.Status != expected[p.Name] {
+			t.Errorf("project %s: expected status %s, got %s", p.Name, expected[p.Name], p.Status)
+		}
+	}
+
+	if !e.isRecentlyStarted(job, "stale") {
+		t.Errorf("expected stale to be recently started")
+	}
+	if e.isRecentlyStarted(job, "changed") {
+		t.Errorf("expected expectation of changed to be removed")
+	}
+}
+
+func TestApplyExpectations_Expired(t *testing.T) {
+	e := NewRenovateExecutor(nil, nil, nil, testLogger, nil).(*renovateExecutor)
+	job := crdManager.RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	e.expectations[expectationKey(job, "p1")] = statusExpectation{
+		from:  api.JobStatusScheduled,
+		to:    api.JobStatusRunning,
+		until: time.Now().Add(-time.Second),
+	}
+
+	projects := []crdManager.RenovateProjectStatus{{Name: "p1", Status: api.JobStatusScheduled}}
+	e.applyExpectations(job, projects)
+
+	if projects[0].Status != api.JobStatusScheduled {
+		t.Fatalf("expected expired expectation to be ignored, got %s", projects[0].Status)
+	}
+	if len(e.expectations) != 0 {
+		t.Fatalf("expected expired expectation to be removed")
+	}
+}
+
+func TestIsInExecutionWindow_TriggersAtNextWindow(t *testing.T) {
+	e := NewRenovateExecutor(nil, nil, nil, testLogger, nil).(*renovateExecutor)
+	jobId := crdManager.RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+	renovateJob := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
+		Spec: api.RenovateJobSpec{
+			TimeZone:         "UTC",
+			ExecutionWindows: []api.RenovateExecutionWindow{{Start: "02:00", End: "06:00"}},
+		},
+	}
+	now := time.Now().UTC()
+	now = time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, time.UTC)
+
+	// without scheduled projects there is nothing to wait for
+	if e.isInExecutionWindow(renovateJob, jobId, []crdManager.RenovateProjectStatus{{Name: "p1", Status: api.JobStatusCompleted}}, now) {
+		t.Fatalf("expected to be outside of the execution window")
+	}
+	if len(e.delayedTriggers) != 0 {
+		t.Fatalf("expected no delayed trigger without scheduled projects")
+	}
+
+	if e.isInExecutionWindow(renovateJob, jobId, []crdManager.RenovateProjectStatus{{Name: "p1", Status: api.JobStatusScheduled}}, now) {
+		t.Fatalf("expected to be outside of the execution window")
+	}
+	trigger, ok := e.delayedTriggers[jobId.Fullname()]
+	if !ok {
+		t.Fatalf("expected a delayed trigger for the next window")
+	}
+	trigger.timer.Stop()
+	if expected := now.Add(14 * time.Hour); !trigger.at.Equal(expected) {
+		t.Errorf("expected trigger at %v, got %v", expected, trigger.at)
+	}
+
+	if !e.isInExecutionWindow(renovateJob, jobId, nil, now.Add(-9*time.Hour)) {
+		t.Errorf("expected to be inside of the execution window")
+	}
+}
+
+func TestExclusiveCacheVolume(t *testing.T) {
+	job := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"}}
+	if _, exclusive := exclusiveCacheVolume(job, "org/a"); exclusive {
+		t.Fatal("expected no exclusive cache volume without cache")
+	}
+
+	job.Spec.Cache = &api.RenovateCache{}
+	a, exclusive := exclusiveCacheVolume(job, "org/a")
+	if !exclusive {
+		t.Fatal("expected ReadWriteOnce cache volumes to be exclusive")
+	}
+	if b, _ := exclusiveCacheVolume(job, "org/b"); a != b {
+		t.Errorf("expected projects to share the single cache volume, got %s and %s", a, b)
+	}
+
+	job.Spec.Cache.AccessMode = corev1.ReadWriteMany
+	if _, exclusive := exclusiveCacheVolume(job, "org/a"); exclusive {
+		t.Error("expected ReadWriteMany cache volumes to be shared")
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	job1 := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
+		Spec: api.RenovateJobSpec{
+			BatchSize: 3,
+			ProjectOverrides: []api.RenovateProjectOverride{
+				{Project: "org/big", Image: "renovate:full"},
+				{Project: "org/urgent", Priority: 5},
+			},
+		},
+	}
+	job2 := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "job2", Namespace: "default"}}
+	queue := []QueueEntry{
+		{Job: job1, Project: "org/urgent"},
+		{Job: job2, Project: "org/other"},
+		{Job: job1, Project: "org/big"},
+		{Job: job1, Project: "org/a"},
+		{Job: job1, Project: "org/b"},
+		{Job: job1, Project: "org/c"},
+	}
+	started := map[string]bool{"job1-default/org/a": true}
+
+	// priority overrides do not prevent batching, other overrides and started projects are skipped
+	got := getBatch(queue, started)
+	expected := []string{"org/urgent", "org/b", "org/c"}
+	if len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] || got[2] != expected[2] {
+		t.Errorf("expected batch %v, got %v", expected, got)
+	}
+
+	if got := getBatch(queue[2:], started); len(got) != 1 || got[0] != "org/big" {
+		t.Errorf("expected projects with overrides to run on their own, got %v", got)
+	}
+	if got := getBatch(queue[1:], started); len(got) != 1 || got[0] != "org/other" {
+		t.Errorf("expected no batch without batchSize, got %v", got)
+	}
+
+	// projects of a batch share a cache volume
+	job1.Spec.Cache = &api.RenovateCache{Shards: 8}
+	for _, project := range getBatch(queue, map[string]bool{}) {
+		if utils.GetCacheShard(&job1.Spec, project) != utils.GetCacheShard(&job1.Spec, "org/urgent") {
+			t.Errorf("project %s of the batch uses another cache volume", project)
+		}
+	}
+}
@@ -2,15 +2,19 @@ package renovate
 
 import (
 	"encoding/json"
+	"fmt"
 	"maps"
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/config"
 	crdmanager "renovate-operator/internal/crdManager"
 	"renovate-operator/internal/utils"
+	"slices"
 	"strconv"
 
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/utils/ptr"
 )
 
@@ -60,16 +64,17 @@ func newDiscoveryJob(job *api.RenovateJob) *batchv1.Job {
 			MountPath: "/tmp",
 		},
 	}
+	volumes, volumeMounts = appendRenovateConfigVolume(job, volumes, volumeMounts)
 
 	batchJob := &batchv1.Job{
 		Spec: batchv1.JobSpec{
-			ActiveDeadlineSeconds:   getJobTimeoutSeconds(),
-			BackoffLimit:            getJobBackOffLimit(),
-			TTLSecondsAfterFinished: getJobTTLSecondsAfterFinished(),
+			ActiveDeadlineSeconds:   getJobTimeoutSeconds(job.Spec.DiscoveryJob),
+			BackoffLimit:            getJobBackOffLimit(job.Spec.DiscoveryJob),
+			TTLSecondsAfterFinished: getJobTTLSecondsAfterFinished(job.Spec.DiscoveryJob),
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					ServiceAccountName:            getServiceAccountName(job.Spec),
-					ImagePullSecrets:              append(job.Spec.ImagePullSecrets, getDefaultImagePullSecrets()...),
+					ImagePullSecrets:              slices.Concat(job.Spec.ImagePullSecrets, getDefaultImagePullSecrets()),
 					TerminationGracePeriodSeconds: ptr.To(int64(0)),
 					Containers: []v1.Container{
 						{
@@ -105,15 +110,66 @@ func newDiscoveryJob(job *api.RenovateJob) *batchv1.Job {
 		batchJob.Spec.Template.Annotations = job.Spec.Metadata.Annotations
 		batchJob.Annotations = job.Spec.Metadata.Annotations
 	}
+	setRenovateConfigHash(batchJob, job)
 	labels := getJobLabels(job.Spec.Metadata, crdmanager.DiscoveryJobType, jobName)
 	batchJob.Spec.Template.Labels = labels
 	batchJob.Labels = labels
 	return batchJob
 }
 
+// default timeout of the validator job, validating the config takes a few seconds
+const defaultValidatorTimeoutSeconds = 300
+
+/*
+create job spec for a job running renovate-config-validator with the environment of the discovery job.
+the job is annotated with the fingerprint of the config it validates and is not retried.
+*/
+func newValidatorJob(job *api.RenovateJob, fingerprint string) *batchv1.Job {
+	batchJob := newDiscoveryJob(job)
+	container := &batchJob.Spec.Template.Spec.Containers[0]
+	container.Name = "validator"
+	container.Command = []string{"renovate-config-validator"}
+	container.Args = nil
+	// an empty working directory, so only the global config is validated
+	container.WorkingDir = "/tmp"
+
+	batchJob.Spec.ActiveDeadlineSeconds = ptr.To(int64(defaultValidatorTimeoutSeconds))
+	if job.Spec.ConfigValidation != nil && job.Spec.ConfigValidation.TimeoutSeconds != nil {
+		batchJob.Spec.ActiveDeadlineSeconds = ptr.To(*job.Spec.ConfigValidation.TimeoutSeconds)
+	}
+	batchJob.Spec.BackoffLimit = ptr.To(int32(0))
+
+	jobName := utils.ValidatorJobName(job)
+	batchJob.GenerateName = jobName
+	annotations := maps.Clone(batchJob.Annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[crdmanager.JOB_ANNOTATION_CONFIG_FINGERPRINT] = fingerprint
+	batchJob.Annotations = annotations
+	labels := getJobLabels(job.Spec.Metadata, crdmanager.ValidatorJobType, jobName)
+	batchJob.Spec.Template.Labels = labels
+	batchJob.Labels = labels
+	return batchJob
+}
+
 // create a Job spec for renovate run on project...
 func newRenovateJob(job *api.RenovateJob, project string) *batchv1.Job {
+	return newExecutorJob(job, []string{project})
+}
+
+/*
+create a Job spec for a renovate run on several projects at once.
+the projects must not have project overrides and share the same cache volume.
+*/
+func newRenovateBatchJob(job *api.RenovateJob, projects []string) *batchv1.Job {
+	return newExecutorJob(job, projects)
+}
+
+func newExecutorJob(job *api.RenovateJob, projects []string) *batchv1.Job {
+	project := projects[0]
 	predefinedEnvVars := getDefaultEnvVars(job)
+	override := utils.GetProjectOverride(&job.Spec, project)
 
 	envFromSecrets := []v1.EnvFromSource{}
 	if job.Spec.SecretRef != "" {
@@ -144,26 +200,52 @@ func newRenovateJob(job *api.RenovateJob, project string) *batchv1.Job {
 			MountPath: "/tmp",
 		},
 	}
+	volumes, volumeMounts = appendRenovateConfigVolume(job, volumes, volumeMounts)
+
+	var initContainers []v1.Container
+	if job.Spec.Cache != nil {
+		volumes = append(volumes, v1.Volume{
+			Name: "cache",
+			VolumeSource: v1.VolumeSource{
+				PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+					ClaimName: utils.CacheVolumeName(job, utils.GetCacheShard(&job.Spec, project)),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      "cache",
+			MountPath: cacheDir,
+		})
+		predefinedEnvVars = append(predefinedEnvVars, v1.EnvVar{
+			Name:  "RENOVATE_CACHE_DIR",
+			Value: cacheDir,
+		}, v1.EnvVar{
+			Name:  "RENOVATE_REPOSITORY_CACHE",
+			Value: "enabled",
+		})
+		initContainers = append(initContainers, newCacheCleanupContainer(job, override))
+	}
 
 	batchJob := &batchv1.Job{
 		Spec: batchv1.JobSpec{
-			ActiveDeadlineSeconds:   getJobTimeoutSeconds(),
-			BackoffLimit:            getJobBackOffLimit(),
-			TTLSecondsAfterFinished: getJobTTLSecondsAfterFinished(),
+			ActiveDeadlineSeconds:   getProjectTimeoutSeconds(job.Spec, override),
+			BackoffLimit:            getJobBackOffLimit(job.Spec.ExecutorJob),
+			TTLSecondsAfterFinished: getJobTTLSecondsAfterFinished(job.Spec.ExecutorJob),
 			Template: v1.PodTemplateSpec{
 				Spec: v1.PodSpec{
 					ServiceAccountName:            getServiceAccountName(job.Spec),
-					ImagePullSecrets:              append(job.Spec.ImagePullSecrets, getDefaultImagePullSecrets()...),
+					ImagePullSecrets:              slices.Concat(job.Spec.ImagePullSecrets, getDefaultImagePullSecrets()),
 					TerminationGracePeriodSeconds: ptr.To(int64(0)),
+					InitContainers:                initContainers,
 					Containers: []v1.Container{
 						{
 							Name:            "renovate",
 							Command:         []string{"renovate"},
-							Args:            []string{"--base-dir", "/tmp", project},
-							Image:           job.Spec.Image,
-							Env:             mergeEnvVars(job.Spec.ExtraEnv, predefinedEnvVars),
+							Args:            append([]string{"--base-dir", "/tmp"}, projects...),
+							Image:           getProjectImage(job.Spec, override),
+							Env:             mergeEnvVars(mergeEnvVars(override.ExtraEnv, job.Spec.ExtraEnv), predefinedEnvVars),
 							EnvFrom:         envFromSecrets,
-							Resources:       job.Spec.Resources,
+							Resources:       getProjectResources(job.Spec, override),
 							VolumeMounts:    append(volumeMounts, job.Spec.ExtraVolumeMounts...),
 							SecurityContext: getContainerSecurityContext(job.Spec),
 						},
@@ -172,7 +254,7 @@ func newRenovateJob(job *api.RenovateJob, project string) *batchv1.Job {
 					AutomountServiceAccountToken: getAutoMountServiceAccountToken(job.Spec),
 					RestartPolicy:                v1.RestartPolicyNever,
 					DNSPolicy:                    getDNSPolicy(job.Spec),
-					NodeSelector:                 job.Spec.NodeSelector,
+					NodeSelector:                 getProjectNodeSelector(job.Spec, override),
 					Affinity:                     job.Spec.Affinity,
 					Tolerations:                  job.Spec.Tolerations,
 					TopologySpreadConstraints:    job.Spec.TopologySpreadConstraints,
@@ -183,18 +265,185 @@ func newRenovateJob(job *api.RenovateJob, project string) *batchv1.Job {
 	}
 
 	jobName := utils.ExecutorJobName(job, project)
+	if len(projects) > 1 {
+		jobName = utils.BatchJobName(job, projects)
+	}
 	batchJob.GenerateName = jobName
 	batchJob.Namespace = job.Namespace
 	if job.Spec.Metadata != nil {
 		batchJob.Spec.Template.Annotations = job.Spec.Metadata.Annotations
 		batchJob.Annotations = job.Spec.Metadata.Annotations
 	}
+	setRenovateConfigHash(batchJob, job)
 	labels := getJobLabels(job.Spec.Metadata, crdmanager.ExecutorJobType, jobName)
 	batchJob.Labels = labels
 	batchJob.Spec.Template.Labels = labels
+	if len(projects) > 1 {
+		setBatchProjects(batchJob, job, projects)
+	}
 	return batchJob
 }
 
+// mark the projects run by a batch job, so the job of each project can be found
+func setBatchProjects(batchJob *batchv1.Job, job *api.RenovateJob, projects []string) {
+	labels := maps.Clone(batchJob.Labels)
+	for _, project := range projects {
+		labels[crdmanager.JOB_LABEL_BATCH_PREFIX+utils.ExecutorJobName(job, project)] = "true"
+	}
+	batchJob.Labels = labels
+
+	annotations := maps.Clone(batchJob.Annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	raw, _ := json.Marshal(projects)
+	annotations[crdmanager.JOB_ANNOTATION_BATCH_PROJECTS] = string(raw)
+	batchJob.Annotations = annotations
+}
+
+// mount path of the ConfigMap holding the inline renovate config
+const renovateConfigDir = "/etc/renovate"
+
+// mount the ConfigMap holding the inline renovate config of the renovatejob
+func appendRenovateConfigVolume(job *api.RenovateJob, volumes []v1.Volume, volumeMounts []v1.VolumeMount) ([]v1.Volume, []v1.VolumeMount) {
+	if job.Spec.RenovateConfig == nil {
+		return volumes, volumeMounts
+	}
+	volumes = append(volumes, v1.Volume{
+		Name: "renovate-config",
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: utils.RenovateConfigName(job),
+				},
+			},
+		},
+	})
+	volumeMounts = append(volumeMounts, v1.VolumeMount{
+		Name:      "renovate-config",
+		MountPath: renovateConfigDir,
+		ReadOnly:  true,
+	})
+	return volumes, volumeMounts
+}
+
+// annotate the pods with the hash of the inline renovate config they are started with
+func setRenovateConfigHash(batchJob *batchv1.Job, job *api.RenovateJob) {
+	hash := utils.RenovateConfigHash(&job.Spec)
+	if hash == "" {
+		return
+	}
+	annotations := maps.Clone(batchJob.Spec.Template.Annotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[crdmanager.CONFIG_ANNOTATION_HASH] = hash
+	batchJob.Spec.Template.Annotations = annotations
+}
+
+// mount path of the cache volume in the executor pods
+const cacheDir = "/cache"
+
+/*
+script removing outdated files from the cache volume once its usage exceeds the threshold.
+files not modified for a week are removed first, the whole cache if that is not sufficient.
+*/
+const cacheCleanupScript = `usage() { df -P %[1]s | awk 'NR==2 { sub("%%", "", $5); print $5 }'; }
+if [ "$(usage)" -ge %[2]d ]; then
+  echo "cache usage of $(usage)%% exceeds %[2]d%%, removing files not modified within 7 days"
+  find %[1]s -mindepth 1 -type f -mtime +7 -delete
+fi
+if [ "$(usage)" -ge %[2]d ]; then
+  echo "cache usage of $(usage)%% still exceeds %[2]d%%, removing the whole cache"
+  find %[1]s -mindepth 1 -delete
+fi
+exit 0`
+
+// init container cleaning up the cache volume before renovate starts
+func newCacheCleanupContainer(job *api.RenovateJob, override api.RenovateProjectOverride) v1.Container {
+	return v1.Container{
+		Name:    "cache-cleanup",
+		Command: []string{"/bin/sh", "-c"},
+		Args:    []string{fmt.Sprintf(cacheCleanupScript, cacheDir, utils.GetCacheCleanupThreshold(job.Spec.Cache))},
+		Image:   getProjectImage(job.Spec, override),
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      "cache",
+				MountPath: cacheDir,
+			},
+		},
+		SecurityContext: getContainerSecurityContext(job.Spec),
+	}
+}
+
+/*
+strategically merge a pod template over the pod template of a generated job.
+only the fields set in the template are changed, lists like containers and volumes are merged by name.
+*/
+func applyPodTemplate(job *batchv1.Job, template *runtime.RawExtension) error {
+	if template == nil || len(template.Raw) == 0 {
+		return nil
+	}
+	original, err := json.Marshal(job.Spec.Template)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pod template: %w", err)
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, template.Raw, v1.PodTemplateSpec{})
+	if err != nil {
+		return fmt.Errorf("failed to merge pod template: %w", err)
+	}
+	var podTemplate v1.PodTemplateSpec
+	if err := json.Unmarshal(merged, &podTemplate); err != nil {
+		return fmt.Errorf("invalid pod template: %w", err)
+	}
+	job.Spec.Template = podTemplate
+	return nil
+}
+
+// pod template merged over the discovery pods
+func getDiscoveryPodTemplate(spec api.RenovateJobSpec) *runtime.RawExtension {
+	if spec.PodTemplate == nil {
+		return nil
+	}
+	return spec.PodTemplate.Discovery
+}
+
+// pod template merged over the executor pods
+func getExecutorPodTemplate(spec api.RenovateJobSpec) *runtime.RawExtension {
+	if spec.PodTemplate == nil {
+		return nil
+	}
+	return spec.PodTemplate.Executor
+}
+
+func getProjectImage(spec api.RenovateJobSpec, override api.RenovateProjectOverride) string {
+	if override.Image != "" {
+		return override.Image
+	}
+	return spec.Image
+}
+
+func getProjectResources(spec api.RenovateJobSpec, override api.RenovateProjectOverride) v1.ResourceRequirements {
+	if override.Resources != nil {
+		return *override.Resources
+	}
+	return spec.Resources
+}
+
+func getProjectNodeSelector(spec api.RenovateJobSpec, override api.RenovateProjectOverride) map[string]string {
+	if override.NodeSelector != nil {
+		return override.NodeSelector
+	}
+	return spec.NodeSelector
+}
+
+func getProjectTimeoutSeconds(spec api.RenovateJobSpec, override api.RenovateProjectOverride) *int64 {
+	if override.TimeoutSeconds != nil {
+		return ptr.To(*override.TimeoutSeconds)
+	}
+	return getJobTimeoutSeconds(spec.ExecutorJob)
+}
+
 func getDefaultEnvVars(job *api.RenovateJob) []v1.EnvVar {
 
 	predefinedEnvVars := []v1.EnvVar{
@@ -219,6 +468,13 @@ func getDefaultEnvVars(job *api.RenovateJob) []v1.EnvVar {
 		})
 	}
 
+	if job.Spec.RenovateConfig != nil {
+		predefinedEnvVars = append(predefinedEnvVars, v1.EnvVar{
+			Name:  "RENOVATE_CONFIG_FILE",
+			Value: renovateConfigDir + "/" + crdmanager.RENOVATE_CONFIG_KEY,
+		})
+	}
+
 	if job.Status.ExecutionOptions != nil && job.Status.ExecutionOptions.Debug {
 		predefinedEnvVars = append(predefinedEnvVars, v1.EnvVar{
 			Name:  "LOG_LEVEL",
@@ -278,7 +534,11 @@ func getServiceAccountName(spec api.RenovateJobSpec) string {
 	return ""
 }
 
-func getJobTimeoutSeconds() *int64 {
+// timeout of the renovatejob, falls back to the operator configuration
+func getJobTimeoutSeconds(limits *api.RenovateJobLimits) *int64 {
+	if limits != nil && limits.TimeoutSeconds != nil {
+		return ptr.To(*limits.TimeoutSeconds)
+	}
 	timeoutString := config.GetValue("JOB_TIMEOUT_SECONDS")
 	val, err := strconv.ParseInt(timeoutString, 10, 64)
 	if err != nil {
@@ -287,7 +547,11 @@ func getJobTimeoutSeconds() *int64 {
 	return ptr.To(val)
 }
 
-func getJobBackOffLimit() *int32 {
+// backoff limit of the renovatejob, falls back to the operator configuration
+func getJobBackOffLimit(limits *api.RenovateJobLimits) *int32 {
+	if limits != nil && limits.BackoffLimit != nil {
+		return ptr.To(*limits.BackoffLimit)
+	}
 	timeoutString := config.GetValue("JOB_BACKOFF_LIMIT")
 	val, err := strconv.ParseInt(timeoutString, 10, 32)
 	if err != nil {
@@ -296,7 +560,11 @@ func getJobBackOffLimit() *int32 {
 	return ptr.To(int32(val))
 }
 
-func getJobTTLSecondsAfterFinished() *int32 {
+// ttl of the renovatejob, falls back to the operator configuration where -1 disables the ttl
+func getJobTTLSecondsAfterFinished(limits *api.RenovateJobLimits) *int32 {
+	if limits != nil && limits.TTLSecondsAfterFinished != nil {
+		return ptr.To(*limits.TTLSecondsAfterFinished)
+	}
 	timeoutString := config.GetValue("JOB_TTL_SECONDS_AFTER_FINISHED")
 
 	if timeoutString == "-1" {
@@ -2,16 +2,19 @@ package renovate
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/config"
 	crdManager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/utils"
 
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/ptr"
 )
 
@@ -568,3 +571,355 @@ func expectTopologySpreadConstraints(t *testing.T, job *batchv1.Job, expectedCon
 		t.Fatalf("topology spread constraints mismatch:\nexpected: %+v\ngot:      %+v", expectedConstraints, job.Spec.Template.Spec.TopologySpreadConstraints)
 	}
 }
+
+func TestNewRenovateJob_ProjectOverrides(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
+		Spec: api.RenovateJobSpec{
+			Image: "renovate/renovate:latest",
+			Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+			},
+			ExtraEnv:     []v1.EnvVar{{Name: "A", Value: "job"}, {Name: "B", Value: "job"}},
+			NodeSelector: map[string]string{"pool": "default"},
+			ProjectOverrides: []api.RenovateProjectOverride{
+				{
+					Project: "org/mono*",
+					Image:   "renovate/renovate:full",
+					Resources: &v1.ResourceRequirements{
+						Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")},
+					},
+					ExtraEnv:       []v1.EnvVar{{Name: "B", Value: "override"}},
+					NodeSelector:   map[string]string{"pool": "large"},
+					TimeoutSeconds: ptr.To(int64(7200)),
+				},
+			},
+		},
+	}
+
+	monorepo := newRenovateJob(job, "org/monorepo").Spec.Template.Spec
+	container := monorepo.Containers[0]
+	if container.Image != "renovate/renovate:full" {
+		t.Errorf("expected the image of the override, got %s", container.Image)
+	}
+	if got := container.Resources.Limits[v1.ResourceMemory]; got.String() != "4Gi" {
+		t.Errorf("expected the memory limit of the override, got %s", got.String())
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if env["A"] != "job" || env["B"] != "override" {
+		t.Errorf("expected extraEnv of the override to take precedence, got %v", env)
+	}
+	if monorepo.NodeSelector["pool"] != "large" {
+		t.Errorf("expected the node selector of the override, got %v", monorepo.NodeSelector)
+	}
+	if deadline := newRenovateJob(job, "org/monorepo").Spec.ActiveDeadlineSeconds; deadline == nil || *deadline != 7200 {
+		t.Errorf("expected the timeout of the override, got %v", deadline)
+	}
+
+	other := newRenovateJob(job, "org/api")
+	if other.Spec.Template.Spec.Containers[0].Image != "renovate/renovate:latest" {
+		t.Errorf("expected the image of the job, got %s", other.Spec.Template.Spec.Containers[0].Image)
+	}
+	if other.Spec.Template.Spec.NodeSelector["pool"] != "default" {
+		t.Errorf("expected the node selector of the job, got %v", other.Spec.Template.Spec.NodeSelector)
+	}
+	if deadline := other.Spec.ActiveDeadlineSeconds; deadline == nil || *deadline != *getJobTimeoutSeconds(nil) {
+		t.Errorf("expected the default timeout, got %v", deadline)
+	}
+}
+
+func TestNewJobs_WithJobLimits(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			Image: "renovate:dev",
+			DiscoveryJob: &api.RenovateJobLimits{
+				TimeoutSeconds: ptr.To(int64(300)),
+			},
+			ExecutorJob: &api.RenovateJobLimits{
+				TimeoutSeconds:          ptr.To(int64(3600)),
+				BackoffLimit:            ptr.To(int32(2)),
+				TTLSecondsAfterFinished: ptr.To(int32(0)),
+			},
+		},
+	}
+	err := config.InitializeConfigModule([]config.ConfigItemDescription{
+		{Key: "JOB_TIMEOUT_SECONDS", Optional: true, Default: "10"},
+		{Key: "JOB_BACKOFF_LIMIT", Optional: true, Default: "0"},
+		{Key: "JOB_TTL_SECONDS_AFTER_FINISHED", Optional: true, Default: "360"},
+	})
+	if err != nil {
+		t.Fatalf("expected to initialize config module without error, got %v", err)
+	}
+
+	// the discovery job falls back to the operator configuration for unset limits
+	dj := newDiscoveryJob(job)
+	expectActiveDeadlineSeconds(t, dj, 300)
+	if dj.Spec.BackoffLimit == nil || *dj.Spec.BackoffLimit != 0 {
+		t.Errorf("expected the backoff limit of the operator, got %v", dj.Spec.BackoffLimit)
+	}
+	expectTtlSecondsAfterFinished(t, dj, ptr.To(int32(360)))
+
+	rj := newRenovateJob(job, "proj")
+	expectActiveDeadlineSeconds(t, rj, 3600)
+	if rj.Spec.BackoffLimit == nil || *rj.Spec.BackoffLimit != 2 {
+		t.Errorf("expected the backoff limit of the executor job, got %v", rj.Spec.BackoffLimit)
+	}
+	expectTtlSecondsAfterFinished(t, rj, ptr.To(int32(0)))
+
+	// project overrides take precedence over the executor timeout
+	job.Spec.ProjectOverrides = []api.RenovateProjectOverride{{Project: "proj", TimeoutSeconds: ptr.To(int64(7200))}}
+	expectActiveDeadlineSeconds(t, newRenovateJob(job, "proj"), 7200)
+}
+
+func TestNewRenovateJob_WithCache(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "cached", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			Image: "renovate:dev",
+			Cache: &api.RenovateCache{Size: resource.MustParse("5Gi"), CleanupThreshold: 90},
+			ExtraEnv: []v1.EnvVar{
+				{Name: "RENOVATE_REPOSITORY_CACHE", Value: "reset"},
+			},
+		},
+	}
+
+	rj := newRenovateJob(job, "org/repo")
+	podSpec := rj.Spec.Template.Spec
+	var claim string
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == "cache" && volume.PersistentVolumeClaim != nil {
+			claim = volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	if claim != utils.CacheVolumeName(job, 0) {
+		t.Errorf("expected the cache volume %s to be mounted, got %q", utils.CacheVolumeName(job, 0), claim)
+	}
+
+	container := podSpec.Containers[0]
+	if !reflect.DeepEqual(container.VolumeMounts[len(container.VolumeMounts)-1], v1.VolumeMount{Name: "cache", MountPath: "/cache"}) {
+		t.Errorf("expected the cache volume to be mounted at /cache, got %v", container.VolumeMounts)
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if env["RENOVATE_CACHE_DIR"] != "/cache" {
+		t.Errorf("expected RENOVATE_CACHE_DIR to point to the cache volume, got %q", env["RENOVATE_CACHE_DIR"])
+	}
+	if env["RENOVATE_REPOSITORY_CACHE"] != "reset" {
+		t.Errorf("expected extraEnv to take precedence over the repository cache setting, got %q", env["RENOVATE_REPOSITORY_CACHE"])
+	}
+
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "cache-cleanup" {
+		t.Fatalf("expected a cache cleanup init container, got %v", podSpec.InitContainers)
+	}
+	if script := podSpec.InitContainers[0].Args[0]; !strings.Contains(script, "-ge 90") || strings.Contains(script, "%%") {
+		t.Errorf("unexpected cleanup script: %s", script)
+	}
+
+	// no cache volume without cache configuration
+	job.Spec.Cache = nil
+	if rj := newRenovateJob(job, "org/repo"); len(rj.Spec.Template.Spec.InitContainers) != 0 || len(rj.Spec.Template.Spec.Volumes) != 1 {
+		t.Errorf("expected no cache volume without cache configuration")
+	}
+}
+
+func TestNewRenovateBatchJob(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "batched", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			Image:     "renovate:dev",
+			BatchSize: 2,
+			Metadata: &api.RenovateJobMetadata{
+				Annotations: map[string]string{"team": "platform"},
+			},
+		},
+	}
+	projects := []string{"org/a", "org/b"}
+
+	bj := newRenovateBatchJob(job, projects)
+	if !reflect.DeepEqual(bj.Spec.Template.Spec.Containers[0].Args, []string{"--base-dir", "/tmp", "org/a", "org/b"}) {
+		t.Errorf("expected all projects as arguments, got %v", bj.Spec.Template.Spec.Containers[0].Args)
+	}
+	if bj.GenerateName != utils.BatchJobName(job, projects) || bj.Labels[crdManager.JOB_LABEL_NAME] != utils.BatchJobName(job, projects) {
+		t.Errorf("expected the batch job name, got %s", bj.GenerateName)
+	}
+	for _, project := range projects {
+		if bj.Labels[crdManager.JOB_LABEL_BATCH_PREFIX+utils.ExecutorJobName(job, project)] != "true" {
+			t.Errorf("expected the batch job to be labeled with project %s, got %v", project, bj.Labels)
+		}
+	}
+	if got := crdManager.GetBatchProjects(bj); !reflect.DeepEqual(got, projects) {
+		t.Errorf("expected the batch projects to be annotated, got %v", got)
+	}
+	if bj.Annotations["team"] != "platform" {
+		t.Errorf("expected the metadata annotations to be kept, got %v", bj.Annotations)
+	}
+	if _, ok := job.Spec.Metadata.Annotations[crdManager.JOB_ANNOTATION_BATCH_PROJECTS]; ok {
+		t.Errorf("expected the annotations of the renovatejob not to be modified")
+	}
+
+	// a batch of a single project is a regular executor job
+	if single := newRenovateBatchJob(job, projects[:1]); crdManager.GetBatchProjects(single) != nil || single.GenerateName != utils.ExecutorJobName(job, "org/a") {
+		t.Errorf("expected a regular executor job for a single project, got %s", single.GenerateName)
+	}
+}
+
+func TestApplyPodTemplate(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "templated", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			Image: "renovate:dev",
+			PodTemplate: &api.RenovatePodTemplates{
+				Executor: &runtime.RawExtension{Raw: []byte(`{
+					"metadata": {"annotations": {"sidecar.istio.io/inject": "false"}},
+					"spec": {
+						"priorityClassName": "renovate-low",
+						"hostAliases": [{"ip": "10.0.0.1", "hostnames": ["git.internal"]}],
+						"containers": [
+							{"name": "renovate", "env": [{"name": "HTTPS_PROXY", "value": "http://localhost:3128"}]},
+							{"name": "proxy", "image": "squid:latest"}
+						]
+					}
+				}`)},
+			},
+		},
+	}
+
+	rj := newRenovateJob(job, "org/repo")
+	if err := applyPodTemplate(rj, getExecutorPodTemplate(job.Spec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	podSpec := rj.Spec.Template.Spec
+	if podSpec.PriorityClassName != "renovate-low" || len(podSpec.HostAliases) != 1 {
+		t.Errorf("expected the pod settings of the template, got %+v", podSpec)
+	}
+	if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != "proxy" {
+		t.Fatalf("expected the sidecar to be added, got %v", podSpec.Containers)
+	}
+	renovate := podSpec.Containers[0]
+	if renovate.Image != "renovate:dev" || !reflect.DeepEqual(renovate.Args, []string{"--base-dir", "/tmp", "org/repo"}) {
+		t.Errorf("expected the generated container to be kept, got %+v", renovate)
+	}
+	env := map[string]string{}
+	for _, e := range renovate.Env {
+		env[e.Name] = e.Value
+	}
+	if env["HTTPS_PROXY"] != "http://localhost:3128" || env["LOG_FORMAT"] != "json" {
+		t.Errorf("expected the environment to be merged, got %v", renovate.Env)
+	}
+	if rj.Spec.Template.Annotations["sidecar.istio.io/inject"] != "false" {
+		t.Errorf("expected the template annotations, got %v", rj.Spec.Template.Annotations)
+	}
+	if rj.Spec.Template.Labels[crdManager.JOB_LABEL_TYPE] != string(crdManager.ExecutorJobType) {
+		t.Errorf("expected the generated labels to be kept, got %v", rj.Spec.Template.Labels)
+	}
+
+	// the discovery pods are not changed by the executor template
+	dj := newDiscoveryJob(job)
+	if err := applyPodTemplate(dj, getDiscoveryPodTemplate(job.Spec)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dj.Spec.Template.Spec.PriorityClassName != "" || len(dj.Spec.Template.Spec.Containers) != 1 {
+		t.Errorf("expected the discovery pod to be unchanged, got %+v", dj.Spec.Template.Spec)
+	}
+
+	invalid := &runtime.RawExtension{Raw: []byte(`{"spec": {"priorityClassName": 1}}`)}
+	if err := applyPodTemplate(newRenovateJob(job, "org/repo"), invalid); err == nil {
+		t.Error("expected an error for an invalid pod template")
+	}
+}
+
+func TestNewJobs_WithRenovateConfig(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "configured", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			Image:          "renovate:dev",
+			RenovateConfig: &runtime.RawExtension{Raw: []byte(`{"onboarding":false}`)},
+			Metadata: &api.RenovateJobMetadata{
+				Annotations: map[string]string{"team": "platform"},
+			},
+		},
+	}
+
+	for _, batchJob := range []*batchv1.Job{newDiscoveryJob(job), newRenovateJob(job, "org/repo")} {
+		podSpec := batchJob.Spec.Template.Spec
+		var configMap string
+		for _, volume := range podSpec.Volumes {
+			if volume.Name == "renovate-config" && volume.ConfigMap != nil {
+				configMap = volume.ConfigMap.Name
+			}
+		}
+		if configMap != utils.RenovateConfigName(job) {
+			t.Errorf("expected the ConfigMap %s to be mounted, got %q", utils.RenovateConfigName(job), configMap)
+		}
+		container := podSpec.Containers[0]
+		if !reflect.DeepEqual(container.VolumeMounts[len(container.VolumeMounts)-1], v1.VolumeMount{Name: "renovate-config", MountPath: "/etc/renovate", ReadOnly: true}) {
+			t.Errorf("expected the config to be mounted at /etc/renovate, got %v", container.VolumeMounts)
+		}
+		var configFile string
+		for _, env := range container.Env {
+			if env.Name == "RENOVATE_CONFIG_FILE" {
+				configFile = env.Value
+			}
+		}
+		if configFile != "/etc/renovate/config.json" {
+			t.Errorf("expected RENOVATE_CONFIG_FILE to point to the mounted config, got %q", configFile)
+		}
+		annotations := batchJob.Spec.Template.Annotations
+		if annotations[crdManager.CONFIG_ANNOTATION_HASH] != utils.RenovateConfigHash(&job.Spec) || annotations["team"] != "platform" {
+			t.Errorf("expected the pod to be annotated with the config hash, got %v", annotations)
+		}
+	}
+	if _, ok := job.Spec.Metadata.Annotations[crdManager.CONFIG_ANNOTATION_HASH]; ok {
+		t.Error("expected the annotations of the renovatejob not to be modified")
+	}
+}
+
+func TestNewValidatorJob(t *testing.T) {
+	job := &api.RenovateJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "validated", Namespace: "ns"},
+		Spec: api.RenovateJobSpec{
+			Image:            "renovate:dev",
+			SecretRef:        "renovate-secret",
+			ConfigValidation: &api.RenovateConfigValidation{Mode: api.ConfigValidationModeBlock},
+			Metadata: &api.RenovateJobMetadata{
+				Annotations: map[string]string{"team": "platform"},
+			},
+		},
+	}
+	err := config.InitializeConfigModule([]config.ConfigItemDescription{{Key: "JOB_TIMEOUT_SECONDS", Optional: true, Default: "10"}})
+	if err != nil {
+		t.Fatalf("expected to initialize config module without error, got %v", err)
+	}
+
+	validatorJob := newValidatorJob(job, "abc")
+	container := validatorJob.Spec.Template.Spec.Containers[0]
+	if container.Name != "validator" || !reflect.DeepEqual(container.Command, []string{"renovate-config-validator"}) || container.Args != nil {
+		t.Errorf("unexpected validator container: %s %v %v", container.Name, container.Command, container.Args)
+	}
+	if container.Image != "renovate:dev" || len(container.EnvFrom) != 1 || container.EnvFrom[0].SecretRef.Name != "renovate-secret" {
+		t.Errorf("expected the image and secrets of the renovatejob, got %s %v", container.Image, container.EnvFrom)
+	}
+	if *validatorJob.Spec.ActiveDeadlineSeconds != 300 || *validatorJob.Spec.BackoffLimit != 0 {
+		t.Errorf("unexpected limits: %d %d", *validatorJob.Spec.ActiveDeadlineSeconds, *validatorJob.Spec.BackoffLimit)
+	}
+	if validatorJob.GenerateName != utils.ValidatorJobName(job) || validatorJob.Labels[crdManager.JOB_LABEL_TYPE] != string(crdManager.ValidatorJobType) {
+		t.Errorf("unexpected name or labels: %s %v", validatorJob.GenerateName, validatorJob.Labels)
+	}
+	if validatorJob.Annotations[crdManager.JOB_ANNOTATION_CONFIG_FINGERPRINT] != "abc" || validatorJob.Annotations["team"] != "platform" {
+		t.Errorf("unexpected annotations: %v", validatorJob.Annotations)
+	}
+	if _, ok := job.Spec.Metadata.Annotations[crdManager.JOB_ANNOTATION_CONFIG_FINGERPRINT]; ok {
+		t.Error("expected the annotations of the renovatejob not to be modified")
+	}
+
+	job.Spec.ConfigValidation.TimeoutSeconds = ptr.To(int64(60))
+	if validatorJob := newValidatorJob(job, "abc"); *validatorJob.Spec.ActiveDeadlineSeconds != 60 {
+		t.Errorf("expected the configured timeout, got %d", *validatorJob.Spec.ActiveDeadlineSeconds)
+	}
+}
@@ -0,0 +1,190 @@
+package renovate
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	api "renovate-operator/api/v1alpha1"
+	"renovate-operator/config"
+	crdManager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/utils"
+)
+
+// priority stale projects are boosted to, equal to projects triggered by a webhook
+const stalePriority int32 = 1
+
+// ExecutionBudget limits the number of executor jobs running at the same time across all RenovateJobs
+type ExecutionBudget struct {
+	// maximum number of running executor jobs, 0 means unlimited
+	MaxRunning int
+	// maximum number of running executor jobs per namespace, 0 means unlimited
+	MaxRunningPerNamespace int
+}
+
+// read the execution budget from the operator configuration
+func GetExecutionBudget() ExecutionBudget {
+	return ExecutionBudget{
+		MaxRunning:             getIntConfigValue("MAX_CONCURRENT_EXECUTOR_JOBS"),
+		MaxRunningPerNamespace: getIntConfigValue("MAX_CONCURRENT_EXECUTOR_JOBS_PER_NAMESPACE"),
+	}
+}
+
+func getIntConfigValue(key string) int {
+	val, err := strconv.Atoi(config.GetValue(key))
+	if err != nil || val < 0 {
+		return 0
+	}
+	return val
+}
+
+// a RenovateJob with the current state of its projects
+type QueuedRenovateJob struct {
+	Job      *api.RenovateJob
+	Projects []crdManager.RenovateProjectStatus
+	// number of running executor jobs, projects of a batch share a job.
+	// if not known, the running projects are counted as full batches
+	RunningJobs int
+}
+
+// a scheduled project and its position in the execution queue
+type QueueEntry struct {
+	Job     *api.RenovateJob
+	Project string
+	// position in the queue, starting at 1
+	Position int
+}
+
+/*
+BuildQueue orders the scheduled projects of all RenovateJobs in the order they get started.
+Free slots are handed out in a weighted round robin: the RenovateJob with the fewest running
+or already queued executor jobs in relation to its weight gets the next slot. Like the execution budget,
+the share counts executor jobs, a queued project takes the fraction of a job it gets when batched.
+Within a RenovateJob, projects are ordered by priority, raised to the priority of their project overrides.
+Stale projects and retries of failed projects are started before regular scheduled projects of the same priority.
+Projects whose spread start time has not been reached at the given time are not queued yet.
+*/
+func BuildQueue(jobs []QueuedRenovateJob, now time.Time) []QueueEntry {
+	type jobQueue struct {
+		job       *api.RenovateJob
+		weight    float64
+		assigned  float64
+		scheduled []crdManager.RenovateProjectStatus
+	}
+
+	queues := make([]*jobQueue, 0, len(jobs))
+	total := 0
+	for _, job := range jobs {
+		q := &jobQueue{
+			job:    job.Job,
+			weight: float64(getWeight(job.Job)),
+		}
+		runningProjects := 0
+		for _, project := range job.Projects {
+			switch project.Status {
+			case api.JobStatusRunning:
+				runningProjects++
+			case api.JobStatusScheduled:
+				// suspended projects keep their status, but are not started
+				if !project.Suspended && !isWaitingForSpread(project, now) {
+					// project overrides raise the priority of scheduled projects
+					if base := utils.GetProjectOverride(&job.Job.Spec, project.Name).Priority; base > project.Priority {
+						project.Priority = base
+					}
+					q.scheduled = append(q.scheduled, project)
+				}
+			}
+		}
+		if len(q.scheduled) == 0 || job.Job.Spec.Suspend {
+			continue
+		}
+		q.assigned = float64(job.RunningJobs)
+		if job.RunningJobs == 0 {
+			q.assigned = math.Ceil(float64(runningProjects) / float64(getBatchSize(job.Job)))
+		}
+		sort.SliceStable(q.scheduled, func(i, j int) bool {
+			return compareQueuePriority(job.Job, q.scheduled[i], q.scheduled[j], now)
+		})
+		queues = append(queues, q)
+		total += len(q.scheduled)
+	}
+
+	// sort by name, so jobs with the same share are served in a stable order
+	sort.SliceStable(queues, func(i, j int) bool {
+		return queues[i].job.Fullname() < queues[j].job.Fullname()
+	})
+
+	result := make([]QueueEntry, 0, total)
+	for len(result) < total {
+		var next *jobQueue
+		for _, q := range queues {
+			if len(q.scheduled) == 0 {
+				continue
+			}
+			if next == nil || q.assigned/q.weight < next.assigned/next.weight {
+				next = q
+			}
+		}
+
+		result = append(result, QueueEntry{
+			Job:      next.job,
+			Project:  next.scheduled[0].Name,
+			Position: len(result) + 1,
+		})
+		next.assigned += getExecutorJobShare(next.job, next.scheduled[0].Name)
+		next.scheduled = next.scheduled[1:]
+	}
+	return result
+}
+
+// fraction of an executor job used by a project, projects of a batch share a job
+func getExecutorJobShare(job *api.RenovateJob, project string) float64 {
+	if !isBatchable(job, project) {
+		return 1
+	}
+	return 1 / float64(getBatchSize(job))
+}
+
+/*
+whether project a is started before project b of the same RenovateJob.
+stale projects are boosted to the priority of webhook triggered projects and started first within that priority,
+retries are started before the other projects of their priority
+*/
+func compareQueuePriority(job *api.RenovateJob, a crdManager.RenovateProjectStatus, b crdManager.RenovateProjectStatus, now time.Time) bool {
+	aStale := utils.IsProjectStale(&job.Spec, a.LastSuccess, now)
+	bStale := utils.IsProjectStale(&job.Spec, b.LastSuccess, now)
+	aPriority := getQueuePriority(a.Priority, aStale)
+	bPriority := getQueuePriority(b.Priority, bStale)
+	if aPriority != bPriority {
+		return aPriority > bPriority
+	}
+	if aStale != bStale {
+		return aStale
+	}
+	return isRetry(a) && !isRetry(b)
+}
+
+// scheduled projects keep their attempts only if they are scheduled as a retry, any other scheduling resets them
+func isRetry(project crdManager.RenovateProjectStatus) bool {
+	return project.Attempts > 0
+}
+
+func getQueuePriority(priority int32, stale bool) int32 {
+	if stale && priority < stalePriority {
+		return stalePriority
+	}
+	return priority
+}
+
+// whether the start of the scheduled project is spread to a later point in time
+func isWaitingForSpread(project crdManager.RenovateProjectStatus, now time.Time) bool {
+	return project.NotBefore != nil && now.Before(*project.NotBefore)
+}
+
+func getWeight(job *api.RenovateJob) int32 {
+	if job.Spec.Weight < 1 {
+		return 1
+	}
+	return job.Spec.Weight
+}
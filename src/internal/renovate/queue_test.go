@@ -0,0 +1,221 @@
+package renovate
+
+import (
+	"testing"
+	"time"
+
+	api "renovate-operator/api/v1alpha1"
+	crdManager "renovate-operator/internal/crdManager"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func queuedJob(name string, weight int32, projects ...crdManager.RenovateProjectStatus) QueuedRenovateJob {
+	return QueuedRenovateJob{
+		Job: &api.RenovateJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       api.RenovateJobSpec{Weight: weight},
+		},
+		Projects: projects,
+	}
+}
+
+func suspendedJob(job QueuedRenovateJob) QueuedRenovateJob {
+	job.Job.Spec.Suspend = true
+	return job
+}
+
+func batchedJob(job QueuedRenovateJob, batchSize int32) QueuedRenovateJob {
+	job.Job.Spec.BatchSize = batchSize
+	return job
+}
+
+func scheduled(name string, priority int32) crdManager.RenovateProjectStatus {
+	return crdManager.RenovateProjectStatus{Name: name, Status: api.JobStatusScheduled, Priority: priority}
+}
+
+func running(name string) crdManager.RenovateProjectStatus {
+	return crdManager.RenovateProjectStatus{Name: name, Status: api.JobStatusRunning}
+}
+
+func queueOrder(entries []QueueEntry) []string {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.Job.Name+"/"+entry.Project)
+	}
+	return result
+}
+
+func TestBuildQueue_StaleProjects(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	old := now.Add(-48 * time.Hour)
+	// the stale project failed recently, only successful runs count
+	job := queuedJob("a", 0,
+		crdManager.RenovateProjectStatus{Name: "fresh", Status: api.JobStatusScheduled, LastRun: recent, LastSuccess: &recent},
+		crdManager.RenovateProjectStatus{Name: "webhook", Status: api.JobStatusScheduled, Priority: 1, LastRun: recent, LastSuccess: &recent},
+		crdManager.RenovateProjectStatus{Name: "ui", Status: api.JobStatusScheduled, Priority: 2, LastRun: recent, LastSuccess: &recent},
+		crdManager.RenovateProjectStatus{Name: "stale", Status: api.JobStatusScheduled, LastRun: recent, LastSuccess: &old},
+	)
+
+	got := queueOrder(BuildQueue([]QueuedRenovateJob{job}, now))
+	expected := []string{"a/ui", "a/webhook", "a/fresh", "a/stale"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected queue %v without max staleness, got %v", expected, got)
+		}
+	}
+
+	job.Job.Spec.MaxStaleness = &metav1.Duration{Duration: 24 * time.Hour}
+	got = queueOrder(BuildQueue([]QueuedRenovateJob{job}, now))
+	expected = []string{"a/ui", "a/stale", "a/webhook", "a/fresh"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected queue %v with max staleness, got %v", expected, got)
+		}
+	}
+}
+
+func TestBuildQueue_ProjectOverridePriority(t *testing.T) {
+	job := queuedJob("a", 0, scheduled("org/api", 0), scheduled("org/monorepo", 0), scheduled("org/web", 2))
+	job.Job.Spec.ProjectOverrides = []api.RenovateProjectOverride{{Project: "org/mono*", Priority: 1}}
+
+	got := queueOrder(BuildQueue([]QueuedRenovateJob{job}, time.Now()))
+	expected := []string{"a/org/web", "a/org/monorepo", "a/org/api"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected queue %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestBuildQueue_Retries(t *testing.T) {
+	job := queuedJob("a", 0,
+		scheduled("regular", 0),
+		crdManager.RenovateProjectStatus{Name: "retry", Status: api.JobStatusScheduled, Priority: retryPriority, Attempts: 1},
+		scheduled("webhook", 1),
+	)
+
+	got := queueOrder(BuildQueue([]QueuedRenovateJob{job}, time.Now()))
+	expected := []string{"a/webhook", "a/retry", "a/regular"}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected queue %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestBuildQueue(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Minute)
+	later := now.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		jobs     []QueuedRenovateJob
+		expected []string
+	}{
+		{
+			name: "round robin between jobs",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 0, scheduled("a1", 0), scheduled("a2", 0), scheduled("a3", 0)),
+				queuedJob("b", 0, scheduled("b1", 0)),
+			},
+			expected: []string{"a/a1", "b/b1", "a/a2", "a/a3"},
+		},
+		{
+			name: "running projects count towards the share",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 0, running("a0"), running("a1"), scheduled("a2", 0)),
+				queuedJob("b", 0, scheduled("b1", 0), scheduled("b2", 0)),
+			},
+			expected: []string{"b/b1", "b/b2", "a/a2"},
+		},
+		{
+			name: "running executor jobs count towards the share",
+			jobs: []QueuedRenovateJob{
+				func() QueuedRenovateJob {
+					job := batchedJob(queuedJob("a", 0, running("a0"), running("a1"), running("a2"), scheduled("a3", 0)), 3)
+					job.RunningJobs = 1
+					return job
+				}(),
+				queuedJob("b", 0, running("b0"), running("b1"), scheduled("b2", 0)),
+			},
+			expected: []string{"a/a3", "b/b2"},
+		},
+		{
+			name: "batched projects share a slot",
+			jobs: []QueuedRenovateJob{
+				batchedJob(queuedJob("a", 0, scheduled("a1", 0), scheduled("a2", 0), scheduled("a3", 0), scheduled("a4", 0)), 2),
+				queuedJob("b", 0, scheduled("b1", 0), scheduled("b2", 0)),
+			},
+			expected: []string{"a/a1", "b/b1", "a/a2", "a/a3", "b/b2", "a/a4"},
+		},
+		{
+			name: "weight increases the share",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 2, scheduled("a1", 0), scheduled("a2", 0), scheduled("a3", 0), scheduled("a4", 0)),
+				queuedJob("b", 1, scheduled("b1", 0), scheduled("b2", 0)),
+			},
+			expected: []string{"a/a1", "b/b1", "a/a2", "a/a3", "b/b2", "a/a4"},
+		},
+		{
+			name: "priority orders projects within a job",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 0, scheduled("a1", 0), scheduled("a2", 2), scheduled("a3", 1)),
+			},
+			expected: []string{"a/a2", "a/a3", "a/a1"},
+		},
+		{
+			name: "jobs without scheduled projects are ignored",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 0, running("a1")),
+				queuedJob("b", 0),
+			},
+			expected: []string{},
+		},
+		{
+			name: "suspended projects are skipped",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 0, scheduled("a1", 0), crdManager.RenovateProjectStatus{Name: "a2", Status: api.JobStatusScheduled, Suspended: true}),
+			},
+			expected: []string{"a/a1"},
+		},
+		{
+			name: "projects waiting for their spread start are skipped",
+			jobs: []QueuedRenovateJob{
+				queuedJob("a", 0,
+					crdManager.RenovateProjectStatus{Name: "a1", Status: api.JobStatusScheduled, NotBefore: &later},
+					crdManager.RenovateProjectStatus{Name: "a2", Status: api.JobStatusScheduled, NotBefore: &earlier},
+				),
+			},
+			expected: []string{"a/a2"},
+		},
+		{
+			name: "suspended jobs are skipped",
+			jobs: []QueuedRenovateJob{
+				suspendedJob(queuedJob("a", 0, scheduled("a1", 0))),
+				queuedJob("b", 0, scheduled("b1", 0)),
+			},
+			expected: []string{"b/b1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := BuildQueue(tt.jobs, now)
+			got := queueOrder(entries)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected queue %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected queue %v, got %v", tt.expected, got)
+				}
+				if entries[i].Position != i+1 {
+					t.Fatalf("expected position %d for %s, got %d", i+1, got[i], entries[i].Position)
+				}
+			}
+		})
+	}
+}
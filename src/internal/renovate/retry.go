@@ -0,0 +1,50 @@
+package renovate
+
+import (
+	"math"
+	api "renovate-operator/api/v1alpha1"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// priority of retried projects, lower than any manually or webhook triggered run.
+// within this priority, retries are started before regular scheduled runs, see compareQueuePriority
+const retryPriority int32 = 0
+
+const (
+	defaultRetryInitialDelay  = 5 * time.Minute
+	defaultRetryBackoffFactor = 2
+	// upper bound for the delay between two retries
+	maxRetryDelay = 24 * time.Hour
+)
+
+/*
+get the time a project shall be retried after its run failed for the given number of consecutive times.
+returns nil if the renovatejob has no retry policy or all attempts are used up.
+*/
+func getNextRetry(policy *api.RenovateRetryPolicy, attempts int32, now time.Time) *v1.Time {
+	if policy == nil || attempts < 1 || attempts >= policy.MaxAttempts {
+		return nil
+	}
+	nextRetry := v1.NewTime(now.Add(getRetryDelay(policy, attempts)))
+	return &nextRetry
+}
+
+// delay before the next retry: initialDelay * backoffFactor^(attempts-1)
+func getRetryDelay(policy *api.RenovateRetryPolicy, attempts int32) time.Duration {
+	initialDelay := defaultRetryInitialDelay
+	if policy.InitialDelay != nil {
+		initialDelay = policy.InitialDelay.Duration
+	}
+	backoffFactor := int32(defaultRetryBackoffFactor)
+	if policy.BackoffFactor > 0 {
+		backoffFactor = policy.BackoffFactor
+	}
+
+	delay := float64(initialDelay) * math.Pow(float64(backoffFactor), float64(attempts-1))
+	if delay > float64(maxRetryDelay) {
+		return maxRetryDelay
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,81 @@
+package renovate
+
+import (
+	"testing"
+	"time"
+
+	api "renovate-operator/api/v1alpha1"
+	crdManager "renovate-operator/internal/crdManager"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetNextRetry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy := &api.RenovateRetryPolicy{
+		MaxAttempts:   4,
+		InitialDelay:  &v1.Duration{Duration: time.Minute},
+		BackoffFactor: 3,
+	}
+
+	tests := []struct {
+		name     string
+		policy   *api.RenovateRetryPolicy
+		attempts int32
+		expected time.Duration // 0 if no retry is expected
+	}{
+		{name: "no policy", policy: nil, attempts: 1, expected: 0},
+		{name: "first retry", policy: policy, attempts: 1, expected: time.Minute},
+		{name: "second retry", policy: policy, attempts: 2, expected: 3 * time.Minute},
+		{name: "third retry", policy: policy, attempts: 3, expected: 9 * time.Minute},
+		{name: "attempts exhausted", policy: policy, attempts: 4, expected: 0},
+		{name: "defaults", policy: &api.RenovateRetryPolicy{MaxAttempts: 3}, attempts: 2, expected: 10 * time.Minute},
+		{name: "capped delay", policy: &api.RenovateRetryPolicy{MaxAttempts: 100, BackoffFactor: 10}, attempts: 50, expected: maxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := getNextRetry(tt.policy, tt.attempts, now)
+			if tt.expected == 0 {
+				if result != nil {
+					t.Fatalf("expected no retry, got %v", result.Time)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("expected a retry after %v, got none", tt.expected)
+			}
+			if delay := result.Sub(now); delay != tt.expected {
+				t.Errorf("expected retry after %v, got %v", tt.expected, delay)
+			}
+		})
+	}
+}
+
+func TestTriggerAt_KeepsEarliestTrigger(t *testing.T) {
+	e := NewRenovateExecutor(nil, nil, nil, testLogger, nil).(*renovateExecutor)
+	job := crdManager.RenovateJobIdentifier{Name: "job1", Namespace: "default"}
+
+	early := time.Now().Add(time.Hour)
+	e.triggerAt(job, early)
+	e.triggerAt(job, early.Add(time.Hour))
+	if at := e.delayedTriggers[job.Fullname()].at; !at.Equal(early) {
+		t.Errorf("expected the earlier trigger to be kept, got %v", at)
+	}
+
+	earlier := early.Add(-30 * time.Minute)
+	e.triggerAt(job, earlier)
+	if at := e.delayedTriggers[job.Fullname()].at; !at.Equal(earlier) {
+		t.Errorf("expected the trigger to be moved to %v, got %v", earlier, at)
+	}
+
+	e.triggerAt(job, time.Now())
+	select {
+	case <-e.wakeup:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the executor to be triggered")
+	}
+	for _, trigger := range e.delayedTriggers {
+		trigger.timer.Stop()
+	}
+}
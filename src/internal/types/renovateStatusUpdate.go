@@ -2,6 +2,7 @@ package types
 
 import (
 	api "renovate-operator/api/v1alpha1"
+	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -12,4 +13,12 @@ type RenovateStatusUpdate struct {
 	RenovateResultStatus *string
 	LastRun              *v1.Time
 	Duration             *string
+	// scheduling is a retry of a failed run, the attempts of the project are kept
+	Retry bool
+	// time a failed project shall be retried, nil if it shall not be retried
+	NextRetry *v1.Time
+	// release a quarantined project, only quarantined projects released this way can be scheduled again
+	Release bool
+	// spread the start of the scheduled project over this duration, by an offset derived from its name
+	Spread time.Duration
 }
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"hash/fnv"
+	api "renovate-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const defaultCacheCleanupThreshold = 80
+
+// number of cache volumes of the renovatejob, zero if the cache is disabled
+func GetCacheShards(spec *api.RenovateJobSpec) int {
+	if spec.Cache == nil {
+		return 0
+	}
+	if spec.Cache.Shards < 1 {
+		return 1
+	}
+	return int(spec.Cache.Shards)
+}
+
+// stable cache volume of a project, derived from the project name
+func GetCacheShard(spec *api.RenovateJobSpec, project string) int {
+	shards := GetCacheShards(spec)
+	if shards <= 1 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(project))
+	return int(hash.Sum64() % uint64(shards))
+}
+
+// access mode of the cache volumes, defaults to ReadWriteOnce
+func GetCacheAccessMode(cache *api.RenovateCache) corev1.PersistentVolumeAccessMode {
+	if cache == nil || cache.AccessMode == "" {
+		return corev1.ReadWriteOnce
+	}
+	return cache.AccessMode
+}
+
+// whether a cache volume can be used by multiple running projects at the same time.
+// volumes that are not shared can only be mounted on a single node or by a single pod.
+func IsCacheShared(cache *api.RenovateCache) bool {
+	return GetCacheAccessMode(cache) == corev1.ReadWriteMany
+}
+
+// usage of a cache volume in percent from which outdated cache files are removed
+func GetCacheCleanupThreshold(cache *api.RenovateCache) int32 {
+	if cache == nil || cache.CleanupThreshold < 1 {
+		return defaultCacheCleanupThreshold
+	}
+	return cache.CleanupThreshold
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	api "renovate-operator/api/v1alpha1"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetCacheShard(t *testing.T) {
+	spec := &api.RenovateJobSpec{}
+	if shards := GetCacheShards(spec); shards != 0 {
+		t.Errorf("expected no cache volumes without cache, got %d", shards)
+	}
+
+	spec.Cache = &api.RenovateCache{}
+	if shards := GetCacheShards(spec); shards != 1 {
+		t.Errorf("expected a single cache volume by default, got %d", shards)
+	}
+	if shard := GetCacheShard(spec, "org/repo"); shard != 0 {
+		t.Errorf("expected shard 0 with a single cache volume, got %d", shard)
+	}
+
+	spec.Cache.Shards = 4
+	seen := map[int]bool{}
+	for _, project := range []string{"org/a", "org/b", "org/c", "org/d", "org/e", "org/f", "org/g", "org/h"} {
+		shard := GetCacheShard(spec, project)
+		if shard < 0 || shard >= 4 {
+			t.Fatalf("shard %d of %s out of range", shard, project)
+		}
+		if shard != GetCacheShard(spec, project) {
+			t.Fatalf("shard of %s is not stable", project)
+		}
+		seen[shard] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("expected projects to be distributed over the shards, got %v", seen)
+	}
+}
+
+func TestCacheAccessMode(t *testing.T) {
+	if IsCacheShared(&api.RenovateCache{}) {
+		t.Error("expected ReadWriteOnce volumes not to be shared")
+	}
+	if IsCacheShared(&api.RenovateCache{AccessMode: corev1.ReadWriteOncePod}) {
+		t.Error("expected ReadWriteOncePod volumes not to be shared")
+	}
+	if !IsCacheShared(&api.RenovateCache{AccessMode: corev1.ReadWriteMany}) {
+		t.Error("expected ReadWriteMany volumes to be shared")
+	}
+	if threshold := GetCacheCleanupThreshold(&api.RenovateCache{}); threshold != 80 {
+		t.Errorf("expected default cleanup threshold of 80, got %d", threshold)
+	}
+}
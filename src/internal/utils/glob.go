@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// compile a glob pattern matching project names, case insensitive like renovate.
+// "*" and "?" do not match "/", "**" matches any number of path segments,
+// "[...]" matches a character class and "{a,b}" matches one of the alternatives.
+func CompileGlob(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("(?i)^")
+	braces := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			// "**/" also matches no directory at all
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				i++
+				expr.WriteString("(?:.*/)?")
+			} else {
+				expr.WriteString(".*")
+			}
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q: unterminated character class", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '{':
+			braces++
+			expr.WriteString("(?:")
+		case c == '}' && braces > 0:
+			braces--
+			expr.WriteString(")")
+		case c == ',' && braces > 0:
+			expr.WriteString("|")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if braces > 0 {
+		return nil, fmt.Errorf("invalid glob %q: unterminated alternative", pattern)
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// whether the project name matches the glob pattern
+func MatchGlob(pattern string, name string) (bool, error) {
+	expr, err := CompileGlob(pattern)
+	if err != nil {
+		return false, err
+	}
+	return expr.MatchString(name), nil
+}
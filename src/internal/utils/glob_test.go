@@ -0,0 +1,45 @@
+package utils
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "org/*", name: "org/repo", expected: true},
+		{pattern: "org/*", name: "org/group/repo", expected: false},
+		{pattern: "org/**", name: "org/group/repo", expected: true},
+		{pattern: "org/**/repo", name: "org/repo", expected: true},
+		{pattern: "org/**/repo", name: "org/a/b/repo", expected: true},
+		{pattern: "org/repo-?", name: "org/repo-1", expected: true},
+		{pattern: "org/repo-?", name: "org/repo-12", expected: false},
+		{pattern: "org/repo-[0-9]", name: "org/repo-1", expected: true},
+		{pattern: "org/repo-[!0-9]", name: "org/repo-1", expected: false},
+		{pattern: "org/{api,web}", name: "org/web", expected: true},
+		{pattern: "org/{api,web}", name: "org/worker", expected: false},
+		{pattern: "Org/Repo", name: "org/repo", expected: true},
+		{pattern: "org/repo.js", name: "org/repoxjs", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			got, err := MatchGlob(tt.pattern, tt.name)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMatchGlob_Invalid(t *testing.T) {
+	for _, pattern := range []string{"org/[abc", "org/{api,web"} {
+		if _, err := MatchGlob(pattern, "org/api"); err == nil {
+			t.Errorf("expected an error for %q", pattern)
+		}
+	}
+}
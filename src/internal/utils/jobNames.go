@@ -24,6 +24,50 @@ func ExecutorJobName(in *api.RenovateJob, project string) string {
 	return fullName + "-" + hashStr
 }
 
+// jobname for an executor job running several projects. the hash is taken from the projects,
+// so a batch of the same projects replaces the job of the previous run
+func BatchJobName(in *api.RenovateJob, projects []string) string {
+	baseName := kubernetesCompatibleName(in.Name)
+
+	hash := sha256.Sum256([]byte(in.Name + "/" + strings.Join(projects, ",")))
+	hashStr := fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes (8 hex chars)
+
+	// Trim base name to fit: 54 - len("-batch") = 48 chars max
+	if len(baseName) > 48 {
+		baseName = baseName[:48]
+	}
+
+	return baseName + "-batch-" + hashStr
+}
+
+// resource name for the RenovateProject of a project. normalized for kubernetes resourcenames
+// the hash is taken from the raw project name, so projects only differing in special characters do not collide
+func RenovateProjectName(renovateJob string, project string) string {
+	fullName := kubernetesCompatibleName(renovateJob + "-" + project)
+
+	hash := sha256.Sum256([]byte(renovateJob + "/" + project))
+	hashStr := fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes (8 hex chars)
+
+	// Trim to 54 chars and append hash
+	if len(fullName) > 54 {
+		fullName = fullName[:54]
+	}
+
+	return fullName + "-" + hashStr
+}
+
+// label value referencing a renovatejob. label values are limited to 63 chars, longer names are trimmed and get a hash
+func RenovateJobLabelValue(renovateJob string) string {
+	if len(renovateJob) <= 63 {
+		return renovateJob
+	}
+
+	hash := sha256.Sum256([]byte(renovateJob))
+	hashStr := fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes (8 hex chars)
+
+	return renovateJob[:54] + "-" + hashStr
+}
+
 func kubernetesCompatibleName(name string) string {
 	name = strings.ReplaceAll(name, "/", "-") // Replace slashes to avoid issues with Kubernetes naming
 	name = strings.ReplaceAll(name, "_", "-")
@@ -51,6 +95,52 @@ func DiscoveryJobName(in *api.RenovateJob) string {
 	return baseName + "-discovery-" + hashStr
 }
 
+// name of the persistent volume claim holding a cache shard of the renovatejob. normalized for kubernetes resourcenames
+func CacheVolumeName(in *api.RenovateJob, shard int) string {
+	baseName := kubernetesCompatibleName(in.Name)
+
+	// Generate hash of the full name
+	hash := sha256.Sum256([]byte(baseName + "-cache"))
+	hashStr := fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes (8 hex chars)
+
+	if len(baseName) > 44 {
+		baseName = baseName[:44]
+	}
+
+	return fmt.Sprintf("%s-cache-%d-%s", baseName, shard, hashStr)
+}
+
+// name of the ConfigMap holding the inline renovate config of the renovatejob. normalized for kubernetes resourcenames
+func RenovateConfigName(in *api.RenovateJob) string {
+	baseName := kubernetesCompatibleName(in.Name)
+
+	// Generate hash of the full name
+	hash := sha256.Sum256([]byte(baseName + "-config"))
+	hashStr := fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes (8 hex chars)
+
+	if len(baseName) > 44 {
+		baseName = baseName[:44]
+	}
+
+	return baseName + "-config-" + hashStr
+}
+
+// jobname for the job validating the renovate config of the renovatejob. normalized for kubernetes resourcenames
+func ValidatorJobName(in *api.RenovateJob) string {
+	baseName := kubernetesCompatibleName(in.Name)
+
+	// Generate hash of the full name
+	hash := sha256.Sum256([]byte(baseName + "-validator"))
+	hashStr := fmt.Sprintf("%x", hash[:4]) // Use first 4 bytes (8 hex chars)
+
+	// Trim base name to fit: 54 - len("-validator") = 44 chars max
+	if len(baseName) > 44 {
+		baseName = baseName[:44]
+	}
+
+	return baseName + "-validator-" + hashStr
+}
+
 // LEGACY functions - to be removed February 2026
 func LegacyExecutorJobName(in *api.RenovateJob, project string) string {
 	jobName := in.Name + "-" + project
@@ -2,6 +2,7 @@ package utils
 
 import (
 	api "renovate-operator/api/v1alpha1"
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -107,3 +108,89 @@ func TestRenovateJob_JobNames(t *testing.T) {
 		})
 	}
 }
+
+func TestRenovateProjectName(t *testing.T) {
+	name := RenovateProjectName("renovate", "Org/My_Repo")
+	if !strings.HasPrefix(name, "renovate-org-my-repo-") {
+		t.Errorf("RenovateProjectName() = %v, expected normalized prefix", name)
+	}
+	if len(name) != len("renovate-org-my-repo-")+8 {
+		t.Errorf("RenovateProjectName() = %v, expected 8 char hash suffix", name)
+	}
+
+	// projects that normalize to the same name must not collide
+	if RenovateProjectName("renovate", "org/my_repo") == RenovateProjectName("renovate", "org/my-repo") {
+		t.Errorf("RenovateProjectName() collides for projects differing only in special characters")
+	}
+
+	long := RenovateProjectName("renovate", "Your-very-long-org-name/with.a.lot_of.parts_and--symbols")
+	if len(long) > 63 {
+		t.Errorf("RenovateProjectName() = %v, exceeds 63 characters", long)
+	}
+}
+
+func TestCacheVolumeName(t *testing.T) {
+	rj := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "My.Renovate"}}
+	name := CacheVolumeName(rj, 2)
+	if !strings.HasPrefix(name, "my-renovate-cache-2-") {
+		t.Errorf("CacheVolumeName() = %v, expected normalized prefix", name)
+	}
+	if CacheVolumeName(rj, 0) == name {
+		t.Errorf("CacheVolumeName() is equal for different shards")
+	}
+
+	rj.Name = "a-very-long-renovatejob-name-exceeding-the-limit-of-kubernetes"
+	if long := CacheVolumeName(rj, 10); len(long) > 63 {
+		t.Errorf("CacheVolumeName() = %v, exceeds 63 characters", long)
+	}
+}
+
+func TestBatchJobName(t *testing.T) {
+	rj := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "Renovate"}}
+	name := BatchJobName(rj, []string{"org/a", "org/b"})
+	if !strings.HasPrefix(name, "renovate-batch-") {
+		t.Errorf("BatchJobName() = %v, expected normalized prefix", name)
+	}
+	if name == BatchJobName(rj, []string{"org/a", "org/c"}) {
+		t.Errorf("BatchJobName() is equal for different projects")
+	}
+
+	rj.Name = "a-very-long-renovatejob-name-exceeding-the-limit-of-kubernetes"
+	if long := BatchJobName(rj, []string{"org/a"}); len(long) > 63 {
+		t.Errorf("BatchJobName() = %v, exceeds 63 characters", long)
+	}
+}
+
+func TestRenovateConfigName(t *testing.T) {
+	rj := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "My.Renovate"}}
+	if name := RenovateConfigName(rj); !strings.HasPrefix(name, "my-renovate-config-") || len(name) != len("my-renovate-config-")+8 {
+		t.Errorf("RenovateConfigName() = %v, expected normalized prefix and hash", name)
+	}
+}
+
+func TestValidatorJobName(t *testing.T) {
+	rj := &api.RenovateJob{ObjectMeta: metav1.ObjectMeta{Name: "My.Renovate"}}
+	if name := ValidatorJobName(rj); !strings.HasPrefix(name, "my-renovate-validator-") || len(name) != len("my-renovate-validator-")+8 {
+		t.Errorf("ValidatorJobName() = %v, expected normalized prefix and hash", name)
+	}
+
+	rj.Name = strings.Repeat("a", 80)
+	if name := ValidatorJobName(rj); len(name) > 63 {
+		t.Errorf("ValidatorJobName() = %v, exceeds 63 characters", name)
+	}
+}
+
+func TestRenovateJobLabelValue(t *testing.T) {
+	if got := RenovateJobLabelValue("renovate-group1"); got != "renovate-group1" {
+		t.Errorf("RenovateJobLabelValue() = %v, expected short names to be kept", got)
+	}
+
+	long := strings.Repeat("renovate-", 20)
+	got := RenovateJobLabelValue(long)
+	if len(got) != 63 || !strings.HasPrefix(got, long[:54]+"-") {
+		t.Errorf("RenovateJobLabelValue() = %v, expected a trimmed name with hash suffix", got)
+	}
+	if other := RenovateJobLabelValue(long + "x"); other == got {
+		t.Errorf("RenovateJobLabelValue() = %v for different names", other)
+	}
+}
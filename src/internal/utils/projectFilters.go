@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	api "renovate-operator/api/v1alpha1"
+	"strings"
+)
+
+// ApplyProjectFilters keeps the projects accepted by the ordered include and exclude rules.
+// The last matching rule decides, projects matching no rule are kept unless there is an include rule.
+func ApplyProjectFilters(projects []string, filters []api.RenovateProjectFilter) ([]string, error) {
+	if len(filters) == 0 {
+		return projects, nil
+	}
+
+	matchers := make([]func(string) bool, len(filters))
+	includeByDefault := true
+	for i := range filters {
+		matcher, err := compileProjectFilter(&filters[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid project filter %d: %w", i, err)
+		}
+		matchers[i] = matcher
+		if filters[i].Action == api.ProjectFilterInclude {
+			includeByDefault = false
+		}
+	}
+
+	result := make([]string, 0, len(projects))
+	for _, project := range projects {
+		included := includeByDefault
+		for i := range filters {
+			if matchers[i](project) {
+				included = filters[i].Action == api.ProjectFilterInclude
+			}
+		}
+		if included {
+			result = append(result, project)
+		}
+	}
+	return result, nil
+}
+
+func compileProjectFilter(filter *api.RenovateProjectFilter) (func(string) bool, error) {
+	if filter.Action != api.ProjectFilterInclude && filter.Action != api.ProjectFilterExclude {
+		return nil, fmt.Errorf("unknown action %q", filter.Action)
+	}
+	switch {
+	case filter.Glob != "" && filter.Regex == "" && filter.Prefix == "":
+		glob, err := CompileGlob(filter.Glob)
+		if err != nil {
+			return nil, err
+		}
+		return glob.MatchString, nil
+	case filter.Regex != "" && filter.Glob == "" && filter.Prefix == "":
+		regex, err := regexp.Compile(filter.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", filter.Regex, err)
+		}
+		return regex.MatchString, nil
+	case filter.Prefix != "" && filter.Glob == "" && filter.Regex == "":
+		prefix := strings.ToLower(strings.TrimSuffix(filter.Prefix, "/") + "/")
+		return func(project string) bool {
+			return strings.HasPrefix(strings.ToLower(project), prefix)
+		}, nil
+	default:
+		return nil, fmt.Errorf("exactly one of glob, regex and prefix must be set")
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+)
+
+func TestApplyProjectFilters(t *testing.T) {
+	projects := []string{"platform/api", "platform/legacy-billing", "platform/team/web", "apps/shop", "apps/legacy-shop"}
+
+	tests := []struct {
+		name     string
+		filters  []api.RenovateProjectFilter
+		expected []string
+	}{
+		{
+			name:     "no filters",
+			filters:  nil,
+			expected: projects,
+		},
+		{
+			name: "include with exception",
+			filters: []api.RenovateProjectFilter{
+				{Action: api.ProjectFilterInclude, Glob: "platform/*"},
+				{Action: api.ProjectFilterExclude, Glob: "platform/legacy-*"},
+			},
+			expected: []string{"platform/api"},
+		},
+		{
+			name: "exclude only keeps everything else",
+			filters: []api.RenovateProjectFilter{
+				{Action: api.ProjectFilterExclude, Regex: "/legacy-"},
+			},
+			expected: []string{"platform/api", "platform/team/web", "apps/shop"},
+		},
+		{
+			name: "prefix includes subgroups",
+			filters: []api.RenovateProjectFilter{
+				{Action: api.ProjectFilterInclude, Prefix: "platform/"},
+			},
+			expected: []string{"platform/api", "platform/legacy-billing", "platform/team/web"},
+		},
+		{
+			name: "later rules win",
+			filters: []api.RenovateProjectFilter{
+				{Action: api.ProjectFilterExclude, Prefix: "Platform"},
+				{Action: api.ProjectFilterInclude, Glob: "platform/team/**"},
+			},
+			expected: []string{"platform/team/web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyProjectFilters(projects, tt.filters)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApplyProjectFilters_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter api.RenovateProjectFilter
+	}{
+		{name: "no matcher", filter: api.RenovateProjectFilter{Action: api.ProjectFilterInclude}},
+		{name: "two matchers", filter: api.RenovateProjectFilter{Action: api.ProjectFilterInclude, Glob: "a/*", Prefix: "a"}},
+		{name: "invalid regex", filter: api.RenovateProjectFilter{Action: api.ProjectFilterInclude, Regex: "[a-"}},
+		{name: "unknown action", filter: api.RenovateProjectFilter{Action: "keep", Glob: "a/*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ApplyProjectFilters([]string{"a/b"}, []api.RenovateProjectFilter{tt.filter}); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
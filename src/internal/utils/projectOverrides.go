@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"maps"
+	api "renovate-operator/api/v1alpha1"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetProjectOverride merges all project overrides of the renovatejob matching the project, later overrides take precedence.
+// Overrides with an invalid glob never match.
+func GetProjectOverride(spec *api.RenovateJobSpec, project string) api.RenovateProjectOverride {
+	result := api.RenovateProjectOverride{Project: project}
+	for i := range spec.ProjectOverrides {
+		override := &spec.ProjectOverrides[i]
+		if matches, err := MatchGlob(override.Project, project); err != nil || !matches {
+			continue
+		}
+		if override.Image != "" {
+			result.Image = override.Image
+		}
+		if override.Resources != nil {
+			result.Resources = override.Resources
+		}
+		// variables of later overrides replace variables with the same name
+		for _, env := range override.ExtraEnv {
+			result.ExtraEnv = slices.DeleteFunc(result.ExtraEnv, func(e corev1.EnvVar) bool { return e.Name == env.Name })
+			result.ExtraEnv = append(result.ExtraEnv, env)
+		}
+		if override.NodeSelector != nil {
+			result.NodeSelector = maps.Clone(override.NodeSelector)
+		}
+		if override.TimeoutSeconds != nil {
+			result.TimeoutSeconds = override.TimeoutSeconds
+		}
+		if override.Priority != 0 {
+			result.Priority = override.Priority
+		}
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetProjectOverride(t *testing.T) {
+	spec := &api.RenovateJobSpec{
+		ProjectOverrides: []api.RenovateProjectOverride{
+			{Project: "org/**", Image: "renovate:default", ExtraEnv: []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "1"}}, Priority: 1},
+			{Project: "org/monorepo", Image: "renovate:full", ExtraEnv: []corev1.EnvVar{{Name: "B", Value: "2"}}},
+			{Project: "org/[invalid", Image: "renovate:invalid"},
+		},
+	}
+
+	override := GetProjectOverride(spec, "org/monorepo")
+	if override.Image != "renovate:full" {
+		t.Errorf("expected the image of the later override, got %q", override.Image)
+	}
+	if override.Priority != 1 {
+		t.Errorf("expected the priority of the first override, got %d", override.Priority)
+	}
+	if len(override.ExtraEnv) != 2 || override.ExtraEnv[0].Name != "A" || override.ExtraEnv[1].Value != "2" {
+		t.Errorf("expected merged env vars, got %v", override.ExtraEnv)
+	}
+
+	override = GetProjectOverride(spec, "other/repo")
+	if override.Image != "" || override.Priority != 0 || override.ExtraEnv != nil {
+		t.Errorf("expected no override for other/repo, got %+v", override)
+	}
+}
@@ -1,13 +1,20 @@
 package utils
 
 import (
+	"hash/fnv"
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/internal/types"
+	"time"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// GetUpdateStatusForProject determines the new status for a project based on its current status and the desired status update.
+/*
+GetUpdateStatusForProject determines the new status for a project based on its current status and the desired status update.
+Failed runs are counted twice: Attempts limits the retries within one schedule and is reset whenever the project is scheduled
+regularly, ConsecutiveFailures counts the failures across schedules for the quarantine. Both are incremented by every failed
+run and reset together once a run completes or the project is released, so ConsecutiveFailures is never lower than Attempts.
+*/
 func GetUpdateStatusForProject(projectStatus *api.ProjectStatus, desiredStatus *types.RenovateStatusUpdate) *api.ProjectStatus {
 	switch desiredStatus.Status {
 	case api.JobStatusScheduled:
@@ -18,15 +25,34 @@ func GetUpdateStatusForProject(projectStatus *api.ProjectStatus, desiredStatus *
 		return validateProjectStatusCompleted(projectStatus, desiredStatus)
 	case api.JobStatusFailed:
 		return validateProjectStatusFailed(projectStatus, desiredStatus)
+	case api.JobStatusQuarantined:
+		return validateProjectStatusQuarantined(projectStatus, desiredStatus)
 	default:
 		return projectStatus
 	}
 }
 
 func validateProjectStatusScheduled(projectStatus *api.ProjectStatus, desiredStatus *types.RenovateStatusUpdate) *api.ProjectStatus {
+	// quarantined projects have to be released explicitly
+	if projectStatus.Status == api.JobStatusQuarantined {
+		if !desiredStatus.Release {
+			return projectStatus
+		}
+		projectStatus.Attempts = 0
+		projectStatus.ConsecutiveFailures = 0
+	}
 	// cannot schedule a project that is currently running
 	if projectStatus.Status != api.JobStatusRunning {
 		projectStatus.Status = api.JobStatusScheduled
+		projectStatus.NextRetry = nil
+		projectStatus.NotBefore = nil
+		if desiredStatus.Spread > 0 {
+			notBefore := v1.NewTime(time.Now().Add(GetSpreadOffset(projectStatus.Name, desiredStatus.Spread)))
+			projectStatus.NotBefore = &notBefore
+		}
+		if !desiredStatus.Retry {
+			projectStatus.Attempts = 0
+		}
 		if desiredStatus.Priority > projectStatus.Priority {
 			projectStatus.Priority = desiredStatus.Priority
 		}
@@ -40,6 +66,7 @@ func validateProjectStatusRunning(projectStatus *api.ProjectStatus, desiredStatu
 	if projectStatus.Status == api.JobStatusScheduled {
 		projectStatus.Status = api.JobStatusRunning
 		projectStatus.Priority = 0
+		projectStatus.NotBefore = nil
 	}
 	projectStatus.Duration = nil
 	updateRenovateResultStatus(projectStatus, desiredStatus.RenovateResultStatus)
@@ -52,6 +79,11 @@ func validateProjectStatusCompleted(projectStatus *api.ProjectStatus, desiredSta
 		projectStatus.Status = api.JobStatusCompleted
 		projectStatus.Priority = 0
 		projectStatus.LastRun = v1.Now()
+		lastSuccess := projectStatus.LastRun
+		projectStatus.LastSuccess = &lastSuccess
+		projectStatus.Attempts = 0
+		projectStatus.NextRetry = nil
+		projectStatus.ConsecutiveFailures = 0
 	}
 	projectStatus.Duration = desiredStatus.Duration
 	updateRenovateResultStatus(projectStatus, desiredStatus.RenovateResultStatus)
@@ -63,12 +95,50 @@ func validateProjectStatusFailed(projectStatus *api.ProjectStatus, desiredStatus
 		projectStatus.Status = api.JobStatusFailed
 		projectStatus.Priority = 0
 		projectStatus.LastRun = v1.Now()
+		projectStatus.Attempts++
+		projectStatus.NextRetry = desiredStatus.NextRetry
+		projectStatus.ConsecutiveFailures++
 	}
 	projectStatus.Duration = desiredStatus.Duration
 	updateRenovateResultStatus(projectStatus, desiredStatus.RenovateResultStatus)
 	return projectStatus
 }
 
+func validateProjectStatusQuarantined(projectStatus *api.ProjectStatus, desiredStatus *types.RenovateStatusUpdate) *api.ProjectStatus {
+	// a project is quarantined instead of failed by its last failing run
+	if projectStatus.Status == api.JobStatusRunning {
+		projectStatus.Status = api.JobStatusQuarantined
+		projectStatus.Priority = 0
+		projectStatus.LastRun = v1.Now()
+		projectStatus.Attempts++
+		projectStatus.NextRetry = nil
+		projectStatus.ConsecutiveFailures++
+	}
+	projectStatus.Duration = desiredStatus.Duration
+	updateRenovateResultStatus(projectStatus, desiredStatus.RenovateResultStatus)
+	return projectStatus
+}
+
+// whether the last successful run of a project is longer ago than the maximum staleness of its renovatejob.
+// failed runs do not count, projects that never completed a run are stale
+func IsProjectStale(spec *api.RenovateJobSpec, lastSuccess *time.Time, now time.Time) bool {
+	if spec.MaxStaleness == nil || spec.MaxStaleness.Duration <= 0 {
+		return false
+	}
+	return lastSuccess == nil || now.Sub(*lastSuccess) > spec.MaxStaleness.Duration
+}
+
+// stable offset of a project within the spread duration, derived from the project name
+func GetSpreadOffset(project string, spread time.Duration) time.Duration {
+	seconds := uint64(spread / time.Second)
+	if seconds == 0 {
+		return 0
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(project))
+	return time.Duration(hash.Sum64()%seconds) * time.Second
+}
+
 func updateRenovateResultStatus(projectStatus *api.ProjectStatus, status *string) {
 	if status != nil {
 		projectStatus.RenovateResultStatus = status
@@ -2,9 +2,12 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	api "renovate-operator/api/v1alpha1"
 	"renovate-operator/internal/types"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGetUpdateStatusForProject(t *testing.T) {
@@ -116,3 +119,215 @@ func TestGetUpdateStatusForProject_Priority(t *testing.T) {
 		}
 	})
 }
+
+func TestGetUpdateStatusForProject_Attempts(t *testing.T) {
+	t.Run("Failing a running project increments attempts and sets next retry", func(t *testing.T) {
+		nextRetry := v1.Now()
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusRunning, Attempts: 1}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusFailed, NextRetry: &nextRetry})
+		if result.Attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", result.Attempts)
+		}
+		if result.NextRetry == nil || !result.NextRetry.Equal(&nextRetry) {
+			t.Errorf("expected next retry %v, got %v", nextRetry, result.NextRetry)
+		}
+	})
+
+	t.Run("Retry keeps attempts and clears next retry", func(t *testing.T) {
+		nextRetry := v1.Now()
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusFailed, Attempts: 2, NextRetry: &nextRetry}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Retry: true})
+		if result.Attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", result.Attempts)
+		}
+		if result.NextRetry != nil {
+			t.Errorf("expected next retry to be cleared, got %v", result.NextRetry)
+		}
+	})
+
+	t.Run("Regular scheduling resets attempts", func(t *testing.T) {
+		nextRetry := v1.Now()
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusFailed, Attempts: 2, NextRetry: &nextRetry}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Priority: 1})
+		if result.Attempts != 0 {
+			t.Errorf("expected attempts to be reset, got %d", result.Attempts)
+		}
+		if result.NextRetry != nil {
+			t.Errorf("expected next retry to be cleared, got %v", result.NextRetry)
+		}
+	})
+
+	t.Run("Completing a running project resets attempts", func(t *testing.T) {
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusRunning, Attempts: 2}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusCompleted})
+		if result.Attempts != 0 {
+			t.Errorf("expected attempts to be reset, got %d", result.Attempts)
+		}
+	})
+}
+
+func TestGetUpdateStatusForProject_Quarantine(t *testing.T) {
+	t.Run("Quarantining a running project counts the failure", func(t *testing.T) {
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusRunning, ConsecutiveFailures: 2}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusQuarantined})
+		if result.Status != api.JobStatusQuarantined {
+			t.Errorf("expected status quarantined, got %v", result.Status)
+		}
+		if result.ConsecutiveFailures != 3 {
+			t.Errorf("expected 3 consecutive failures, got %d", result.ConsecutiveFailures)
+		}
+	})
+
+	t.Run("Quarantined project is not scheduled", func(t *testing.T) {
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusQuarantined, ConsecutiveFailures: 3}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Priority: 1})
+		if result.Status != api.JobStatusQuarantined {
+			t.Errorf("expected status to remain quarantined, got %v", result.Status)
+		}
+		if result.Priority != 0 {
+			t.Errorf("expected priority to remain 0, got %d", result.Priority)
+		}
+	})
+
+	t.Run("Released project is scheduled and failures are reset", func(t *testing.T) {
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusQuarantined, ConsecutiveFailures: 3}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Priority: 2, Release: true})
+		if result.Status != api.JobStatusScheduled {
+			t.Errorf("expected status scheduled, got %v", result.Status)
+		}
+		if result.ConsecutiveFailures != 0 {
+			t.Errorf("expected consecutive failures to be reset, got %d", result.ConsecutiveFailures)
+		}
+	})
+
+	t.Run("Failures are counted across schedules until a run completes", func(t *testing.T) {
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusRunning, ConsecutiveFailures: 1}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusFailed})
+		result = GetUpdateStatusForProject(result, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled})
+		if result.ConsecutiveFailures != 2 {
+			t.Errorf("expected 2 consecutive failures, got %d", result.ConsecutiveFailures)
+		}
+		result = GetUpdateStatusForProject(result, &types.RenovateStatusUpdate{Status: api.JobStatusRunning})
+		result = GetUpdateStatusForProject(result, &types.RenovateStatusUpdate{Status: api.JobStatusCompleted})
+		if result.ConsecutiveFailures != 0 {
+			t.Errorf("expected consecutive failures to be reset, got %d", result.ConsecutiveFailures)
+		}
+	})
+}
+
+// the retry and quarantine counters count the same failures, they only differ in resetting attempts on regular schedules
+func TestGetUpdateStatusForProject_FailureCounters(t *testing.T) {
+	steps := []struct {
+		update              types.RenovateStatusUpdate
+		attempts            int32
+		consecutiveFailures int32
+	}{
+		{types.RenovateStatusUpdate{Status: api.JobStatusRunning}, 0, 0},
+		{types.RenovateStatusUpdate{Status: api.JobStatusFailed}, 1, 1},
+		{types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Retry: true}, 1, 1},
+		{types.RenovateStatusUpdate{Status: api.JobStatusRunning}, 1, 1},
+		{types.RenovateStatusUpdate{Status: api.JobStatusFailed}, 2, 2},
+		// the next schedule starts a new series of retries, the quarantine keeps counting
+		{types.RenovateStatusUpdate{Status: api.JobStatusScheduled}, 0, 2},
+		{types.RenovateStatusUpdate{Status: api.JobStatusRunning}, 0, 2},
+		{types.RenovateStatusUpdate{Status: api.JobStatusQuarantined}, 1, 3},
+		{types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Release: true}, 0, 0},
+		{types.RenovateStatusUpdate{Status: api.JobStatusRunning}, 0, 0},
+		{types.RenovateStatusUpdate{Status: api.JobStatusFailed}, 1, 1},
+		{types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Retry: true}, 1, 1},
+		{types.RenovateStatusUpdate{Status: api.JobStatusRunning}, 1, 1},
+		{types.RenovateStatusUpdate{Status: api.JobStatusCompleted}, 0, 0},
+	}
+
+	proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusScheduled}
+	for i, step := range steps {
+		proj = GetUpdateStatusForProject(proj, &step.update)
+		if proj.Attempts != step.attempts || proj.ConsecutiveFailures != step.consecutiveFailures {
+			t.Fatalf("step %d (%s): expected %d attempts and %d consecutive failures, got %d and %d",
+				i, step.update.Status, step.attempts, step.consecutiveFailures, proj.Attempts, proj.ConsecutiveFailures)
+		}
+		if proj.Attempts > proj.ConsecutiveFailures {
+			t.Fatalf("step %d (%s): attempts %d exceed consecutive failures %d", i, step.update.Status, proj.Attempts, proj.ConsecutiveFailures)
+		}
+	}
+}
+
+func TestGetUpdateStatusForProject_Spread(t *testing.T) {
+	t.Run("Scheduling with spread sets the start time", func(t *testing.T) {
+		before := time.Now()
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusCompleted}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Spread: time.Hour})
+		if result.NotBefore == nil {
+			t.Fatalf("expected a start time to be set")
+		}
+		expected := before.Add(GetSpreadOffset("p", time.Hour))
+		if result.NotBefore.Time.Before(expected.Truncate(time.Second)) || result.NotBefore.Time.After(expected.Add(time.Second)) {
+			t.Errorf("expected start time around %v, got %v", expected, result.NotBefore.Time)
+		}
+	})
+
+	t.Run("Scheduling without spread starts right away", func(t *testing.T) {
+		notBefore := v1.NewTime(time.Now().Add(time.Hour))
+		proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusScheduled, NotBefore: &notBefore}
+		result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusScheduled, Priority: 1})
+		if result.NotBefore != nil {
+			t.Errorf("expected the start time to be cleared, got %v", result.NotBefore)
+		}
+	})
+}
+
+func TestGetSpreadOffset(t *testing.T) {
+	spread := 2 * time.Hour
+	offsets := make(map[time.Duration]struct{})
+	for _, project := range []string{"org/a", "org/b", "org/c", "org/d", "org/e"} {
+		offset := GetSpreadOffset(project, spread)
+		if offset < 0 || offset >= spread {
+			t.Errorf("offset %v of %s is outside of the spread %v", offset, project, spread)
+		}
+		if offset != GetSpreadOffset(project, spread) {
+			t.Errorf("offset of %s is not stable", project)
+		}
+		offsets[offset] = struct{}{}
+	}
+	if len(offsets) < 2 {
+		t.Errorf("expected projects to be spread, got %v", offsets)
+	}
+	if offset := GetSpreadOffset("org/a", 0); offset != 0 {
+		t.Errorf("expected no offset without spread, got %v", offset)
+	}
+}
+
+func TestIsProjectStale(t *testing.T) {
+	now := time.Now()
+	spec := &api.RenovateJobSpec{MaxStaleness: &v1.Duration{Duration: 24 * time.Hour}}
+
+	recent := now.Add(-time.Hour)
+	old := now.Add(-48 * time.Hour)
+
+	if IsProjectStale(&api.RenovateJobSpec{}, &old, now) {
+		t.Errorf("expected no project to be stale without max staleness")
+	}
+	if IsProjectStale(spec, &recent, now) {
+		t.Errorf("expected a recently successful project not to be stale")
+	}
+	if !IsProjectStale(spec, &old, now) {
+		t.Errorf("expected a project without success for 48h to be stale")
+	}
+	if !IsProjectStale(spec, nil, now) {
+		t.Errorf("expected a project that never succeeded to be stale")
+	}
+}
+
+func TestGetUpdateStatusForProject_LastSuccess(t *testing.T) {
+	proj := &api.ProjectStatus{Name: "p", Status: api.JobStatusRunning}
+	result := GetUpdateStatusForProject(proj, &types.RenovateStatusUpdate{Status: api.JobStatusFailed})
+	if result.LastSuccess != nil {
+		t.Fatalf("expected no last success after a failed run, got %v", result.LastSuccess)
+	}
+
+	result.Status = api.JobStatusRunning
+	result = GetUpdateStatusForProject(result, &types.RenovateStatusUpdate{Status: api.JobStatusCompleted})
+	if result.LastSuccess == nil || !result.LastSuccess.Equal(&result.LastRun) {
+		t.Fatalf("expected last success to equal the last run %v, got %v", result.LastRun, result.LastSuccess)
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	api "renovate-operator/api/v1alpha1"
+)
+
+// render the inline renovate config of the renovatejob as formatted JSON, nil if it is not set
+func RenderRenovateConfig(spec *api.RenovateJobSpec) ([]byte, error) {
+	if spec.RenovateConfig == nil || len(spec.RenovateConfig.Raw) == 0 {
+		return nil, nil
+	}
+	var config map[string]any
+	if err := json.Unmarshal(spec.RenovateConfig.Raw, &config); err != nil {
+		return nil, fmt.Errorf("renovateConfig must be an object: %w", err)
+	}
+	// map keys are sorted, so the same config is always rendered the same way
+	return json.MarshalIndent(config, "", "  ")
+}
+
+// short hash of the rendered renovate config, empty if it is not set or invalid
+func RenovateConfigHash(spec *api.RenovateJobSpec) string {
+	rendered, err := RenderRenovateConfig(spec)
+	if err != nil || rendered == nil {
+		return ""
+	}
+	hash := sha256.Sum256(rendered)
+	return fmt.Sprintf("%x", hash[:8])
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	api "renovate-operator/api/v1alpha1"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRenderRenovateConfig(t *testing.T) {
+	spec := &api.RenovateJobSpec{}
+	if rendered, err := RenderRenovateConfig(spec); err != nil || rendered != nil {
+		t.Fatalf("expected no config, got %s, %v", rendered, err)
+	}
+	if hash := RenovateConfigHash(spec); hash != "" {
+		t.Errorf("expected no hash without config, got %s", hash)
+	}
+
+	spec.RenovateConfig = &runtime.RawExtension{Raw: []byte(`{"onboarding":false,"hostRules":[{"matchHost":"registry.internal","hostType":"docker"}]}`)}
+	rendered, err := RenderRenovateConfig(spec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{
+  "hostRules": [
+    {
+      "hostType": "docker",
+      "matchHost": "registry.internal"
+    }
+  ],
+  "onboarding": false
+}`
+	if string(rendered) != expected {
+		t.Errorf("RenderRenovateConfig() = %s, want %s", rendered, expected)
+	}
+
+	hash := RenovateConfigHash(spec)
+	if len(hash) != 16 {
+		t.Errorf("expected a 16 character hash, got %q", hash)
+	}
+	spec.RenovateConfig = &runtime.RawExtension{Raw: []byte(`{"hostRules":[{"hostType":"docker","matchHost":"registry.internal"}],"onboarding":false}`)}
+	if RenovateConfigHash(spec) != hash {
+		t.Error("expected the same hash for the same config in another key order")
+	}
+	spec.RenovateConfig = &runtime.RawExtension{Raw: []byte(`{"onboarding":true}`)}
+	if RenovateConfigHash(spec) == hash {
+		t.Error("expected another hash for a changed config")
+	}
+
+	spec.RenovateConfig = &runtime.RawExtension{Raw: []byte(`["not", "an", "object"]`)}
+	if _, err := RenderRenovateConfig(spec); err == nil {
+		t.Error("expected an error for a config that is not an object")
+	}
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"fmt"
+	api "renovate-operator/api/v1alpha1"
+	"strings"
+	"time"
+)
+
+var weekdays = map[api.Weekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// cron expression of a renovatejob including its time zone
+func GetScheduleExpression(spec *api.RenovateJobSpec) string {
+	// an explicit time zone in the schedule takes precedence
+	if spec.TimeZone == "" || strings.HasPrefix(spec.Schedule, "CRON_TZ=") || strings.HasPrefix(spec.Schedule, "TZ=") {
+		return spec.Schedule
+	}
+	return "CRON_TZ=" + spec.TimeZone + " " + spec.Schedule
+}
+
+// whether scheduled projects of the renovatejob may be started at the given time
+func IsInExecutionWindow(spec *api.RenovateJobSpec, now time.Time) (bool, error) {
+	if len(spec.ExecutionWindows) == 0 {
+		return true, nil
+	}
+	for i := range spec.ExecutionWindows {
+		start, end, err := getExecutionWindowAround(spec, &spec.ExecutionWindows[i], now)
+		if err != nil {
+			return false, err
+		}
+		if !start.IsZero() && !now.Before(start) && now.Before(end) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// start of the next execution window after the given time, zero if the renovatejob has no windows
+func GetNextExecutionWindow(spec *api.RenovateJobSpec, now time.Time) (time.Time, error) {
+	var next time.Time
+	for i := range spec.ExecutionWindows {
+		window := &spec.ExecutionWindows[i]
+		location, err := getExecutionWindowLocation(spec, window)
+		if err != nil {
+			return time.Time{}, err
+		}
+		local := now.In(location)
+		// every window starts at least once a week
+		for day := 0; day <= 7; day++ {
+			start, _, err := getExecutionWindowOnDay(window, local.AddDate(0, 0, day))
+			if err != nil {
+				return time.Time{}, err
+			}
+			if start.IsZero() || !start.After(now) {
+				continue
+			}
+			if next.IsZero() || start.Before(next) {
+				next = start
+			}
+			break
+		}
+	}
+	return next, nil
+}
+
+// the occurrence of the window that might contain the given time, zero if there is none
+func getExecutionWindowAround(spec *api.RenovateJobSpec, window *api.RenovateExecutionWindow, now time.Time) (time.Time, time.Time, error) {
+	location, err := getExecutionWindowLocation(spec, window)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	local := now.In(location)
+	// windows ending on the next day might have started yesterday
+	for _, day := range []time.Time{local, local.AddDate(0, 0, -1)} {
+		start, end, err := getExecutionWindowOnDay(window, day)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if !start.IsZero() && !now.Before(start) && now.Before(end) {
+			return start, end, nil
+		}
+	}
+	return time.Time{}, time.Time{}, nil
+}
+
+// start and end of the window starting on the day of the given time, zero if the window does not start on that day
+func getExecutionWindowOnDay(window *api.RenovateExecutionWindow, day time.Time) (time.Time, time.Time, error) {
+	if len(window.Days) > 0 {
+		startsOnDay := false
+		for _, d := range window.Days {
+			weekday, ok := weekdays[d]
+			if !ok {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid day %q in execution window", d)
+			}
+			if weekday == day.Weekday() {
+				startsOnDay = true
+			}
+		}
+		if !startsOnDay {
+			return time.Time{}, time.Time{}, nil
+		}
+	}
+
+	startHour, startMinute, err := parseTimeOfDay(window.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endHour, endMinute, err := parseTimeOfDay(window.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	year, month, date := day.Date()
+	start := time.Date(year, month, date, startHour, startMinute, 0, 0, day.Location())
+	end := time.Date(year, month, date, endHour, endMinute, 0, 0, day.Location())
+	if !end.After(start) {
+		end = time.Date(year, month, date+1, endHour, endMinute, 0, 0, day.Location())
+	}
+	return start, end, nil
+}
+
+func getExecutionWindowLocation(spec *api.RenovateJobSpec, window *api.RenovateExecutionWindow) (*time.Location, error) {
+	timeZone := window.TimeZone
+	if timeZone == "" {
+		timeZone = spec.TimeZone
+	}
+	if timeZone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q in execution window: %w", timeZone, err)
+	}
+	return location, nil
+}
+
+// parse a time of day in the format HH:MM
+func parseTimeOfDay(value string) (int, int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q in execution window, expected HH:MM: %w", value, err)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
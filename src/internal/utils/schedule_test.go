@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	api "renovate-operator/api/v1alpha1"
+)
+
+func TestGetScheduleExpression(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     api.RenovateJobSpec
+		expected string
+	}{
+		{
+			name:     "without time zone",
+			spec:     api.RenovateJobSpec{Schedule: "0 2 * * *"},
+			expected: "0 2 * * *",
+		},
+		{
+			name:     "with time zone",
+			spec:     api.RenovateJobSpec{Schedule: "0 2 * * *", TimeZone: "Europe/Berlin"},
+			expected: "CRON_TZ=Europe/Berlin 0 2 * * *",
+		},
+		{
+			name:     "time zone in schedule takes precedence",
+			spec:     api.RenovateJobSpec{Schedule: "CRON_TZ=UTC 0 2 * * *", TimeZone: "Europe/Berlin"},
+			expected: "CRON_TZ=UTC 0 2 * * *",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetScheduleExpression(&tt.spec); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsInExecutionWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	weekdayNights := api.RenovateJobSpec{
+		TimeZone: "Europe/Berlin",
+		ExecutionWindows: []api.RenovateExecutionWindow{
+			{Days: []api.Weekday{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "02:00", End: "06:00"},
+		},
+	}
+	overnight := api.RenovateJobSpec{
+		ExecutionWindows: []api.RenovateExecutionWindow{
+			{Days: []api.Weekday{"Fri"}, Start: "22:00", End: "04:00", TimeZone: "Europe/Berlin"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		spec     api.RenovateJobSpec
+		now      time.Time
+		expected bool
+	}{
+		{name: "no windows", spec: api.RenovateJobSpec{}, now: time.Date(2026, 1, 5, 12, 0, 0, 0, berlin), expected: true},
+		{name: "inside window", spec: weekdayNights, now: time.Date(2026, 1, 5, 3, 0, 0, 0, berlin), expected: true},
+		{name: "window start is inclusive", spec: weekdayNights, now: time.Date(2026, 1, 5, 2, 0, 0, 0, berlin), expected: true},
+		{name: "window end is exclusive", spec: weekdayNights, now: time.Date(2026, 1, 5, 6, 0, 0, 0, berlin), expected: false},
+		{name: "peak hours", spec: weekdayNights, now: time.Date(2026, 1, 5, 12, 0, 0, 0, berlin), expected: false},
+		{name: "weekend", spec: weekdayNights, now: time.Date(2026, 1, 4, 3, 0, 0, 0, berlin), expected: false},
+		{name: "other time zone", spec: weekdayNights, now: time.Date(2026, 1, 5, 2, 30, 0, 0, time.UTC), expected: true},
+		{name: "overnight window before midnight", spec: overnight, now: time.Date(2026, 1, 9, 23, 0, 0, 0, berlin), expected: true},
+		{name: "overnight window after midnight", spec: overnight, now: time.Date(2026, 1, 10, 3, 0, 0, 0, berlin), expected: true},
+		{name: "overnight window on the wrong day", spec: overnight, now: time.Date(2026, 1, 9, 3, 0, 0, 0, berlin), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsInExecutionWindow(&tt.spec, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsInExecutionWindow_InvalidTimeZone(t *testing.T) {
+	spec := api.RenovateJobSpec{
+		TimeZone:         "Mars/Olympus",
+		ExecutionWindows: []api.RenovateExecutionWindow{{Start: "02:00", End: "06:00"}},
+	}
+	if _, err := IsInExecutionWindow(&spec, time.Now()); err == nil {
+		t.Fatalf("expected an error for an invalid time zone")
+	}
+}
+
+func TestGetNextExecutionWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	spec := api.RenovateJobSpec{
+		TimeZone: "Europe/Berlin",
+		ExecutionWindows: []api.RenovateExecutionWindow{
+			{Days: []api.Weekday{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "02:00", End: "06:00"},
+			{Days: []api.Weekday{"Sat"}, Start: "10:00", End: "12:00"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected time.Time
+	}{
+		{name: "later today", now: time.Date(2026, 1, 5, 1, 0, 0, 0, berlin), expected: time.Date(2026, 1, 5, 2, 0, 0, 0, berlin)},
+		{name: "next day", now: time.Date(2026, 1, 5, 12, 0, 0, 0, berlin), expected: time.Date(2026, 1, 6, 2, 0, 0, 0, berlin)},
+		{name: "second window", now: time.Date(2026, 1, 9, 12, 0, 0, 0, berlin), expected: time.Date(2026, 1, 10, 10, 0, 0, 0, berlin)},
+		{name: "after the weekend", now: time.Date(2026, 1, 10, 12, 0, 0, 0, berlin), expected: time.Date(2026, 1, 12, 2, 0, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetNextExecutionWindow(&spec, tt.now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
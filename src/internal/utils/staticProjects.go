@@ -0,0 +1,23 @@
+package utils
+
+import (
+	api "renovate-operator/api/v1alpha1"
+	"slices"
+)
+
+// whether the projects of the renovatejob are autodiscovered, by default only without static projects
+func IsAutodiscoveryEnabled(spec *api.RenovateJobSpec) bool {
+	if spec.Autodiscover != nil {
+		return *spec.Autodiscover
+	}
+	return len(spec.Projects) == 0
+}
+
+// static projects of the renovatejob merged with the discovered projects, sorted and without duplicates
+func MergeProjects(static []string, discovered []string) []string {
+	projects := make([]string, 0, len(static)+len(discovered))
+	projects = append(projects, static...)
+	projects = append(projects, discovered...)
+	slices.Sort(projects)
+	return slices.Compact(projects)
+}
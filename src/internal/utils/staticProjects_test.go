@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+
+	api "renovate-operator/api/v1alpha1"
+)
+
+func TestIsAutodiscoveryEnabled(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		name     string
+		spec     api.RenovateJobSpec
+		expected bool
+	}{
+		{name: "no static projects", spec: api.RenovateJobSpec{}, expected: true},
+		{name: "static projects", spec: api.RenovateJobSpec{Projects: []string{"org/repo"}}, expected: false},
+		{name: "static projects with autodiscovery", spec: api.RenovateJobSpec{Projects: []string{"org/repo"}, Autodiscover: &enabled}, expected: true},
+		{name: "autodiscovery disabled", spec: api.RenovateJobSpec{Autodiscover: &disabled}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsAutodiscoveryEnabled(&tt.spec); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMergeProjects(t *testing.T) {
+	got := MergeProjects([]string{"org/b", "org/a"}, []string{"org/c", "org/a"})
+	if !slices.Equal(got, []string{"org/a", "org/b", "org/c"}) {
+		t.Errorf("unexpected projects: %v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package metricStore
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 )
@@ -26,12 +28,28 @@ var (
 			Help: "Whether the last Renovate run had WARN/ERROR log entries (1=issues found, 0=clean)",
 		},
 		[]string{"renovate_namespace", "renovate_job", "project"})
+
+	lastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "renovate_operator_project_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful Renovate run for this project",
+		},
+		[]string{"renovate_namespace", "renovate_job", "project"})
+
+	projectQuarantined = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "renovate_operator_project_quarantined",
+			Help: "Whether the project is quarantined after failing repeatedly (1=quarantined, 0=not quarantined)",
+		},
+		[]string{"renovate_namespace", "renovate_job", "project"})
 )
 
 func Register(registry ctrlmetrics.RegistererGatherer) {
 	registry.MustRegister(projectRuns)
 	registry.MustRegister(runFailed)
 	registry.MustRegister(dependencyIssues)
+	registry.MustRegister(projectQuarantined)
+	registry.MustRegister(lastSuccess)
 }
 
 func CaptureRenovateProjectExecution(namespace, job, project, status string) {
@@ -61,10 +79,26 @@ func SetDependencyIssues(namespace, job, project string, hasIssues bool) {
 	dependencyIssues.WithLabelValues(namespace, job, project).Set(value)
 }
 
+// SetProjectQuarantined sets the project_quarantined gauge for a project
+func SetProjectQuarantined(namespace, job, project string, quarantined bool) {
+	value := 0.0
+	if quarantined {
+		value = 1.0
+	}
+	projectQuarantined.WithLabelValues(namespace, job, project).Set(value)
+}
+
+// SetLastSuccess sets the last_success_timestamp_seconds gauge for a project
+func SetLastSuccess(namespace, job, project string, timestamp time.Time) {
+	lastSuccess.WithLabelValues(namespace, job, project).Set(float64(timestamp.Unix()))
+}
+
 // DeleteProjectMetrics removes all metrics for a project that was removed from discovery
 func DeleteProjectMetrics(namespace, job, project string) {
 	runFailed.DeleteLabelValues(namespace, job, project)
 	dependencyIssues.DeleteLabelValues(namespace, job, project)
+	projectQuarantined.DeleteLabelValues(namespace, job, project)
+	lastSuccess.DeleteLabelValues(namespace, job, project)
 	// Note: projectRuns counter has an additional "status" label, so we delete both possible values
 	projectRuns.DeleteLabelValues(namespace, job, project, "completed")
 	projectRuns.DeleteLabelValues(namespace, job, project, "failed")
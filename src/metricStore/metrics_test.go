@@ -2,6 +2,7 @@ package metricStore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/testutil"
 )
@@ -72,6 +73,33 @@ func TestSetDependencyIssues(t *testing.T) {
 	}
 }
 
+func TestSetProjectQuarantined(t *testing.T) {
+	SetProjectQuarantined("test-ns", "test-job", "test-project", true)
+	if value := testutil.ToFloat64(projectQuarantined.WithLabelValues("test-ns", "test-job", "test-project")); value != 1.0 {
+		t.Errorf("SetProjectQuarantined(true) = %v, want 1.0", value)
+	}
+
+	SetProjectQuarantined("test-ns", "test-job", "test-project", false)
+	if value := testutil.ToFloat64(projectQuarantined.WithLabelValues("test-ns", "test-job", "test-project")); value != 0.0 {
+		t.Errorf("SetProjectQuarantined(false) = %v, want 0.0", value)
+	}
+
+	// Cleanup
+	projectQuarantined.DeleteLabelValues("test-ns", "test-job", "test-project")
+}
+
+func TestSetLastSuccess(t *testing.T) {
+	timestamp := time.Unix(1767225600, 0)
+	SetLastSuccess("test-ns", "test-job", "test-project", timestamp)
+
+	if value := testutil.ToFloat64(lastSuccess.WithLabelValues("test-ns", "test-job", "test-project")); value != 1767225600 {
+		t.Errorf("SetLastSuccess() = %v, want 1767225600", value)
+	}
+
+	// Cleanup
+	lastSuccess.DeleteLabelValues("test-ns", "test-job", "test-project")
+}
+
 func TestCaptureRenovateProjectExecution(t *testing.T) {
 	// Capture a completed execution
 	CaptureRenovateProjectExecution("test-ns", "test-job", "test-project", "completed")
@@ -157,6 +157,25 @@ func TestGetNextRun(t *testing.T) {
 	}
 }
 
+func TestGetNextRunOnScheduleWithTimeZone(t *testing.T) {
+	h := health.NewHealthCheck()
+	s := NewScheduler(testLogger, h)
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	nextRun := s.GetNextRunOnSchedule("CRON_TZ=Europe/Berlin 0 2 * * *")
+	if nextRun.IsZero() {
+		t.Fatal("Next run time should not be zero for a schedule with time zone")
+	}
+	local := nextRun.In(berlin)
+	if local.Hour() != 2 || local.Minute() != 0 {
+		t.Errorf("Next run should be at 02:00 Europe/Berlin, got %v", local)
+	}
+}
+
 func TestScheduleExecution(t *testing.T) {
 	h := health.NewHealthCheck()
 	s := NewScheduler(testLogger, h)
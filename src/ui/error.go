@@ -41,3 +41,10 @@ func badRequestError(w http.ResponseWriter, err error, message string) {
 		Error:      err,
 	})
 }
+func notFoundError(w http.ResponseWriter, err error, message string) {
+	writeError(w, HttpResultError{
+		Message:    message,
+		StatusCode: http.StatusNotFound,
+		Error:      err,
+	})
+}
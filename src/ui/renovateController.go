@@ -7,13 +7,14 @@ import (
 	api "renovate-operator/api/v1alpha1"
 	"strings"
 	crdmanager "renovate-operator/internal/crdManager"
-	"renovate-operator/internal/gitprovider"
+	"renovate-operator/internal/renovate"
 	"renovate-operator/internal/types"
 	"renovate-operator/internal/utils"
 	"time"
 
 	"github.com/gorilla/mux"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 )
 
 type RenovateJobInfo struct {
@@ -26,6 +27,19 @@ type RenovateJobInfo struct {
 	Platform         string                             `json:"platform,omitempty"`
 	PlatformEndpoint string                             `json:"platformEndpoint,omitempty"`
 	ExecutionOptions *ExecutionOptions                  `json:"executionOptions,omitempty"`
+	Suspended        bool                               `json:"suspended,omitempty"`
+	// projects the last discovery would have removed, waiting for confirmation
+	PendingProjectRemoval *api.RenovatePendingProjectRemoval `json:"pendingProjectRemoval,omitempty"`
+	// result of the last config validation, only set if the config is validated
+	ConfigValidation *ConfigValidation `json:"configValidation,omitempty"`
+}
+
+type ConfigValidation struct {
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// scheduled projects are not started until the config validated
+	Blocking bool `json:"blocking,omitempty"`
 }
 
 type ExecutionOptions struct {
@@ -210,9 +224,13 @@ func (s *Server) registerApiV1Routes(router *mux.Router) {
 	apiV1.HandleFunc("/renovatejobs", s.getRenovateJobs).Methods("GET")
 	apiV1.HandleFunc("/renovate", s.runRenovateForProject).Methods("POST")
 	apiV1.HandleFunc("/renovate/all", s.runRenovateForAllProjects).Methods("POST")
+	apiV1.HandleFunc("/renovate/release", s.releaseProject).Methods("POST")
+	apiV1.HandleFunc("/renovate/suspend", s.suspendProject).Methods("POST")
 	apiV1.HandleFunc("/logs", s.getRenovateJobLogs).Methods("GET")
 	apiV1.HandleFunc("/discovery/start", s.runDiscoveryForProject).Methods("POST")
 	apiV1.HandleFunc("/discovery/status", s.discoveryStatusForProject).Methods("GET")
+	apiV1.HandleFunc("/discovery/confirm", s.confirmProjectRemoval).Methods("POST")
+	apiV1.HandleFunc("/discovery/history", s.discoveryHistoryForProject).Methods("GET")
 	apiV1.HandleFunc("/executionOptions", s.updateExecutionOptions).Methods("POST")
 }
 
@@ -232,6 +250,28 @@ func (s *Server) getRenovateJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// load the projects of all jobs, the queue position depends on every job
+	projectsByJob := make(map[string][]crdmanager.RenovateProjectStatus, len(renovateJobs))
+	queuedJobs := make([]renovate.QueuedRenovateJob, 0, len(renovateJobs))
+	for i := range renovateJobs {
+		renovateJob := &renovateJobs[i]
+		projects, err := s.manager.GetProjectsForRenovateJob(r.Context(), crdmanager.RenovateJobIdentifier{
+			Name:      renovateJob.Name,
+			Namespace: renovateJob.Namespace,
+		})
+		if err != nil {
+			internalServerError(w, err, "failed to load projects")
+			return
+		}
+		projectsByJob[renovateJob.Fullname()] = projects
+		queuedJobs = append(queuedJobs, renovate.QueuedRenovateJob{Job: renovateJob, Projects: projects})
+	}
+	now := time.Now()
+	queuePositions := make(map[string]int)
+	for _, entry := range renovate.BuildQueue(queuedJobs, now) {
+		queuePositions[entry.Job.Fullname()+"/"+entry.Project] = entry.Position
+	}
+
 	// Filter jobs based on user's groups
 	authEnabled := s.auth != nil
 	session := getSessionFromContext(r)
@@ -253,22 +293,16 @@ func (s *Server) getRenovateJobs(w http.ResponseWriter, r *http.Request) {
 
 		platform, platformEndpoint := utils.GetPlatformAndEndpoint(renovateJob.Spec.Provider)
 
-		projects := make([]crdmanager.RenovateProjectStatus, 0, len(renovateJob.Status.Projects))
-		for _, p := range renovateJob.Status.Projects {
-			projects = append(projects, crdmanager.RenovateProjectStatus{
-				Name:                 p.Name,
-				Status:               p.Status,
-				LastRun:              p.LastRun.Time,
-				Priority:             p.Priority,
-				RenovateResultStatus: p.RenovateResultStatus,
-				Duration:             p.Duration,
-			})
+		projects := projectsByJob[renovateJob.Fullname()]
+		for j := range projects {
+			projects[j].QueuePosition = queuePositions[renovateJob.Fullname()+"/"+projects[j].Name]
+			projects[j].Stale = utils.IsProjectStale(&renovateJob.Spec, projects[j].LastSuccess, now)
 		}
 
 		result = append(result, RenovateJobInfo{
 			Name:             renovateJob.Name,
 			Namespace:        renovateJob.Namespace,
-			NextSchedule:     s.scheduler.GetNextRunOnSchedule(renovateJob.Spec.Schedule),
+			NextSchedule:     s.scheduler.GetNextRunOnSchedule(utils.GetScheduleExpression(&renovateJob.Spec)),
 			Projects:         projects,
 			CronExpression:   renovateJob.Spec.Schedule,
 			DiscoveryStatus:  discoveryStatus,
@@ -277,6 +311,9 @@ func (s *Server) getRenovateJobs(w http.ResponseWriter, r *http.Request) {
 			ExecutionOptions: &ExecutionOptions{
 				Debug: renovateJob.Status.ExecutionOptions != nil && renovateJob.Status.ExecutionOptions.Debug,
 			},
+			Suspended:             renovateJob.Spec.Suspend,
+			PendingProjectRemoval: renovateJob.Status.PendingProjectRemoval,
+			ConfigValidation:      getConfigValidation(renovateJob),
 		})
 	}
 
@@ -284,6 +321,23 @@ func (s *Server) getRenovateJobs(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(result)
 }
 
+// result of the last config validation of the renovatejob, nil if the config is not validated
+func getConfigValidation(renovateJob *api.RenovateJob) *ConfigValidation {
+	if renovateJob.Spec.ConfigValidation == nil {
+		return nil
+	}
+	condition := meta.FindStatusCondition(renovateJob.Status.Conditions, api.ConditionConfigValid)
+	if condition == nil {
+		return nil
+	}
+	return &ConfigValidation{
+		Status:   string(condition.Status),
+		Reason:   condition.Reason,
+		Message:  condition.Message,
+		Blocking: crdmanager.IsConfigValidationBlocking(renovateJob),
+	}
+}
+
 func (s *Server) getRenovateJobLogs(w http.ResponseWriter, r *http.Request) {
 	namespace := r.URL.Query().Get("namespace")
 	renovate := r.URL.Query().Get("renovate")
@@ -403,6 +457,10 @@ func (s *Server) runRenovateForProject(w http.ResponseWriter, r *http.Request) {
 			Priority: 2,
 		},
 	)
+	if errors.IsNotFound(err) {
+		notFoundError(w, err, "project not found")
+		return
+	}
 	if err != nil {
 		s.logger.Error(err, "Failed to run Renovate for project", "project", params.project, "renovateJob", params.name, "namespace", params.namespace)
 		internalServerError(w, err, "failed to run Renovate for project")
@@ -451,6 +509,102 @@ func (s *Server) runRenovateForAllProjects(w http.ResponseWriter, r *http.Reques
 	s.logger.V(2).Info("Successfully triggered all projects", "renovateJob", params.name, "namespace", params.namespace)
 }
 
+// release a quarantined project and schedule it right away
+func (s *Server) releaseProject(w http.ResponseWriter, r *http.Request) {
+	params, err := getRenovateJsonBody(r)
+	if err != nil {
+		badRequestError(w, err, "failed to parse request body")
+		return
+	}
+
+	if params.name == "" || params.namespace == "" || params.project == "" {
+		badRequestError(w, err, "Missing parameters")
+		return
+	}
+
+	// Authorization check
+	if !s.authorizeJobAccess(r, params.namespace, params.name) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	err = s.manager.UpdateProjectStatus(
+		r.Context(),
+		params.project,
+		crdmanager.RenovateJobIdentifier{
+			Name:      params.name,
+			Namespace: params.namespace,
+		},
+		&types.RenovateStatusUpdate{
+			Status:   api.JobStatusScheduled,
+			Priority: 2,
+			Release:  true,
+		},
+	)
+	if errors.IsNotFound(err) {
+		notFoundError(w, err, "project not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to release project", "project", params.project, "renovateJob", params.name, "namespace", params.namespace)
+		internalServerError(w, err, "failed to release project")
+		return
+	}
+
+	writeSuccess(w, SuccessResult{Message: "Project released from quarantine"})
+	s.logger.V(2).Info("Successfully released project from quarantine", "project", params.project, "renovateJob", params.name, "namespace", params.namespace)
+}
+
+// suspend or resume a single project
+func (s *Server) suspendProject(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		RenovateJob string `json:"renovateJob"`
+		Namespace   string `json:"namespace"`
+		Project     string `json:"project"`
+		Suspended   bool   `json:"suspended"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		badRequestError(w, err, "failed to parse request body")
+		return
+	}
+	if params.RenovateJob == "" || params.Namespace == "" || params.Project == "" {
+		badRequestError(w, nil, "missing parameters")
+		return
+	}
+
+	// Authorization check
+	if !s.authorizeJobAccess(r, params.Namespace, params.RenovateJob) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	err := s.manager.SetProjectSuspended(
+		r.Context(),
+		params.Project,
+		crdmanager.RenovateJobIdentifier{
+			Name:      params.RenovateJob,
+			Namespace: params.Namespace,
+		},
+		params.Suspended,
+	)
+	if errors.IsNotFound(err) {
+		notFoundError(w, err, "project not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error(err, "Failed to suspend project", "project", params.Project, "renovateJob", params.RenovateJob, "namespace", params.Namespace, "suspended", params.Suspended)
+		internalServerError(w, err, "failed to suspend project")
+		return
+	}
+
+	message := "Project resumed"
+	if params.Suspended {
+		message = "Project suspended"
+	}
+	writeSuccess(w, SuccessResult{Message: message})
+	s.logger.V(2).Info("Successfully updated project suspension", "project", params.Project, "renovateJob", params.RenovateJob, "namespace", params.Namespace, "suspended", params.Suspended)
+}
+
 func (s *Server) runDiscoveryForProject(w http.ResponseWriter, r *http.Request) {
 	params, err := getRenovateJsonBody(r)
 	if err != nil {
@@ -475,6 +629,25 @@ func (s *Server) runDiscoveryForProject(w http.ResponseWriter, r *http.Request)
 	}
 
 	ctx := r.Context()
+	jobIdentifier := crdmanager.RenovateJobIdentifier{
+		Name:      params.name,
+		Namespace: params.namespace,
+	}
+	// static projects are reconciled without starting a discovery
+	if !utils.IsAutodiscoveryEnabled(&job.Spec) {
+		err = s.manager.ReconcileProjects(ctx, jobIdentifier, job.Spec.Projects)
+		if crdmanager.IsProjectRemovalBlocked(err) {
+			writeSuccess(w, SuccessResult{Message: "projects are not applied until the removal is confirmed"})
+			return
+		}
+		if err != nil {
+			internalServerError(w, err, "failed to reconcile projects")
+			return
+		}
+		writeSuccess(w, SuccessResult{Message: "projects reconciled"})
+		return
+	}
+
 	// discovery mus only run once
 	status, err := s.discovery.GetDiscoveryJobStatus(ctx, job, "")
 	if err == nil && status == api.JobStatusRunning {
@@ -483,39 +656,42 @@ func (s *Server) runDiscoveryForProject(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	generation, err := s.discovery.CreateDiscoveryJob(ctx, *job)
-	if err != nil {
-		s.logger.Error(err, "Failed to start discovery for RenovateJob", "renovateJob", params.name, "namespace", params.namespace)
-		internalServerError(w, err, "failed to create discovery job")
-		return
+	// the api discovery mode does not start a discovery job, unless it falls back to one
+	discover := func(ctx context.Context) ([]string, error) {
+		return s.discovery.Discover(ctx, job)
+	}
+	if job.Spec.DiscoveryMode != api.DiscoveryModeAPI {
+		generation, err := s.discovery.CreateDiscoveryJob(ctx, *job)
+		if err != nil {
+			s.logger.Error(err, "Failed to start discovery for RenovateJob", "renovateJob", params.name, "namespace", params.namespace)
+			internalServerError(w, err, "failed to create discovery job")
+			return
+		}
+		discover = func(ctx context.Context) ([]string, error) {
+			return s.discovery.WaitForDiscoveryJob(ctx, job, generation)
+		}
 	}
+	// the discovery outlives the request, it keeps the values of the request context but not its cancellation
+	discoveryCtx := context.WithoutCancel(ctx)
 	go func() {
-		ctxBackground := context.Background()
-		projects, err := s.discovery.WaitForDiscoveryJob(ctxBackground, job, generation)
+		projects, err := discover(discoveryCtx)
 		if err != nil {
 			s.logger.Error(err, "Discovery job failed for RenovateJob", "renovateJob", params.name, "namespace", params.namespace)
 			return
 		}
 
-		if job.Spec.SkipForks && s.gitProviderClientFactory != nil {
-			providerClient, err := s.gitProviderClientFactory(ctxBackground, job)
-			if err != nil {
-				s.logger.Error(err, "Failed to create git provider client for fork filtering", "renovateJob", params.name, "namespace", params.namespace)
-				return
-			}
-			projects, err = gitprovider.FilterForks(ctxBackground, providerClient, s.logger, projects)
-			if err != nil {
-				s.logger.Error(err, "Failed to filter forked repositories", "renovateJob", params.name, "namespace", params.namespace)
-				return
-			}
+		projects, err = renovate.ApplyDiscoveryFilters(discoveryCtx, s.logger, job, projects, s.gitProviderClientFactory)
+		if err != nil {
+			s.logger.Error(err, "Failed to filter discovered projects", "renovateJob", params.name, "namespace", params.namespace)
+			return
 		}
 
 		// update all projects to scheduled
-		jobIdentifier := crdmanager.RenovateJobIdentifier{
-			Name:      params.name,
-			Namespace: params.namespace,
+		err = s.manager.ReconcileProjects(discoveryCtx, jobIdentifier, projects)
+		if crdmanager.IsProjectRemovalBlocked(err) {
+			s.logger.Info("Discovered projects are not applied until the removal is confirmed", "renovateJob", params.name, "namespace", params.namespace, "reason", err.Error())
+			return
 		}
-		err = s.manager.ReconcileProjects(ctxBackground, jobIdentifier, projects)
 		if err != nil {
 			s.logger.Error(err, "failed to reconcile projects")
 			return
@@ -526,6 +702,37 @@ func (s *Server) runDiscoveryForProject(w http.ResponseWriter, r *http.Request)
 	s.logger.V(2).Info("Successfully started discovery for RenovateJob", "renovateJob", params.name, "namespace", params.namespace)
 }
 
+func (s *Server) confirmProjectRemoval(w http.ResponseWriter, r *http.Request) {
+	params, err := getRenovateJsonBody(r)
+	if err != nil {
+		badRequestError(w, err, "failed to parse request body")
+		return
+	}
+	if params.name == "" || params.namespace == "" {
+		badRequestError(w, nil, "missing parameters")
+		return
+	}
+
+	// Authorization check
+	if !s.authorizeJobAccess(r, params.namespace, params.name) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	err = s.manager.ConfirmProjectRemoval(r.Context(), crdmanager.RenovateJobIdentifier{
+		Name:      params.name,
+		Namespace: params.namespace,
+	})
+	if err != nil {
+		s.logger.Error(err, "Failed to confirm project removal", "renovateJob", params.name, "namespace", params.namespace)
+		internalServerError(w, err, "failed to confirm project removal")
+		return
+	}
+
+	writeSuccess(w, SuccessResult{Message: "Project removal confirmed"})
+	s.logger.V(2).Info("Successfully confirmed project removal", "renovateJob", params.name, "namespace", params.namespace)
+}
+
 func (s *Server) updateExecutionOptions(w http.ResponseWriter, r *http.Request) {
 	var params struct {
 		RenovateJob string `json:"renovateJob"`
@@ -593,3 +800,30 @@ func (s *Server) discoveryStatusForProject(w http.ResponseWriter, r *http.Reques
 		Status: status,
 	})
 }
+
+func (s *Server) discoveryHistoryForProject(w http.ResponseWriter, r *http.Request) {
+	namespace := r.URL.Query().Get("namespace")
+	renovate := r.URL.Query().Get("renovate")
+
+	// Authorization check (returns job to avoid duplicate K8s API call)
+	job, authorized := s.authorizeAndGetJob(r, namespace, renovate)
+	if !authorized {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if job == nil {
+		internalServerError(w, nil, "failed to get renovate job")
+		return
+	}
+
+	history := job.Status.DiscoveryHistory
+	if history == nil {
+		history = []api.RenovateDiscoveryResult{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		History []api.RenovateDiscoveryResult `json:"history"`
+	}{
+		History: history,
+	})
+}
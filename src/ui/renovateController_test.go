@@ -27,6 +27,8 @@ type mockRenovateJobManager struct {
 	updateProjectStatusFunc       func(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error
 	getRenovateJobFunc            func(ctx context.Context, name, namespace string) (*api.RenovateJob, error)
 	reconcileProjectsFunc         func(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, projects []string) error
+	setProjectSuspendedFunc       func(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, suspended bool) error
+	confirmProjectRemovalFunc     func(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error
 }
 
 func (m *mockRenovateJobManager) ListRenovateJobs(ctx context.Context) ([]crdmanager.RenovateJobIdentifier, error) {
@@ -71,6 +73,10 @@ func (m *mockRenovateJobManager) GetRenovateJob(ctx context.Context, name, names
 	return nil, nil
 }
 
+func (m *mockRenovateJobManager) MigrateLegacyProjectStatus(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error {
+	return nil
+}
+
 func (m *mockRenovateJobManager) ReconcileProjects(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, projects []string) error {
 	if m.reconcileProjectsFunc != nil {
 		return m.reconcileProjectsFunc(ctx, jobId, projects)
@@ -110,6 +116,36 @@ func (m *mockRenovateJobManager) UpdateExecutionOptions(ctx context.Context, job
 	return nil
 }
 
+func (m *mockRenovateJobManager) UpdateConfigValidation(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, configHash string, condition *metav1.Condition) error {
+	return nil
+}
+
+func (m *mockRenovateJobManager) UpdateSecretsHash(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, hash string) error {
+	return nil
+}
+
+func (m *mockRenovateJobManager) SetProjectSuspended(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, suspended bool) error {
+	if m.setProjectSuspendedFunc != nil {
+		return m.setProjectSuspendedFunc(ctx, project, jobId, suspended)
+	}
+	return nil
+}
+
+func (m *mockRenovateJobManager) ConfirmProjectRemoval(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error {
+	if m.confirmProjectRemovalFunc != nil {
+		return m.confirmProjectRemovalFunc(ctx, jobId)
+	}
+	return nil
+}
+
+func (m *mockRenovateJobManager) AddProject(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, project string) (bool, error) {
+	return true, nil
+}
+
+func (m *mockRenovateJobManager) RemoveProjects(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, projects []string) ([]string, error) {
+	return projects, nil
+}
+
 // Mock DiscoveryAgent
 type mockDiscoveryAgent struct {
 	getDiscoveryJobStatusFunc func(ctx context.Context, job *api.RenovateJob, generation string) (api.RenovateProjectStatus, error)
@@ -313,6 +349,241 @@ func TestRunRenovateForProject_MissingParams(t *testing.T) {
 	}
 }
 
+func TestReleaseProject_Success(t *testing.T) {
+	var update *types.RenovateStatusUpdate
+	mockManager := &mockRenovateJobManager{
+		updateProjectStatusFunc: func(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+			update = status
+			return nil
+		},
+		getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+			return &api.RenovateJob{}, nil
+		},
+	}
+
+	server := &Server{
+		manager: mockManager,
+		logger:  logr.Discard(),
+	}
+
+	body := map[string]string{
+		"renovateJob": "job1",
+		"namespace":   "default",
+		"project":     "project1",
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/renovate/release", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.releaseProject(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if update == nil || !update.Release || update.Status != api.JobStatusScheduled {
+		t.Errorf("Expected the project to be released and scheduled, got %+v", update)
+	}
+}
+
+func TestReleaseProject_UnknownProject(t *testing.T) {
+	mockManager := &mockRenovateJobManager{
+		updateProjectStatusFunc: func(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
+			return k8serrors.NewNotFound(schema.GroupResource{}, project)
+		},
+		getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+			return &api.RenovateJob{}, nil
+		},
+	}
+
+	server := &Server{
+		manager: mockManager,
+		logger:  logr.Discard(),
+	}
+
+	body := map[string]string{
+		"renovateJob": "job1",
+		"namespace":   "default",
+		"project":     "misspelled",
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/renovate/release", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.releaseProject(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestSuspendProject(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         map[string]any
+		expectedCode int
+		expected     bool
+	}{
+		{
+			name:         "suspend",
+			body:         map[string]any{"renovateJob": "job1", "namespace": "default", "project": "project1", "suspended": true},
+			expectedCode: http.StatusOK,
+			expected:     true,
+		},
+		{
+			name:         "resume",
+			body:         map[string]any{"renovateJob": "job1", "namespace": "default", "project": "project1", "suspended": false},
+			expectedCode: http.StatusOK,
+			expected:     false,
+		},
+		{
+			name:         "missing project",
+			body:         map[string]any{"renovateJob": "job1", "namespace": "default", "suspended": true},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			var suspended bool
+			server := &Server{
+				manager: &mockRenovateJobManager{
+					setProjectSuspendedFunc: func(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, s bool) error {
+						called = true
+						suspended = s
+						return nil
+					},
+					getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+						return &api.RenovateJob{}, nil
+					},
+				},
+				logger: logr.Discard(),
+			}
+
+			jsonBody, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/renovate/suspend", bytes.NewReader(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			server.suspendProject(w, req)
+
+			if w.Code != tt.expectedCode {
+				t.Fatalf("Expected status %d, got %d", tt.expectedCode, w.Code)
+			}
+			if tt.expectedCode != http.StatusOK {
+				if called {
+					t.Errorf("Expected the manager not to be called")
+				}
+				return
+			}
+			if !called || suspended != tt.expected {
+				t.Errorf("Expected project to be suspended=%v, got called=%v suspended=%v", tt.expected, called, suspended)
+			}
+		})
+	}
+}
+
+func TestConfirmProjectRemoval(t *testing.T) {
+	var confirmed crdmanager.RenovateJobIdentifier
+	server := &Server{
+		manager: &mockRenovateJobManager{
+			confirmProjectRemovalFunc: func(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error {
+				confirmed = jobId
+				return nil
+			},
+			getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+				return &api.RenovateJob{}, nil
+			},
+		},
+		logger: logr.Discard(),
+	}
+
+	jsonBody, _ := json.Marshal(map[string]any{"renovateJob": "job1", "namespace": "default"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/discovery/confirm", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.confirmProjectRemoval(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if confirmed.Name != "job1" || confirmed.Namespace != "default" {
+		t.Errorf("Expected the removal of job1 in default to be confirmed, got %v", confirmed)
+	}
+}
+
+func TestConfirmProjectRemoval_Error(t *testing.T) {
+	server := &Server{
+		manager: &mockRenovateJobManager{
+			confirmProjectRemovalFunc: func(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error {
+				return k8serrors.NewNotFound(schema.GroupResource{}, "job1")
+			},
+			getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+				return &api.RenovateJob{}, nil
+			},
+		},
+		logger: logr.Discard(),
+	}
+
+	jsonBody, _ := json.Marshal(map[string]any{"renovateJob": "job1", "namespace": "default"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/discovery/confirm", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.confirmProjectRemoval(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestDiscoveryHistoryForProject(t *testing.T) {
+	mockManager := &mockRenovateJobManager{
+		getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+			return &api.RenovateJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "job1",
+					Namespace: "default",
+				},
+				Status: api.RenovateJobStatus{
+					DiscoveryHistory: []api.RenovateDiscoveryResult{
+						{Discovered: 3, AddedCount: 1, Added: []string{"org/new"}},
+					},
+				},
+			}, nil
+		},
+	}
+
+	server := &Server{
+		manager: mockManager,
+		logger:  logr.Discard(),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/discovery/history?namespace=default&renovate=job1", nil)
+	w := httptest.NewRecorder()
+
+	server.discoveryHistoryForProject(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var result struct {
+		History []api.RenovateDiscoveryResult `json:"history"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(result.History) != 1 || result.History[0].AddedCount != 1 || result.History[0].Added[0] != "org/new" {
+		t.Errorf("Unexpected discovery history: %+v", result.History)
+	}
+}
+
 func TestDiscoveryStatusForProject_Success(t *testing.T) {
 	mockManager := &mockRenovateJobManager{
 		getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
@@ -447,6 +718,52 @@ func TestRunDiscoveryForProject_AlreadyRunning(t *testing.T) {
 	}
 }
 
+func TestRunDiscoveryForProject_StaticProjects(t *testing.T) {
+	var reconciled []string
+	mockManager := &mockRenovateJobManager{
+		getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+			return &api.RenovateJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "job1", Namespace: "default"},
+				Spec:       api.RenovateJobSpec{Projects: []string{"org/repo1", "org/repo2"}},
+			}, nil
+		},
+		reconcileProjectsFunc: func(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, projects []string) error {
+			reconciled = projects
+			return nil
+		},
+	}
+
+	mockDiscovery := &mockDiscoveryAgent{
+		createDiscoveryJobFunc: func(ctx context.Context, renovateJob api.RenovateJob) error {
+			t.Fatal("no discovery job should be created for static projects")
+			return nil
+		},
+	}
+
+	server := &Server{
+		manager:   mockManager,
+		discovery: mockDiscovery,
+		logger:    logr.Discard(),
+	}
+
+	jsonBody, _ := json.Marshal(map[string]string{
+		"renovateJob": "job1",
+		"namespace":   "default",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/discovery/start", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	server.runDiscoveryForProject(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(reconciled) != 2 {
+		t.Errorf("Expected the static projects to be reconciled, got %v", reconciled)
+	}
+}
+
 // Additional mock types needed for authorization tests
 type mockScheduler struct{}
 
@@ -6,6 +6,9 @@ import (
 	api "renovate-operator/api/v1alpha1"
 	crdmanager "renovate-operator/internal/crdManager"
 	"renovate-operator/internal/types"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
 )
 
 // Forgejo webhook types and handler.
@@ -25,6 +28,7 @@ import (
 //     Dashboard and contains a checked checkbox
 //   - pull_request (edited/closed/reopened): only for PRs generated by
 //     Renovate, identified by body content rather than author username
+//   - repository (created/deleted): adds or removes the repository as project
 //
 // Additionally, Forgejo uses X-Forgejo-Signature for HMAC authentication
 // instead of X-Hub-Signature-256.
@@ -51,9 +55,11 @@ type ForgejoIssue struct {
 }
 
 type ForgejoRepository struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	FullName string `json:"full_name"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	FullName string   `json:"full_name"`
+	Fork     bool     `json:"fork,omitempty"`
+	Topics   []string `json:"topics,omitempty"`
 }
 
 type ForgejoUser struct {
@@ -75,6 +81,23 @@ func (s *Server) forgejoWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if event == "repository" {
+		switch payload.Action {
+		case "created":
+			s.applyRepositoryChanges(w, r, []repositoryChange{{
+				project:  payload.Repository.FullName,
+				topics:   payload.Repository.Topics,
+				fork:     ptr.To(payload.Repository.Fork),
+				archived: ptr.To(false),
+			}})
+		case "deleted":
+			s.applyRepositoryChanges(w, r, []repositoryChange{{project: payload.Repository.FullName, removed: true}})
+		default:
+			s.writeJSON(w, http.StatusOK, map[string]string{"message": "event ignored", "reason": "repository action is not created or deleted"})
+		}
+		return
+	}
+
 	valid, reason := isValidForgejoEvent(event, &payload)
 	if !valid {
 		s.logger.Info("ignoring Forgejo webhook event", "event", event, "repository", payload.Repository.FullName, "reason", reason)
@@ -102,6 +125,10 @@ func (s *Server) forgejoWebhook(w http.ResponseWriter, r *http.Request) {
 			Priority: 1,
 		},
 	)
+	if errors.IsNotFound(err) {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "project not found"})
+		return
+	}
 	if err != nil {
 		s.logger.Error(err, "Failed to process Forgejo webhook for repo", "repo", payload.Repository.FullName)
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
@@ -6,6 +6,9 @@ import (
 	api "renovate-operator/api/v1alpha1"
 	crdmanager "renovate-operator/internal/crdManager"
 	"renovate-operator/internal/types"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
 )
 
 type GitHubEvent struct {
@@ -28,12 +31,28 @@ type GitHubIssue struct {
 }
 
 type GitHubRepository struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	FullName string `json:"full_name"`
+	ID       int      `json:"id"`
+	Name     string   `json:"name"`
+	FullName string   `json:"full_name"`
+	Fork     bool     `json:"fork,omitempty"`
+	Topics   []string `json:"topics,omitempty"`
+}
+
+// GitHubRepositoryEvent is the payload of repository and installation_repositories events
+type GitHubRepositoryEvent struct {
+	Action              string             `json:"action"`
+	Repository          *GitHubRepository  `json:"repository,omitempty"`
+	RepositoriesAdded   []GitHubRepository `json:"repositories_added,omitempty"`
+	RepositoriesRemoved []GitHubRepository `json:"repositories_removed,omitempty"`
 }
 
 func (s *Server) githubWebhook(w http.ResponseWriter, r *http.Request) {
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "repository", "installation_repositories":
+		s.githubRepositoryWebhook(w, r, event)
+		return
+	}
+
 	var payload GitHubEvent
 	err := json.NewDecoder(r.Body).Decode(&payload)
 	if err != nil {
@@ -70,6 +89,10 @@ func (s *Server) githubWebhook(w http.ResponseWriter, r *http.Request) {
 			Priority: 1,
 		},
 	)
+	if errors.IsNotFound(err) {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "project not found"})
+		return
+	}
 	if err != nil {
 		s.logger.Error(err, "Failed to process GitHub webhook for repo", "repo", payload.Repository.FullName)
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
@@ -110,3 +133,51 @@ func isValidGitHubEvent(payload *GitHubEvent) (bool, string) {
 	}
 	return true, ""
 }
+
+func (s *Server) githubRepositoryWebhook(w http.ResponseWriter, r *http.Request, event string) {
+	var payload GitHubRepositoryEvent
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.logger.Error(err, "failed to decode github webhook payload. Not processing.")
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to decode payload"})
+		return
+	}
+
+	changes := githubRepositoryChanges(event, &payload)
+	if len(changes) == 0 {
+		s.logger.Info("ignoring github webhook event", "event", event, "action", payload.Action)
+		s.writeJSON(w, http.StatusOK, map[string]string{"message": "event ignored", "reason": "repository action is not handled"})
+		return
+	}
+	s.applyRepositoryChanges(w, r, changes)
+}
+
+func githubRepositoryChanges(event string, payload *GitHubRepositoryEvent) []repositoryChange {
+	var changes []repositoryChange
+	if event == "installation_repositories" {
+		// repositories added to or removed from the installation of a github app
+		for _, repo := range payload.RepositoriesAdded {
+			changes = append(changes, repositoryChange{project: repo.FullName})
+		}
+		for _, repo := range payload.RepositoriesRemoved {
+			changes = append(changes, repositoryChange{project: repo.FullName, removed: true})
+		}
+		return changes
+	}
+
+	if payload.Repository == nil {
+		return nil
+	}
+	// archived repositories are not discovered
+	switch payload.Action {
+	case "created", "unarchived":
+		changes = append(changes, repositoryChange{
+			project:  payload.Repository.FullName,
+			topics:   payload.Repository.Topics,
+			fork:     ptr.To(payload.Repository.Fork),
+			archived: ptr.To(false),
+		})
+	case "deleted", "archived":
+		changes = append(changes, repositoryChange{project: payload.Repository.FullName, removed: true})
+	}
+	return changes
+}
@@ -6,6 +6,9 @@ import (
 	api "renovate-operator/api/v1alpha1"
 	crdmanager "renovate-operator/internal/crdManager"
 	"renovate-operator/internal/types"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
 )
 
 type GitLabEvent struct {
@@ -14,6 +17,9 @@ type GitLabEvent struct {
 	Project          Project          `json:"project"`
 	ObjectAttributes ObjectAttributes `json:"object_attributes"`
 	Changes          Changes          `json:"changes"`
+	// set by system hooks, e.g. "project_create"
+	EventName         string `json:"event_name,omitempty"`
+	PathWithNamespace string `json:"path_with_namespace,omitempty"`
 }
 
 type Project struct {
@@ -46,6 +52,16 @@ func (s *Server) gitLabWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// project lifecycle events of system hooks
+	switch payload.EventName {
+	case "project_create":
+		s.applyRepositoryChanges(w, r, []repositoryChange{{project: payload.PathWithNamespace, archived: ptr.To(false)}})
+		return
+	case "project_destroy":
+		s.applyRepositoryChanges(w, r, []repositoryChange{{project: payload.PathWithNamespace, removed: true}})
+		return
+	}
+
 	valid, reason := isValidGitLabEvent(&payload)
 	if !valid {
 		s.logger.Info("ignoring GitLab webhook event", "reason", reason)
@@ -74,6 +90,10 @@ func (s *Server) gitLabWebhook(w http.ResponseWriter, r *http.Request) {
 			Priority: 1,
 		},
 	)
+	if errors.IsNotFound(err) {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "project not found"})
+		return
+	}
 	if err != nil {
 		s.logger.Error(err, "Failed to process GitLab webhook for project", "project", payload.Project.PathWithNamespace)
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
@@ -5,6 +5,8 @@ import (
 	api "renovate-operator/api/v1alpha1"
 	crdmanager "renovate-operator/internal/crdManager"
 	"renovate-operator/internal/types"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Mock RenovateJobManager for webhook integration tests
@@ -12,6 +14,9 @@ type mockWebhookManager struct {
 	updateProjectStatusFunc     func(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error
 	isWebhookTokenValidFunc     func(ctx context.Context, job crdmanager.RenovateJobIdentifier, token string) (bool, error)
 	isWebhookSignatureValidFunc func(ctx context.Context, job crdmanager.RenovateJobIdentifier, signature string, body []byte) (bool, error)
+	getRenovateJobFunc          func(ctx context.Context, name, namespace string) (*api.RenovateJob, error)
+	addProjectFunc              func(ctx context.Context, job crdmanager.RenovateJobIdentifier, project string) (bool, error)
+	removeProjectsFunc          func(ctx context.Context, job crdmanager.RenovateJobIdentifier, projects []string) ([]string, error)
 }
 
 func (m *mockWebhookManager) UpdateProjectStatus(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, status *types.RenovateStatusUpdate) error {
@@ -37,6 +42,30 @@ func (m *mockWebhookManager) IsWebhookSignatureValid(ctx context.Context, job cr
 func (m *mockWebhookManager) UpdateExecutionOptions(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, options *api.RenovateExecutionOptions) error {
 	return nil
 }
+func (m *mockWebhookManager) UpdateConfigValidation(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, configHash string, condition *metav1.Condition) error {
+	return nil
+}
+func (m *mockWebhookManager) UpdateSecretsHash(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, hash string) error {
+	return nil
+}
+func (m *mockWebhookManager) SetProjectSuspended(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, suspended bool) error {
+	return nil
+}
+func (m *mockWebhookManager) ConfirmProjectRemoval(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error {
+	return nil
+}
+func (m *mockWebhookManager) AddProject(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, project string) (bool, error) {
+	if m.addProjectFunc != nil {
+		return m.addProjectFunc(ctx, jobId, project)
+	}
+	return true, nil
+}
+func (m *mockWebhookManager) RemoveProjects(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, projects []string) ([]string, error) {
+	if m.removeProjectsFunc != nil {
+		return m.removeProjectsFunc(ctx, jobId, projects)
+	}
+	return projects, nil
+}
 
 // Implement remaining interface methods as no-ops for webhook tests
 func (m *mockWebhookManager) ListRenovateJobs(ctx context.Context) ([]crdmanager.RenovateJobIdentifier, error) {
@@ -52,11 +81,17 @@ func (m *mockWebhookManager) GetLogsForProject(ctx context.Context, jobId crdman
 	return "", nil
 }
 func (m *mockWebhookManager) GetRenovateJob(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+	if m.getRenovateJobFunc != nil {
+		return m.getRenovateJobFunc(ctx, name, namespace)
+	}
 	return nil, nil
 }
 func (m *mockWebhookManager) ReconcileProjects(ctx context.Context, jobId crdmanager.RenovateJobIdentifier, projects []string) error {
 	return nil
 }
+func (m *mockWebhookManager) MigrateLegacyProjectStatus(ctx context.Context, jobId crdmanager.RenovateJobIdentifier) error {
+	return nil
+}
 func (m *mockWebhookManager) UpdateProjectConfigStatus(ctx context.Context, project string, jobId crdmanager.RenovateJobIdentifier, status *string) error {
 	return nil
 }
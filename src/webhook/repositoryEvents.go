@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	api "renovate-operator/api/v1alpha1"
+	crdmanager "renovate-operator/internal/crdManager"
+	"renovate-operator/internal/gitprovider"
+	"renovate-operator/internal/utils"
+
+	"k8s.io/utils/ptr"
+)
+
+// Repository lifecycle events add created repositories to and remove deleted
+// repositories from the RenovateJob of the webhook, so new repositories do not
+// have to wait for the next discovery. Created repositories are only added if
+// the RenovateJob would discover them. If the event can not tell whether a skip
+// option of the RenovateJob applies, the repository is left to the next discovery.
+
+// change of a single repository announced by a lifecycle event
+type repositoryChange struct {
+	project string
+	// true if the repository was deleted or archived
+	removed bool
+	// topics of the repository, nil if the event does not contain them
+	topics []string
+	// fork and archived state of the repository, nil if the event does not contain them
+	fork     *bool
+	archived *bool
+}
+
+// skip option of the renovatejob the event can not answer for a created repository, empty if none
+func unansweredSkipOption(spec *api.RenovateJobSpec, change repositoryChange) string {
+	switch {
+	case spec.SkipForks && change.fork == nil:
+		return "skipForks"
+	case spec.SkipArchived && change.archived == nil:
+		return "skipArchived"
+	// events do not tell whether a repository has commits or when it was last pushed to
+	case spec.SkipEmpty:
+		return "skipEmpty"
+	case spec.SkipInactiveFor != nil:
+		return "skipInactiveFor"
+	default:
+		return ""
+	}
+}
+
+func (s *Server) applyRepositoryChanges(w http.ResponseWriter, r *http.Request, changes []repositoryChange) {
+	namespace := r.URL.Query().Get("namespace")
+	job := r.URL.Query().Get("job")
+	if namespace == "" || job == "" {
+		s.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing namespace or job query parameter"})
+		return
+	}
+
+	renovateJob, err := s.manager.GetRenovateJob(r.Context(), job, namespace)
+	if err != nil || renovateJob == nil {
+		s.logger.Error(err, "Failed to get RenovateJob for repository event", "renovateJob", job, "namespace", namespace)
+		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
+		return
+	}
+	if !utils.IsAutodiscoveryEnabled(&renovateJob.Spec) {
+		s.writeJSON(w, http.StatusOK, map[string]string{"message": "event ignored", "reason": "autodiscovery is disabled"})
+		return
+	}
+
+	jobIdentifier := crdmanager.RenovateJobIdentifier{
+		Name:      job,
+		Namespace: namespace,
+	}
+	var added, removals []string
+	for _, change := range changes {
+		// static projects are managed through the spec of the renovatejob
+		if slices.Contains(renovateJob.Spec.Projects, change.project) {
+			continue
+		}
+
+		if change.removed {
+			removals = append(removals, change.project)
+			continue
+		}
+
+		if option := unansweredSkipOption(&renovateJob.Spec, change); option != "" {
+			s.logger.Info("Leaving created repository to the next discovery", "project", change.project, "skipOption", option)
+			continue
+		}
+		if renovateJob.Spec.SkipForks && ptr.Deref(change.fork, false) {
+			s.logger.V(2).Info("Ignoring created fork", "project", change.project)
+			continue
+		}
+		if renovateJob.Spec.SkipArchived && ptr.Deref(change.archived, false) {
+			s.logger.V(2).Info("Ignoring archived repository", "project", change.project)
+			continue
+		}
+		matches, err := gitprovider.MatchesDiscovery(renovateJob, change.project, change.topics)
+		if err != nil {
+			s.logger.Error(err, "Failed to match project against the discovery filters", "project", change.project)
+			s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
+			return
+		}
+		if !matches {
+			s.logger.V(2).Info("Ignoring repository not matching the discovery filters", "project", change.project)
+			continue
+		}
+		ok, err := s.manager.AddProject(r.Context(), jobIdentifier, change.project)
+		if err != nil {
+			s.logger.Error(err, "Failed to add project for repository event", "project", change.project)
+			s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
+			return
+		}
+		if ok {
+			added = append(added, change.project)
+		}
+	}
+
+	// removals are applied together, so they are limited by maxProjectRemovals and recorded in the discovery history
+	var removed []string
+	if len(removals) > 0 {
+		removed, err = s.manager.RemoveProjects(r.Context(), jobIdentifier, removals)
+		if crdmanager.IsProjectRemovalBlocked(err) {
+			s.logger.Info("Project removal for repository event exceeds maxProjectRemovals and waits for confirmation", "renovateJob", job, "namespace", namespace, "projects", removals)
+			s.writeJSON(w, http.StatusAccepted, map[string]string{
+				"message": "project removal blocked",
+				"added":   strings.Join(added, ","),
+				"reason":  err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			s.logger.Error(err, "Failed to remove projects for repository event", "projects", removals)
+			s.writeJSON(w, http.StatusInternalServerError, map[string]string{"message": "failed to process webhook"})
+			return
+		}
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		s.writeJSON(w, http.StatusOK, map[string]string{"message": "event ignored", "reason": "no project added or removed"})
+		return
+	}
+	s.logger.Info("Applied repository event", "renovateJob", job, "namespace", namespace, "added", added, "removed", removed)
+	s.writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "projects updated",
+		"added":   strings.Join(added, ","),
+		"removed": strings.Join(removed, ","),
+	})
+}
@@ -0,0 +1,233 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	api "renovate-operator/api/v1alpha1"
+	crdmanager "renovate-operator/internal/crdManager"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// server recording the projects added to and removed from a renovatejob with the given spec
+func newRepositoryEventServer(spec api.RenovateJobSpec, added *[]string, removed *[]string) *Server {
+	mockManager := &mockWebhookManager{
+		getRenovateJobFunc: func(ctx context.Context, name, namespace string) (*api.RenovateJob, error) {
+			return &api.RenovateJob{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       spec,
+			}, nil
+		},
+		addProjectFunc: func(ctx context.Context, job crdmanager.RenovateJobIdentifier, project string) (bool, error) {
+			*added = append(*added, project)
+			return true, nil
+		},
+		removeProjectsFunc: func(ctx context.Context, job crdmanager.RenovateJobIdentifier, projects []string) ([]string, error) {
+			*removed = append(*removed, projects...)
+			return projects, nil
+		},
+	}
+	return &Server{manager: mockManager, logger: logr.Discard()}
+}
+
+func TestRepositoryEvents(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		headers         map[string]string
+		payload         any
+		spec            api.RenovateJobSpec
+		expectedStatus  int
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		{
+			name:            "github repository created",
+			path:            "/webhook/v1/github",
+			headers:         map[string]string{"X-GitHub-Event": "repository"},
+			payload:         GitHubRepositoryEvent{Action: "created", Repository: &GitHubRepository{FullName: "org/new"}},
+			spec:            api.RenovateJobSpec{DiscoveryFilter: "org/*"},
+			expectedStatus:  http.StatusAccepted,
+			expectedAdded:   []string{"org/new"},
+			expectedRemoved: nil,
+		},
+		{
+			name:           "github repository not matching the discovery filter",
+			path:           "/webhook/v1/github",
+			headers:        map[string]string{"X-GitHub-Event": "repository"},
+			payload:        GitHubRepositoryEvent{Action: "created", Repository: &GitHubRepository{FullName: "other/new"}},
+			spec:           api.RenovateJobSpec{DiscoveryFilter: "org/*"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "github fork created with skipForks",
+			path:           "/webhook/v1/github",
+			headers:        map[string]string{"X-GitHub-Event": "repository"},
+			payload:        GitHubRepositoryEvent{Action: "created", Repository: &GitHubRepository{FullName: "org/fork", Fork: true}},
+			spec:           api.RenovateJobSpec{SkipForks: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "github repository created with skipEmpty",
+			path:           "/webhook/v1/github",
+			headers:        map[string]string{"X-GitHub-Event": "repository"},
+			payload:        GitHubRepositoryEvent{Action: "created", Repository: &GitHubRepository{FullName: "org/new"}},
+			spec:           api.RenovateJobSpec{SkipEmpty: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "github repository created with skipArchived",
+			path:           "/webhook/v1/github",
+			headers:        map[string]string{"X-GitHub-Event": "repository"},
+			payload:        GitHubRepositoryEvent{Action: "created", Repository: &GitHubRepository{FullName: "org/new"}},
+			spec:           api.RenovateJobSpec{SkipArchived: true},
+			expectedStatus: http.StatusAccepted,
+			expectedAdded:  []string{"org/new"},
+		},
+		{
+			name:    "github installation repositories with skipForks",
+			path:    "/webhook/v1/github",
+			headers: map[string]string{"X-GitHub-Event": "installation_repositories"},
+			payload: GitHubRepositoryEvent{
+				Action:            "added",
+				RepositoriesAdded: []GitHubRepository{{FullName: "org/a"}},
+			},
+			spec:           api.RenovateJobSpec{SkipForks: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "github repository archived",
+			path:            "/webhook/v1/github",
+			headers:         map[string]string{"X-GitHub-Event": "repository"},
+			payload:         GitHubRepositoryEvent{Action: "archived", Repository: &GitHubRepository{FullName: "org/old"}},
+			expectedStatus:  http.StatusAccepted,
+			expectedRemoved: []string{"org/old"},
+		},
+		{
+			name:    "github installation repositories",
+			path:    "/webhook/v1/github",
+			headers: map[string]string{"X-GitHub-Event": "installation_repositories"},
+			payload: GitHubRepositoryEvent{
+				Action:              "added",
+				RepositoriesAdded:   []GitHubRepository{{FullName: "org/a"}, {FullName: "org/b"}},
+				RepositoriesRemoved: []GitHubRepository{{FullName: "org/c"}},
+			},
+			expectedStatus:  http.StatusAccepted,
+			expectedAdded:   []string{"org/a", "org/b"},
+			expectedRemoved: []string{"org/c"},
+		},
+		{
+			name:           "static projects ignore repository events",
+			path:           "/webhook/v1/github",
+			headers:        map[string]string{"X-GitHub-Event": "repository"},
+			payload:        GitHubRepositoryEvent{Action: "created", Repository: &GitHubRepository{FullName: "org/new"}},
+			spec:           api.RenovateJobSpec{Projects: []string{"org/static"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "gitlab project created",
+			path:           "/webhook/v1/gitlab",
+			payload:        GitLabEvent{EventName: "project_create", PathWithNamespace: "group/sub/new"},
+			spec:           api.RenovateJobSpec{ProjectFilters: []api.RenovateProjectFilter{{Action: api.ProjectFilterInclude, Prefix: "group"}}},
+			expectedStatus: http.StatusAccepted,
+			expectedAdded:  []string{"group/sub/new"},
+		},
+		{
+			name:           "gitlab project created with skipInactiveFor",
+			path:           "/webhook/v1/gitlab",
+			payload:        GitLabEvent{EventName: "project_create", PathWithNamespace: "group/new"},
+			spec:           api.RenovateJobSpec{SkipInactiveFor: &metav1.Duration{Duration: time.Hour}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "gitlab project destroyed",
+			path:            "/webhook/v1/gitlab",
+			payload:         GitLabEvent{EventName: "project_destroy", PathWithNamespace: "group/old"},
+			expectedStatus:  http.StatusAccepted,
+			expectedRemoved: []string{"group/old"},
+		},
+		{
+			name:           "forgejo repository created",
+			path:           "/webhook/v1/forgejo",
+			headers:        map[string]string{"X-Forgejo-Event": "repository"},
+			payload:        ForgejoEvent{Action: "created", Repository: ForgejoRepository{FullName: "org/new", Topics: []string{"renovate"}}},
+			spec:           api.RenovateJobSpec{DiscoverTopics: "renovate"},
+			expectedStatus: http.StatusAccepted,
+			expectedAdded:  []string{"org/new"},
+		},
+		{
+			name:           "forgejo repository without the discover topics",
+			path:           "/webhook/v1/forgejo",
+			headers:        map[string]string{"X-Forgejo-Event": "repository"},
+			payload:        ForgejoEvent{Action: "created", Repository: ForgejoRepository{FullName: "org/new", Topics: []string{"other"}}},
+			spec:           api.RenovateJobSpec{DiscoverTopics: "renovate"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added, removed []string
+			server := newRepositoryEventServer(tt.spec, &added, &removed)
+
+			body, err := json.Marshal(tt.payload)
+			if err != nil {
+				t.Fatalf("failed to marshal payload: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodPost, tt.path+"?namespace=default&job=test-job", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+
+			switch tt.path {
+			case "/webhook/v1/github":
+				server.githubWebhook(w, req)
+			case "/webhook/v1/gitlab":
+				server.gitLabWebhook(w, req)
+			case "/webhook/v1/forgejo":
+				server.forgejoWebhook(w, req)
+			}
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if !slices.Equal(added, tt.expectedAdded) {
+				t.Errorf("expected added projects %v, got %v", tt.expectedAdded, added)
+			}
+			if !slices.Equal(removed, tt.expectedRemoved) {
+				t.Errorf("expected removed projects %v, got %v", tt.expectedRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestRepositoryEvents_RemovalBlocked(t *testing.T) {
+	var added, removed []string
+	server := newRepositoryEventServer(api.RenovateJobSpec{}, &added, &removed)
+	server.manager.(*mockWebhookManager).removeProjectsFunc = func(ctx context.Context, job crdmanager.RenovateJobIdentifier, projects []string) ([]string, error) {
+		return nil, &crdmanager.ProjectRemovalBlockedError{Removed: len(projects), Existing: 1}
+	}
+
+	body, err := json.Marshal(GitHubRepositoryEvent{Action: "deleted", Repository: &GitHubRepository{FullName: "org/old"}})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/webhook/v1/github?namespace=default&job=test-job", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "repository")
+	w := httptest.NewRecorder()
+	server.githubWebhook(w, req)
+
+	if w.Code != http.StatusAccepted || !strings.Contains(w.Body.String(), "project removal blocked") {
+		t.Errorf("expected the blocked removal to be accepted, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/api/errors"
 )
 
 type Server struct {
@@ -90,6 +91,10 @@ func (s *Server) runRenovate(w http.ResponseWriter, r *http.Request) {
 			Priority: 1,
 		},
 	)
+	if errors.IsNotFound(err) {
+		s.writeJSON(w, http.StatusNotFound, map[string]string{"error": "project not found"})
+		return
+	}
 	if err != nil {
 		s.logger.Error(err, "Failed to run Renovate for project", "project", project, "renovateJob", job, "namespace", namespace)
 		s.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to run renovate for project"})